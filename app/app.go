@@ -0,0 +1,82 @@
+package app
+
+import (
+	"assignment/initializers"
+	"assignment/services"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// App holds the dependencies shared by the HTTP handlers, the scheduler and
+// the commands. Each server process or test builds its own.
+type App struct {
+	DB         *gorm.DB
+	Log        *logrus.Logger
+	Clock      services.Clock
+	Prices     *services.PriceService
+	Depository services.DepositoryAdapter
+	Calendar   *services.TradingCalendar
+	Limits     *services.RewardLimiter
+	Location   *time.Location
+	Batch      initializers.RewardBatchConfig
+	Portfolio  initializers.PortfolioConfig
+}
+
+// Config is the settings read from the environment that an App is built with.
+type Config struct {
+	Location     *time.Location
+	RewardLimits initializers.RewardLimitsConfig
+	Batch        initializers.RewardBatchConfig
+	Portfolio    initializers.PortfolioConfig
+}
+
+// DefaultConfig matches an empty environment: the default business timezone,
+// no reward caps and the default batch size.
+func DefaultConfig() Config {
+	// The zone database is embedded, so the default zone always loads.
+	location, _ := time.LoadLocation(initializers.DefaultBusinessTimezone)
+	return Config{
+		Location: location,
+		Batch:    initializers.RewardBatchConfig{MaxItems: initializers.DefaultRewardBatchMaxItems},
+	}
+}
+
+func LoadConfig() (Config, error) {
+	var config Config
+	var err error
+
+	if config.Location, err = initializers.LoadBusinessLocation(); err != nil {
+		return config, err
+	}
+	if config.RewardLimits, err = initializers.LoadRewardLimitsConfig(); err != nil {
+		return config, err
+	}
+	if config.Batch, err = initializers.LoadRewardBatchConfig(); err != nil {
+		return config, err
+	}
+	if config.Portfolio, err = initializers.LoadPortfolioConfig(); err != nil {
+		return config, err
+	}
+
+	return config, nil
+}
+
+// New wires an App around db. Prices come from provider and are stamped with
+// clock. The depository defaults to the sandbox adapter and the calendar to
+// one session per day in config's timezone; replace either as needed.
+func New(db *gorm.DB, log *logrus.Logger, provider services.PriceProvider, clock services.Clock, config Config) *App {
+	return &App{
+		DB:         db,
+		Log:        log,
+		Clock:      clock,
+		Prices:     services.NewPriceService(db, provider, clock),
+		Depository: services.NewSandboxDepositoryAdapter(),
+		Calendar:   services.NewContinuousCalendar(config.Location),
+		Limits:     services.NewRewardLimiter(config.RewardLimits, clock, config.Location),
+		Location:   config.Location,
+		Batch:      config.Batch,
+		Portfolio:  config.Portfolio,
+	}
+}
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"assignment/app"
+	"fmt"
+	"os"
+	"sort"
+)
+
+type command struct {
+	description string
+	run         func(a *app.App, args []string) int
+}
+
+var registry = map[string]command{
+	"dividends": {
+		description: "Accrue dividends past their record date and pay those past their payment date (--as-of)",
+		run:         runDividends,
+	},
+	"issue-token": {
+		description: "Sign a bearer token for the API (--sub, --role, --ttl)",
+		run:         runIssueToken,
+	},
+	"import-rewards": {
+		description: "Import rewards from a CSV file (--file, --dry-run, --rejects)",
+		run:         runImportRewards,
+	},
+	"reconcile": {
+		description: "Check every reward's ledger entries and record a reconciliation run",
+		run:         runReconcile,
+	},
+}
+
+// Run executes the subcommand named by args[0] against a and returns the
+// process exit code.
+func Run(a *app.App, args []string) int {
+	cmd, ok := registry[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+		printUsage()
+		return 2
+	}
+
+	return cmd.run(a, args[1:])
+}
+
+func printUsage() {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(os.Stderr, "Usage: assignment [command]")
+	fmt.Fprintln(os.Stderr, "\nWithout a command the API server is started.\n\nCommands:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-15s %s\n", name, registry[name].description)
+	}
+}
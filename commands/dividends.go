@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"assignment/app"
+	"assignment/services"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+func runDividends(a *app.App, args []string) int {
+	flags := flag.NewFlagSet("dividends", flag.ContinueOnError)
+	asOf := flags.String("as-of", "", "process dividends due at this RFC 3339 time instead of now")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	clock := a.Clock
+	if *asOf != "" {
+		parsed, err := time.Parse(time.RFC3339, *asOf)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --as-of: %v\n", err)
+			return 2
+		}
+		clock = services.FixedClock(parsed)
+	}
+
+	result, err := services.ProcessDueDividends(a.DB, clock)
+	if result != nil {
+		for _, declaration := range result.Accrued {
+			fmt.Printf("Accrued dividend %d (%s): %d entitlements, %s INR\n",
+				declaration.ID, declaration.StockSymbol, declaration.EntitlementsCount, declaration.TotalAmount)
+		}
+		for _, declaration := range result.Paid {
+			fmt.Printf("Paid dividend %d (%s): %d entitlements, %s INR\n",
+				declaration.ID, declaration.StockSymbol, declaration.EntitlementsCount, declaration.TotalAmount)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "dividend processing failed: %v\n", err)
+		return 2
+	}
+
+	fmt.Printf("Dividends accrued: %d, paid: %d\n", len(result.Accrued), len(result.Paid))
+	return 0
+}
@@ -0,0 +1,257 @@
+package commands
+
+import (
+	"assignment/app"
+	"assignment/decimal"
+	"assignment/models"
+	"assignment/services"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+var importColumns = []string{"id", "user_id", "stock_symbol", "quantity", "reward_timestamp"}
+
+type importRow struct {
+	line   int
+	record []string
+}
+
+type rejectedRow struct {
+	importRow
+	reason string
+}
+
+func runImportRewards(a *app.App, args []string) int {
+	flags := flag.NewFlagSet("import-rewards", flag.ContinueOnError)
+	file := flags.String("file", "", "CSV of id,user_id,stock_symbol,quantity,reward_timestamp")
+	dryRun := flags.Bool("dry-run", false, "validate and preview charges without writing anything")
+	rejectsPath := flags.String("rejects", "", "where to write rejected rows (default <file>.rejected.csv)")
+	batchSize := flags.Int("batch-size", 500, "rewards written per batch")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *file == "" || *batchSize <= 0 {
+		fmt.Fprintln(os.Stderr, "import-rewards: --file is required and --batch-size must be positive")
+		flags.Usage()
+		return 2
+	}
+	if *rejectsPath == "" {
+		*rejectsPath = strings.TrimSuffix(*file, filepath.Ext(*file)) + ".rejected.csv"
+	}
+
+	header, rows, err := readImportFile(*file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import-rewards: %v\n", err)
+		return 2
+	}
+
+	var rejected []rejectedRow
+	var items []models.BatchRewardItem
+	var itemRows []importRow
+
+	for _, row := range rows {
+		req, err := parseImportRow(header, row.record)
+		if err != nil {
+			rejected = append(rejected, rejectedRow{importRow: row, reason: err.Error()})
+			continue
+		}
+		items = append(items, models.BatchRewardItem{Index: len(itemRows), Request: req})
+		itemRows = append(itemRows, row)
+	}
+
+	// A dry run previews the whole file at once, so each row's limit check
+	// counts the valid rows before it.
+	chunkSize := *batchSize
+	if *dryRun {
+		chunkSize = len(items)
+	}
+
+	var created, valid, skipped int
+	for start := 0; start < len(items); start += chunkSize {
+		end := start + chunkSize
+		if end > len(items) {
+			end = len(items)
+		}
+
+		var results []models.BatchItemResult
+		if *dryRun {
+			results = services.PreviewRewardBatch(a.DB, a.Prices, a.Limits, items[start:end])
+		} else {
+			results = services.CreateRewardBatch(a.DB, a.Prices, a.Limits, items[start:end])
+		}
+
+		for _, result := range results {
+			row := itemRows[result.Index]
+			switch {
+			case result.Status == models.BatchStatusCreated:
+				created++
+			case result.Status == models.BatchStatusValid:
+				valid++
+				charges := result.CompanyCharges
+				fmt.Printf("line %d: %s %s x %s @ %s = %s + charges %s = %s\n",
+					row.line, result.ID, result.Reward.StockSymbol, result.Reward.Quantity,
+					result.Reward.StockPriceAtReward.StringFixed(2), charges.StockCost.StringFixed(2),
+					charges.TotalCost.Sub(charges.StockCost).StringFixed(2), charges.TotalCost.StringFixed(2))
+			case result.Status == models.BatchStatusDuplicate && result.Reward != nil:
+				skipped++
+			default:
+				rejected = append(rejected, rejectedRow{importRow: row, reason: result.Message})
+			}
+		}
+
+		if !*dryRun {
+			fmt.Printf("Processed %d/%d rewards\n", end, len(items))
+		}
+	}
+
+	if len(rejected) > 0 {
+		if err := writeRejectedRows(*rejectsPath, header, rejected); err != nil {
+			fmt.Fprintf(os.Stderr, "import-rewards: %v\n", err)
+			return 2
+		}
+	}
+
+	if *dryRun {
+		fmt.Printf("Dry run: %d rows, %d valid, %d already imported, %d rejected\n", len(rows), valid, skipped, len(rejected))
+	} else {
+		fmt.Printf("Import: %d rows, %d created, %d already imported, %d rejected\n", len(rows), created, skipped, len(rejected))
+	}
+	if len(rejected) > 0 {
+		fmt.Printf("Rejected rows written to %s\n", *rejectsPath)
+		return 1
+	}
+	return 0
+}
+
+// readImportFile returns the column order and data rows. A first row naming
+// the columns is used as the header; without one the default column order is
+// assumed.
+func readImportFile(path string) ([]string, []importRow, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header := importColumns
+	var rows []importRow
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		if len(rows) == 0 && line == 1 && isImportHeader(record) {
+			header = normalizeHeader(record)
+			continue
+		}
+
+		rows = append(rows, importRow{line: line, record: record})
+	}
+
+	return header, rows, nil
+}
+
+func isImportHeader(record []string) bool {
+	for _, column := range normalizeHeader(record) {
+		if column == "id" {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeHeader(record []string) []string {
+	header := make([]string, len(record))
+	for i, column := range record {
+		header[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+	}
+	return header
+}
+
+func parseImportRow(header []string, record []string) (models.RewardRequest, error) {
+	var req models.RewardRequest
+
+	if len(record) != len(header) {
+		return req, fmt.Errorf("expected %d columns, got %d", len(header), len(record))
+	}
+
+	values := make(map[string]string, len(header))
+	for i, column := range header {
+		values[column] = strings.TrimSpace(record[i])
+	}
+
+	for _, column := range importColumns {
+		if _, ok := values[column]; !ok {
+			return req, fmt.Errorf("missing column %s", column)
+		}
+	}
+
+	req.ID = values["id"]
+	req.UserID = values["user_id"]
+	req.StockSymbol = values["stock_symbol"]
+	req.CampaignID = values["campaign_id"]
+
+	if values["quantity"] != "" {
+		quantity, err := decimal.NewFromString(values["quantity"])
+		if err != nil {
+			return req, fmt.Errorf("invalid quantity %q", values["quantity"])
+		}
+		req.Quantity = quantity
+	}
+
+	if values["reward_timestamp"] != "" {
+		timestamp, err := time.Parse(time.RFC3339, values["reward_timestamp"])
+		if err != nil {
+			return req, fmt.Errorf("invalid reward_timestamp %q, expected RFC 3339", values["reward_timestamp"])
+		}
+		req.RewardTimestamp = timestamp
+	}
+
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return req, fmt.Errorf("invalid request: %v", err)
+	}
+
+	return req, nil
+}
+
+func writeRejectedRows(path string, header []string, rejected []rejectedRow) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(append(append([]string{"line"}, header...), "reason")); err != nil {
+		return err
+	}
+
+	for _, row := range rejected {
+		record := append([]string{fmt.Sprint(row.line)}, row.record...)
+		if err := writer.Write(append(record, row.reason)); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
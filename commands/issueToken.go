@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"assignment/app"
+	"assignment/initializers"
+	"assignment/models"
+	"assignment/services"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+func runIssueToken(a *app.App, args []string) int {
+	flags := flag.NewFlagSet("issue-token", flag.ContinueOnError)
+	subject := flags.String("sub", "", "subject; the user ID for the user role")
+	role := flags.String("role", models.RoleUser, "role: service, user or admin")
+	ttl := flags.Duration("ttl", time.Hour, "how long the token is valid")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *subject == "" || *ttl <= 0 {
+		fmt.Fprintln(os.Stderr, "issue-token: --sub is required and --ttl must be positive")
+		return 2
+	}
+
+	config, err := initializers.LoadAuthConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid auth configuration: %v\n", err)
+		return 2
+	}
+
+	now := a.Clock.Now()
+	claims := models.Claims{
+		Subject:   *subject,
+		Role:      *role,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(*ttl).Unix(),
+	}
+	switch claims.Role {
+	case models.RoleService, models.RoleUser, models.RoleAdmin:
+	default:
+		fmt.Fprintf(os.Stderr, "issue-token: unknown role %q\n", claims.Role)
+		return 2
+	}
+
+	token, err := services.SignToken(config, claims)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to sign token: %v\n", err)
+		return 2
+	}
+
+	fmt.Println(token)
+	return 0
+}
@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"assignment/app"
+	"assignment/models"
+	"assignment/services"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func runReconcile(a *app.App, args []string) int {
+	flags := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	run, err := services.RunReconciliation(a.DB, a.Clock)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "reconciliation failed: %v\n", err)
+		return 2
+	}
+
+	fmt.Printf("Reconciliation run %d: %s\n", run.ID, run.Status)
+	fmt.Printf("  rewards scanned:     %d\n", run.RewardsScanned)
+	fmt.Printf("  unbalanced journals: %d\n", run.UnbalancedCount)
+	fmt.Printf("  missing entries:     %d\n", run.MissingEntriesCount)
+	fmt.Printf("  quantity mismatches: %d\n", run.QuantityMismatchCount)
+
+	for _, finding := range run.Findings {
+		fmt.Printf("  [%s] %s: %s\n", finding.Type, finding.RewardID, finding.Message)
+	}
+
+	if run.Status != models.ReconciliationStatusClean {
+		return 1
+	}
+	return 0
+}
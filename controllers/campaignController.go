@@ -0,0 +1,260 @@
+package controllers
+
+import (
+	"assignment/app"
+	"assignment/models"
+	"assignment/services"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CampaignController struct {
+	*app.App
+}
+
+func NewCampaignController(a *app.App) *CampaignController {
+	return &CampaignController{App: a}
+}
+
+func (h *CampaignController) ListCampaigns(c *gin.Context) {
+	log := h.Log
+
+	query := h.DB.Order("id")
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", strings.ToUpper(status))
+	}
+
+	campaigns := []models.Campaign{}
+	if err := query.Find(&campaigns).Error; err != nil {
+		log.WithError(err).Error("Failed to fetch campaigns")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch campaigns",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, campaigns)
+}
+
+func (h *CampaignController) GetCampaign(c *gin.Context) {
+	campaign, ok := h.findCampaign(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, campaign)
+}
+
+func (h *CampaignController) CreateCampaign(c *gin.Context) {
+	log := h.Log
+	var req models.CampaignRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	campaign := models.Campaign{ID: req.ID}
+	applyCampaignRequest(&campaign, req)
+	if !h.validateCampaign(c, &campaign) {
+		return
+	}
+
+	var count int64
+	if err := h.DB.WithContext(c.Request.Context()).Model(&models.Campaign{}).Where("id = ?", campaign.ID).Count(&count).Error; err != nil {
+		log.WithError(err).WithField("campaign_id", campaign.ID).Error("Failed to check campaign")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to check campaign",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": fmt.Sprintf("Campaign '%s' already exists", campaign.ID),
+		})
+		return
+	}
+
+	if err := h.DB.WithContext(c.Request.Context()).Create(&campaign).Error; err != nil {
+		log.WithError(err).WithField("campaign_id", campaign.ID).Error("Failed to create campaign")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create campaign",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	log.WithField("campaign_id", campaign.ID).Info("Campaign created")
+	c.JSON(http.StatusCreated, campaign)
+}
+
+func (h *CampaignController) UpdateCampaign(c *gin.Context) {
+	log := h.Log
+
+	campaign, ok := h.findCampaign(c)
+	if !ok {
+		return
+	}
+
+	var req models.CampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if req.ID != campaign.ID {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "ID in body does not match the campaign being updated",
+		})
+		return
+	}
+
+	applyCampaignRequest(campaign, req)
+	if !h.validateCampaign(c, campaign) {
+		return
+	}
+
+	if err := h.DB.WithContext(c.Request.Context()).Save(campaign).Error; err != nil {
+		log.WithError(err).WithField("campaign_id", campaign.ID).Error("Failed to update campaign")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to update campaign",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	log.WithField("campaign_id", campaign.ID).Info("Campaign updated")
+	c.JSON(http.StatusOK, campaign)
+}
+
+func (h *CampaignController) DeleteCampaign(c *gin.Context) {
+	log := h.Log
+
+	campaign, ok := h.findCampaign(c)
+	if !ok {
+		return
+	}
+
+	campaign.Status = models.CampaignStatusEnded
+	if err := h.DB.WithContext(c.Request.Context()).Save(campaign).Error; err != nil {
+		log.WithError(err).WithField("campaign_id", campaign.ID).Error("Failed to end campaign")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to end campaign",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	log.WithField("campaign_id", campaign.ID).Info("Campaign ended")
+	c.JSON(http.StatusOK, campaign)
+}
+
+func (h *CampaignController) ListCampaignSpend(c *gin.Context) {
+	h.respondCampaignSpend(c, "")
+}
+
+func (h *CampaignController) GetCampaignSpend(c *gin.Context) {
+	h.respondCampaignSpend(c, c.Param("id"))
+}
+
+func (h *CampaignController) respondCampaignSpend(c *gin.Context, campaignID string) {
+	log := h.Log
+
+	var query models.CampaignSpendQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	spends, err := services.GetCampaignSpend(h.DB, campaignID, query.From, query.To)
+	if errors.Is(err, services.ErrCampaignNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": fmt.Sprintf("Campaign '%s' not found", campaignID),
+		})
+		return
+	} else if err != nil {
+		log.WithError(err).WithField("campaign_id", campaignID).Error("Failed to calculate campaign spend")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to calculate campaign spend",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if campaignID != "" {
+		c.JSON(http.StatusOK, spends[0])
+		return
+	}
+	c.JSON(http.StatusOK, spends)
+}
+
+func (h *CampaignController) findCampaign(c *gin.Context) (*models.Campaign, bool) {
+	campaignID := c.Param("id")
+
+	campaign, err := services.GetCampaign(h.DB.WithContext(c.Request.Context()), campaignID)
+	if errors.Is(err, services.ErrCampaignNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": fmt.Sprintf("Campaign '%s' not found", campaignID),
+		})
+		return nil, false
+	} else if err != nil {
+		h.Log.WithError(err).WithField("campaign_id", campaignID).Error("Failed to fetch campaign")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch campaign",
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+
+	return campaign, true
+}
+
+func (h *CampaignController) validateCampaign(c *gin.Context, campaign *models.Campaign) bool {
+	err := services.ValidateCampaign(h.DB.WithContext(c.Request.Context()), campaign)
+	if errors.Is(err, services.ErrInvalidCampaign) || errors.Is(err, services.ErrUnknownInstrument) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "Invalid campaign",
+			"details": err.Error(),
+		})
+		return false
+	} else if err != nil {
+		h.Log.WithError(err).WithField("campaign_id", campaign.ID).Error("Failed to validate campaign")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to validate campaign",
+			"details": err.Error(),
+		})
+		return false
+	}
+
+	return true
+}
+
+func applyCampaignRequest(campaign *models.Campaign, req models.CampaignRequest) {
+	campaign.Name = req.Name
+	campaign.Description = req.Description
+	campaign.Budget = req.Budget
+	campaign.DailyBudget = req.DailyBudget
+	campaign.StartDate = req.StartDate
+	campaign.EndDate = req.EndDate
+	campaign.EligibleSymbols = req.EligibleSymbols
+	campaign.Status = req.Status
+	if campaign.Status == "" {
+		campaign.Status = models.CampaignStatusActive
+	}
+}
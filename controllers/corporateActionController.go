@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"assignment/app"
+	"assignment/models"
+	"assignment/services"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type CorporateActionController struct {
+	*app.App
+}
+
+func NewCorporateActionController(a *app.App) *CorporateActionController {
+	return &CorporateActionController{App: a}
+}
+
+func (h *CorporateActionController) ListCorporateActions(c *gin.Context) {
+	log := h.Log
+
+	query := h.DB.Order("ex_date DESC, id DESC")
+	if symbol := c.Query("stock_symbol"); symbol != "" {
+		symbol = strings.ToUpper(symbol)
+		query = query.Where("stock_symbol = ? OR new_symbol = ?", symbol, symbol)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", strings.ToUpper(status))
+	}
+
+	var actions []models.CorporateAction
+	if err := query.Find(&actions).Error; err != nil {
+		log.WithError(err).Error("Failed to fetch corporate actions")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch corporate actions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, actions)
+}
+
+func (h *CorporateActionController) GetCorporateAction(c *gin.Context) {
+	log := h.Log
+	actionID := c.Param("id")
+
+	var action models.CorporateAction
+	err := h.DB.Where("id = ?", actionID).First(&action).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": fmt.Sprintf("Corporate action '%s' not found", actionID),
+		})
+		return
+	} else if err != nil {
+		log.WithError(err).WithField("corporate_action_id", actionID).Error("Failed to fetch corporate action")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch corporate action",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, action)
+}
+
+func (h *CorporateActionController) CreateCorporateAction(c *gin.Context) {
+	log := h.Log
+	var req models.CorporateActionRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	action := models.CorporateAction{
+		ActionType:       req.ActionType,
+		StockSymbol:      strings.ToUpper(req.StockSymbol),
+		NewSymbol:        strings.ToUpper(req.NewSymbol),
+		RatioNumerator:   req.RatioNumerator,
+		RatioDenominator: req.RatioDenominator,
+		ExDate:           req.ExDate.UTC(),
+		Status:           models.CorporateActionStatusPending,
+		Description:      req.Description,
+	}
+
+	err := services.ValidateCorporateAction(h.DB.WithContext(c.Request.Context()), &action)
+	if errors.Is(err, services.ErrInvalidCorporateAction) || errors.Is(err, services.ErrUnknownInstrument) ||
+		errors.Is(err, services.ErrInstrumentNotActive) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": err.Error(),
+		})
+		return
+	} else if err != nil {
+		log.WithError(err).Error("Failed to validate corporate action")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create corporate action",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.DB.WithContext(c.Request.Context()).Create(&action).Error; err != nil {
+		log.WithError(err).Error("Failed to create corporate action")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create corporate action",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	log.WithFields(logrus.Fields{
+		"corporate_action_id": action.ID,
+		"action_type":         action.ActionType,
+		"stock_symbol":        action.StockSymbol,
+	}).Info("Corporate action created")
+	c.JSON(http.StatusCreated, action)
+}
+
+func (h *CorporateActionController) ApplyCorporateAction(c *gin.Context) {
+	log := h.Log
+
+	actionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": fmt.Sprintf("Corporate action '%s' not found", c.Param("id")),
+		})
+		return
+	}
+
+	action, err := services.ApplyCorporateAction(h.DB.WithContext(c.Request.Context()), h.Clock, uint(actionID))
+	if errors.Is(err, services.ErrCorporateActionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	} else if errors.Is(err, services.ErrCorporateActionApplied) || errors.Is(err, services.ErrCorporateActionNotDue) ||
+		errors.Is(err, services.ErrCorporateActionBlocked) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+		return
+	} else if err != nil {
+		log.WithError(err).WithField("corporate_action_id", actionID).Error("Failed to apply corporate action")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to apply corporate action",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	log.WithFields(logrus.Fields{
+		"corporate_action_id": action.ID,
+		"lots_adjusted":       action.LotsAdjusted,
+	}).Info("Corporate action applied")
+	c.JSON(http.StatusOK, action)
+}
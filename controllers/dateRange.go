@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"assignment/services"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestLocation resolves the ?tz= query parameter, defaulting to fallback,
+// and writes a 400 response for an unknown zone.
+func requestLocation(c *gin.Context, fallback *time.Location) (*time.Location, bool) {
+	location, err := services.ResolveLocation(c.Query("tz"), fallback)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid tz, expected an IANA timezone such as Asia/Kolkata",
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+	return location, true
+}
+
+// requestToday is the day containing now in the request's timezone, or in
+// fallback when the request names none.
+func requestToday(c *gin.Context, now time.Time, fallback *time.Location) (services.DateRange, *time.Location, bool) {
+	location, ok := requestLocation(c, fallback)
+	if !ok {
+		return services.DateRange{}, nil, false
+	}
+	return services.DayRange(now, location), location, true
+}
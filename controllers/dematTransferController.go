@@ -0,0 +1,214 @@
+package controllers
+
+import (
+	"assignment/app"
+	"assignment/models"
+	"assignment/services"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type DematTransferController struct {
+	*app.App
+}
+
+func NewDematTransferController(a *app.App) *DematTransferController {
+	return &DematTransferController{App: a}
+}
+
+func (h *DematTransferController) RequestDematTransfer(c *gin.Context) {
+	log := h.Log
+	var req models.DematTransferRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.WithError(err).Warn("Invalid transfer request")
+		c.JSON(http.StatusBadRequest, models.DematTransferResponse{
+			Success: false,
+			Message: fmt.Sprintf("Invalid request: %v", err),
+		})
+		return
+	}
+
+	response, replayed, err := services.CreateDematTransfer(h.DB.WithContext(c.Request.Context()), h.Clock, req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrTransferConflict):
+			status = http.StatusConflict
+		case errors.Is(err, services.ErrInsufficientHoldings) || errors.Is(err, services.ErrUnknownInstrument) ||
+			errors.Is(err, services.ErrInstrumentNotActive):
+			status = http.StatusUnprocessableEntity
+		}
+
+		entry := log.WithError(err).WithField("transfer_id", req.ID)
+		if status == http.StatusInternalServerError {
+			entry.Error("Failed to record transfer request")
+		} else {
+			entry.Warn("Rejected transfer request")
+		}
+		c.JSON(status, models.DematTransferResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if replayed {
+		log.WithField("transfer_id", req.ID).Info("Replayed response for duplicate transfer request")
+		c.JSON(http.StatusCreated, response)
+		return
+	}
+
+	log.WithFields(logrus.Fields{
+		"transfer_id": response.Transfer.ID,
+		"user_id":     response.Transfer.UserID,
+		"symbol":      response.Transfer.StockSymbol,
+		"quantity":    response.Transfer.Quantity.String(),
+	}).Info("Transfer requested successfully")
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *DematTransferController) GetUserDematTransfers(c *gin.Context) {
+	log := h.Log
+	userID := c.Param("userId")
+
+	transfers, err := services.ListUserDematTransfers(h.DB, userID)
+	if err != nil {
+		log.WithError(err).WithField("user_id", userID).Error("Failed to fetch transfers")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch transfers",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, transfers)
+}
+
+func (h *DematTransferController) ListDematTransfers(c *gin.Context) {
+	log := h.Log
+
+	query := h.DB.Preload("Lots", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Order("requested_at DESC, id")
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", strings.ToUpper(status))
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	transfers := []models.DematTransfer{}
+	if err := query.Find(&transfers).Error; err != nil {
+		log.WithError(err).Error("Failed to fetch transfers")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch transfers",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, transfers)
+}
+
+func (h *DematTransferController) GetDematTransfer(c *gin.Context) {
+	log := h.Log
+	transferID := c.Param("id")
+
+	transfer, err := services.GetDematTransfer(h.DB, transferID)
+	if errors.Is(err, services.ErrTransferNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": fmt.Sprintf("Transfer '%s' not found", transferID),
+		})
+		return
+	} else if err != nil {
+		log.WithError(err).WithField("transfer_id", transferID).Error("Failed to fetch transfer")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch transfer",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+func (h *DematTransferController) ApproveDematTransfer(c *gin.Context) {
+	h.runTransferStep(c, "approve", func(db *gorm.DB, transferID string) (*models.DematTransfer, error) {
+		return services.ApproveDematTransfer(db, h.Clock, transferID)
+	})
+}
+
+func (h *DematTransferController) SubmitDematTransfer(c *gin.Context) {
+	h.runTransferStep(c, "submit", func(db *gorm.DB, transferID string) (*models.DematTransfer, error) {
+		return services.SubmitDematTransfer(db, h.Clock, h.Depository, transferID)
+	})
+}
+
+func (h *DematTransferController) RefreshDematTransfer(c *gin.Context) {
+	h.runTransferStep(c, "refresh", func(db *gorm.DB, transferID string) (*models.DematTransfer, error) {
+		return services.RefreshDematTransfer(db, h.Clock, h.Depository, transferID)
+	})
+}
+
+func (h *DematTransferController) RejectDematTransfer(c *gin.Context) {
+	var req models.RejectTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	h.runTransferStep(c, "reject", func(db *gorm.DB, transferID string) (*models.DematTransfer, error) {
+		return services.RejectDematTransfer(db, h.Clock, transferID, req.Reason)
+	})
+}
+
+func (h *DematTransferController) runTransferStep(c *gin.Context, step string, apply func(*gorm.DB, string) (*models.DematTransfer, error)) {
+	log := h.Log
+	transferID := c.Param("id")
+
+	transfer, err := apply(h.DB.WithContext(c.Request.Context()), transferID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrTransferNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, services.ErrTransferInvalidStatus):
+			status = http.StatusConflict
+		case errors.Is(err, services.ErrInsufficientHoldings):
+			status = http.StatusUnprocessableEntity
+		case errors.Is(err, services.ErrDepositoryUnavailable):
+			status = http.StatusBadGateway
+		}
+
+		if status == http.StatusInternalServerError || status == http.StatusBadGateway {
+			log.WithError(err).WithField("transfer_id", transferID).Errorf("Failed to %s transfer", step)
+			c.JSON(status, gin.H{
+				"error":   fmt.Sprintf("Failed to %s transfer", step),
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	log.WithFields(logrus.Fields{
+		"transfer_id": transfer.ID,
+		"status":      transfer.Status,
+		"reference":   transfer.DepositoryReference,
+	}).Info("Transfer updated")
+	c.JSON(http.StatusOK, transfer)
+}
@@ -0,0 +1,192 @@
+package controllers
+
+import (
+	"assignment/app"
+	"assignment/models"
+	"assignment/services"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type DividendController struct {
+	*app.App
+}
+
+func NewDividendController(a *app.App) *DividendController {
+	return &DividendController{App: a}
+}
+
+func (h *DividendController) GetUserDividends(c *gin.Context) {
+	log := h.Log
+	userID := c.Param("userId")
+
+	response, err := services.GetUserDividends(h.DB, userID)
+	if err != nil {
+		log.WithError(err).WithField("user_id", userID).Error("Failed to fetch dividends")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch dividends",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *DividendController) ListDividendDeclarations(c *gin.Context) {
+	log := h.Log
+
+	query := h.DB.Order("record_date DESC, id DESC")
+	if symbol := c.Query("stock_symbol"); symbol != "" {
+		query = query.Where("stock_symbol = ?", strings.ToUpper(symbol))
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", strings.ToUpper(status))
+	}
+
+	var declarations []models.DividendDeclaration
+	if err := query.Find(&declarations).Error; err != nil {
+		log.WithError(err).Error("Failed to fetch dividend declarations")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch dividend declarations",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, declarations)
+}
+
+func (h *DividendController) GetDividendDeclaration(c *gin.Context) {
+	log := h.Log
+	dividendID := c.Param("id")
+
+	var declaration models.DividendDeclaration
+	err := h.DB.Where("id = ?", dividendID).First(&declaration).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": fmt.Sprintf("Dividend declaration '%s' not found", dividendID),
+		})
+		return
+	} else if err != nil {
+		log.WithError(err).WithField("dividend_id", dividendID).Error("Failed to fetch dividend declaration")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch dividend declaration",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, declaration)
+}
+
+func (h *DividendController) CreateDividendDeclaration(c *gin.Context) {
+	log := h.Log
+	var req models.DividendDeclarationRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	declaration := models.DividendDeclaration{
+		StockSymbol:    strings.ToUpper(req.StockSymbol),
+		ExDate:         req.ExDate.UTC(),
+		RecordDate:     req.RecordDate.UTC(),
+		AmountPerShare: req.AmountPerShare,
+		Status:         models.DividendStatusDeclared,
+		Description:    req.Description,
+	}
+	if req.PaymentDate != nil {
+		paymentDate := req.PaymentDate.UTC()
+		declaration.PaymentDate = &paymentDate
+	}
+
+	err := services.ValidateDividendDeclaration(h.DB.WithContext(c.Request.Context()), &declaration)
+	if errors.Is(err, services.ErrInvalidDividendRequest) || errors.Is(err, services.ErrUnknownInstrument) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": err.Error(),
+		})
+		return
+	} else if err != nil {
+		log.WithError(err).Error("Failed to validate dividend declaration")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create dividend declaration",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.DB.WithContext(c.Request.Context()).Create(&declaration).Error; err != nil {
+		log.WithError(err).Error("Failed to create dividend declaration")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create dividend declaration",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	log.WithFields(logrus.Fields{
+		"dividend_id":  declaration.ID,
+		"stock_symbol": declaration.StockSymbol,
+	}).Info("Dividend declared")
+	c.JSON(http.StatusCreated, declaration)
+}
+
+func (h *DividendController) AccrueDividend(c *gin.Context) {
+	h.runDividendStep(c, "accrue", services.AccrueDividend)
+}
+
+func (h *DividendController) PayDividend(c *gin.Context) {
+	h.runDividendStep(c, "pay", services.PayDividend)
+}
+
+func (h *DividendController) runDividendStep(c *gin.Context, step string, apply func(*gorm.DB, services.Clock, uint) (*models.DividendDeclaration, error)) {
+	log := h.Log
+
+	dividendID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": fmt.Sprintf("Dividend declaration '%s' not found", c.Param("id")),
+		})
+		return
+	}
+
+	declaration, err := apply(h.DB.WithContext(c.Request.Context()), h.Clock, uint(dividendID))
+	if errors.Is(err, services.ErrDividendNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	} else if errors.Is(err, services.ErrDividendInvalidStatus) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+		return
+	} else if err != nil {
+		log.WithError(err).WithField("dividend_id", dividendID).Errorf("Failed to %s dividend", step)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   fmt.Sprintf("Failed to %s dividend", step),
+			"details": err.Error(),
+		})
+		return
+	}
+
+	log.WithFields(logrus.Fields{
+		"dividend_id":  declaration.ID,
+		"status":       declaration.Status,
+		"entitlements": declaration.EntitlementsCount,
+	}).Info("Dividend updated")
+	c.JSON(http.StatusOK, declaration)
+}
@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"assignment/app"
+	"assignment/models"
+	"assignment/services"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type FeeScheduleController struct {
+	*app.App
+}
+
+func NewFeeScheduleController(a *app.App) *FeeScheduleController {
+	return &FeeScheduleController{App: a}
+}
+
+func (h *FeeScheduleController) ListFeeSchedules(c *gin.Context) {
+	log := h.Log
+
+	var schedules []models.FeeSchedule
+	err := h.DB.Order("effective_from DESC, id DESC").Find(&schedules).Error
+	if err != nil {
+		log.WithError(err).Error("Failed to fetch fee schedules")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch fee schedules",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedules)
+}
+
+func (h *FeeScheduleController) GetFeeSchedule(c *gin.Context) {
+	log := h.Log
+	scheduleID := c.Param("id")
+
+	var schedule models.FeeSchedule
+	err := h.DB.Where("id = ?", scheduleID).First(&schedule).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": fmt.Sprintf("Fee schedule '%s' not found", scheduleID),
+		})
+		return
+	} else if err != nil {
+		log.WithError(err).WithField("fee_schedule_id", scheduleID).Error("Failed to fetch fee schedule")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch fee schedule",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+func (h *FeeScheduleController) GetEffectiveFeeSchedule(c *gin.Context) {
+	log := h.Log
+
+	at, ok := parseTimeQuery(c, "at", h.Clock.Now())
+	if !ok {
+		return
+	}
+
+	exchange := strings.ToUpper(c.DefaultQuery("exchange", "NSE"))
+	instrumentType := strings.ToUpper(c.DefaultQuery("instrument_type", models.InstrumentTypeEquity))
+
+	schedule, err := services.GetEffectiveFeeSchedule(h.DB, at, exchange, instrumentType)
+	if errors.Is(err, services.ErrNoFeeSchedule) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	} else if err != nil {
+		log.WithError(err).Error("Failed to fetch effective fee schedule")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch effective fee schedule",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+func (h *FeeScheduleController) CreateFeeSchedule(c *gin.Context) {
+	log := h.Log
+	var req models.FeeScheduleRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if req.BrokerageMax.IsPositive() && req.BrokerageMin.GreaterThan(req.BrokerageMax) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "brokerage_min cannot be greater than brokerage_max",
+		})
+		return
+	}
+
+	schedule := models.FeeSchedule{
+		Name:            req.Name,
+		EffectiveFrom:   req.EffectiveFrom.UTC(),
+		Exchange:        req.Exchange,
+		InstrumentType:  req.InstrumentType,
+		BrokerageRate:   req.BrokerageRate,
+		BrokerageMin:    req.BrokerageMin,
+		BrokerageMax:    req.BrokerageMax,
+		STTRate:         req.STTRate,
+		GSTRate:         req.GSTRate,
+		StampDutyRate:   req.StampDutyRate,
+		ExchangeTxnRate: req.ExchangeTxnRate,
+		SEBIFeeRate:     req.SEBIFeeRate,
+	}
+
+	if err := h.DB.WithContext(c.Request.Context()).Create(&schedule).Error; err != nil {
+		log.WithError(err).Error("Failed to create fee schedule")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create fee schedule",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	log.WithField("fee_schedule_id", schedule.ID).Info("Fee schedule created")
+	c.JSON(http.StatusCreated, schedule)
+}
@@ -1,7 +1,8 @@
 package controllers
 
 import (
-	"assignment/initializers"
+	"assignment/app"
+	"assignment/decimal"
 	"assignment/models"
 	"assignment/services"
 	"net/http"
@@ -10,17 +11,24 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func GetHistoricalINR(c *gin.Context) {
-	log := initializers.Log
-	userID := c.Param("userId")
+type HistoricalController struct {
+	*app.App
+}
 
-	now := time.Now()
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+func NewHistoricalController(a *app.App) *HistoricalController {
+	return &HistoricalController{App: a}
+}
 
-	var rewards []models.StockReward
-	err := initializers.DB.Where("user_id = ? AND reward_timestamp < ?", userID, today).
-		Find(&rewards).Error
+func (h *HistoricalController) GetHistoricalINR(c *gin.Context) {
+	log := h.Log
+	userID := c.Param("userId")
+
+	today, location, ok := requestToday(c, h.Clock.Now(), h.Location)
+	if !ok {
+		return
+	}
 
+	movements, err := services.GetHoldingMovements(h.DB, userID, today.Start)
 	if err != nil {
 		log.WithError(err).WithField("user_id", userID).Error("Failed to fetch historical data")
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -30,30 +38,90 @@ func GetHistoricalINR(c *gin.Context) {
 		return
 	}
 
-	dailyValues := make(map[string]float64)
+	dailyValues := make(map[string]decimal.Decimal)
 
-	for _, reward := range rewards {
-		dateStr := reward.RewardTimestamp.Format("2006-01-02")
-		currentPrice, err := services.GetCurrentStockPrice(reward.StockSymbol)
-		if err != nil {
-			continue
-		}
+	if len(movements) == 0 {
+		c.JSON(http.StatusOK, models.HistoricalINRResponse{
+			UserID:      userID,
+			DailyValues: dailyValues,
+		})
+		return
+	}
 
-		dailyValues[dateStr] += reward.Quantity * currentPrice
+	var days []time.Time
+	var dayEnds []time.Time
+	for day := services.DayRange(movements[0].EffectiveAt, location); day.Start.Before(today.Start); day = services.DayRange(day.End, location) {
+		days = append(days, day.Start)
+		dayEnds = append(dayEnds, day.End)
 	}
 
-	for date, value := range dailyValues {
-		dailyValues[date] = float64(int(value*100)) / 100
+	var symbols []string
+	seenSymbols := make(map[string]bool)
+	for _, movement := range movements {
+		if !seenSymbols[movement.StockSymbol] {
+			seenSymbols[movement.StockSymbol] = true
+			symbols = append(symbols, movement.StockSymbol)
+		}
 	}
 
-	response := models.HistoricalINRResponse{
-		UserID:      userID,
-		DailyValues: dailyValues,
+	closingPrices := make(map[string][]models.StockPrice)
+	for _, symbol := range symbols {
+		prices, err := services.GetClosingPrices(h.DB, symbol, dayEnds)
+		if err != nil {
+			log.WithError(err).WithField("stock_symbol", symbol).Error("Failed to fetch closing prices")
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to fetch closing prices",
+				"details": err.Error(),
+			})
+			return
+		}
+		closingPrices[symbol] = prices
 	}
 
-	if response.DailyValues == nil {
-		response.DailyValues = make(map[string]float64)
+	holdings := make(map[string]decimal.Decimal)
+	lastKnownPrices := make(map[string]decimal.Decimal)
+	pricedAt := make(map[string]time.Time)
+	next := 0
+
+	for i, day := range days {
+		for next < len(movements) && movements[next].EffectiveAt.Before(dayEnds[i]) {
+			movement := movements[next]
+			symbol := movement.StockSymbol
+			before := holdings[symbol]
+			holdings[symbol] = before.Add(movement.Quantity)
+
+			if price := services.MovementPrice(movement); price.IsPositive() {
+				lastKnownPrices[symbol] = price
+				pricedAt[symbol] = movement.EffectiveAt
+			} else if movement.EntryType == models.EntryTypeCorporateAction && before.IsPositive() && movement.Quantity.IsPositive() {
+				if i > 0 {
+					if closing := closingPrices[symbol][i-1]; closing.Price.IsPositive() && !closing.Timestamp.Before(pricedAt[symbol]) {
+						lastKnownPrices[symbol] = closing.Price
+					}
+				}
+				lastKnownPrices[symbol] = lastKnownPrices[symbol].Mul(before).Div(holdings[symbol], 4)
+				pricedAt[symbol] = movement.EffectiveAt
+			}
+			next++
+		}
+
+		value := decimal.Zero
+		for symbol, quantity := range holdings {
+			if quantity.IsZero() {
+				continue
+			}
+			price := lastKnownPrices[symbol]
+			if closing := closingPrices[symbol][i]; closing.Price.IsPositive() && !closing.Timestamp.Before(pricedAt[symbol]) {
+				price = closing.Price
+			}
+			value = value.Add(quantity.Mul(price))
+		}
+
+		dailyValues[day.Format("2006-01-02")] = value.RoundBank(2)
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, models.HistoricalINRResponse{
+		UserID:      userID,
+		DailyValues: dailyValues,
+	})
 }
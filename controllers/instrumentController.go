@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"assignment/app"
+	"assignment/models"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type InstrumentController struct {
+	*app.App
+}
+
+func NewInstrumentController(a *app.App) *InstrumentController {
+	return &InstrumentController{App: a}
+}
+
+func (h *InstrumentController) ListInstruments(c *gin.Context) {
+	log := h.Log
+
+	query := h.DB.Order("symbol")
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", strings.ToUpper(status))
+	}
+
+	var instruments []models.Instrument
+	if err := query.Find(&instruments).Error; err != nil {
+		log.WithError(err).Error("Failed to fetch instruments")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch instruments",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, instruments)
+}
+
+func (h *InstrumentController) GetInstrument(c *gin.Context) {
+	instrument, ok := h.findInstrument(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, instrument)
+}
+
+func (h *InstrumentController) CreateInstrument(c *gin.Context) {
+	log := h.Log
+	var req models.InstrumentRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	instrument := models.Instrument{Symbol: strings.ToUpper(req.Symbol)}
+	applyInstrumentRequest(&instrument, req)
+
+	var count int64
+	if err := h.DB.WithContext(c.Request.Context()).Model(&models.Instrument{}).Where("symbol = ?", instrument.Symbol).Count(&count).Error; err != nil {
+		log.WithError(err).WithField("symbol", instrument.Symbol).Error("Failed to check instrument")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to check instrument",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": fmt.Sprintf("Instrument '%s' already exists", instrument.Symbol),
+		})
+		return
+	}
+
+	if err := h.DB.WithContext(c.Request.Context()).Create(&instrument).Error; err != nil {
+		log.WithError(err).WithField("symbol", instrument.Symbol).Error("Failed to create instrument")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create instrument",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	log.WithField("symbol", instrument.Symbol).Info("Instrument created")
+	c.JSON(http.StatusCreated, instrument)
+}
+
+func (h *InstrumentController) UpdateInstrument(c *gin.Context) {
+	log := h.Log
+
+	instrument, ok := h.findInstrument(c)
+	if !ok {
+		return
+	}
+
+	var req models.InstrumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if !strings.EqualFold(req.Symbol, instrument.Symbol) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Symbol in body does not match the instrument being updated",
+		})
+		return
+	}
+
+	applyInstrumentRequest(instrument, req)
+
+	if err := h.DB.WithContext(c.Request.Context()).Save(instrument).Error; err != nil {
+		log.WithError(err).WithField("symbol", instrument.Symbol).Error("Failed to update instrument")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to update instrument",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	log.WithField("symbol", instrument.Symbol).Info("Instrument updated")
+	c.JSON(http.StatusOK, instrument)
+}
+
+func (h *InstrumentController) DeleteInstrument(c *gin.Context) {
+	log := h.Log
+
+	instrument, ok := h.findInstrument(c)
+	if !ok {
+		return
+	}
+
+	instrument.Status = models.InstrumentStatusDelisted
+	if err := h.DB.WithContext(c.Request.Context()).Save(instrument).Error; err != nil {
+		log.WithError(err).WithField("symbol", instrument.Symbol).Error("Failed to delist instrument")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to delist instrument",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	log.WithField("symbol", instrument.Symbol).Info("Instrument delisted")
+	c.JSON(http.StatusOK, instrument)
+}
+
+func (h *InstrumentController) findInstrument(c *gin.Context) (*models.Instrument, bool) {
+	symbol := strings.ToUpper(c.Param("symbol"))
+
+	var instrument models.Instrument
+	err := h.DB.WithContext(c.Request.Context()).Where("symbol = ?", symbol).First(&instrument).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": fmt.Sprintf("Instrument '%s' not found", symbol),
+		})
+		return nil, false
+	} else if err != nil {
+		h.Log.WithError(err).WithField("symbol", symbol).Error("Failed to fetch instrument")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch instrument",
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+
+	return &instrument, true
+}
+
+func applyInstrumentRequest(instrument *models.Instrument, req models.InstrumentRequest) {
+	instrument.ISIN = strings.ToUpper(req.ISIN)
+	instrument.Exchange = req.Exchange
+	instrument.InstrumentType = req.InstrumentType
+	if instrument.InstrumentType == "" {
+		instrument.InstrumentType = models.InstrumentTypeEquity
+	}
+	instrument.Name = req.Name
+	instrument.LotSize = req.LotSize
+	if instrument.LotSize == 0 {
+		instrument.LotSize = 1
+	}
+	instrument.Status = req.Status
+	if instrument.Status == "" {
+		instrument.Status = models.InstrumentStatusActive
+	}
+}
@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"assignment/app"
+	"assignment/models"
+	"assignment/services"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LedgerController struct {
+	*app.App
+}
+
+func NewLedgerController(a *app.App) *LedgerController {
+	return &LedgerController{App: a}
+}
+
+func (h *LedgerController) ListLedgerEntries(c *gin.Context) {
+	log := h.Log
+	var query models.LedgerEntryQuery
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	query.AccountType = strings.ToUpper(query.AccountType)
+	query.StockSymbol = strings.ToUpper(query.StockSymbol)
+
+	response, err := services.ListLedgerEntries(h.DB, query)
+	if err != nil {
+		log.WithError(err).Error("Failed to fetch ledger entries")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch ledger entries",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *LedgerController) GetAccountBalances(c *gin.Context) {
+	log := h.Log
+
+	asOf, ok := parseTimeQuery(c, "as_of", h.Clock.Now())
+	if !ok {
+		return
+	}
+
+	balances, err := services.GetAccountBalances(h.DB, asOf)
+	if err != nil {
+		log.WithError(err).Error("Failed to fetch account balances")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch account balances",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AccountBalancesResponse{
+		AsOf:     asOf,
+		Balances: balances,
+	})
+}
+
+func (h *LedgerController) GetTrialBalance(c *gin.Context) {
+	log := h.Log
+
+	asOf, ok := parseTimeQuery(c, "as_of", h.Clock.Now())
+	if !ok {
+		return
+	}
+
+	trialBalance, err := services.GetTrialBalance(h.DB, asOf)
+	if err != nil {
+		log.WithError(err).Error("Failed to compute trial balance")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to compute trial balance",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if !trialBalance.Balanced {
+		log.WithField("unbalanced_rewards", len(trialBalance.UnbalancedRewards)).Warn("Trial balance does not balance")
+	}
+
+	c.JSON(http.StatusOK, trialBalance)
+}
+
+func parseTimeQuery(c *gin.Context, name string, fallback time.Time) (time.Time, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return fallback, true
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid '" + name + "' parameter, expected RFC 3339 timestamp",
+			"details": err.Error(),
+		})
+		return time.Time{}, false
+	}
+
+	return parsed, true
+}
@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"assignment/app"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LimitController struct {
+	*app.App
+}
+
+func NewLimitController(a *app.App) *LimitController {
+	return &LimitController{App: a}
+}
+
+func (h *LimitController) GetUserLimits(c *gin.Context) {
+	log := h.Log
+	userID := c.Param("userId")
+
+	response, err := h.Limits.GetUserLimits(h.DB, userID, h.Clock.Now())
+	if err != nil {
+		log.WithError(err).WithField("user_id", userID).Error("Failed to fetch reward limits")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch reward limits",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
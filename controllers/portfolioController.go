@@ -1,24 +1,40 @@
 package controllers
 
 import (
-	"assignment/initializers"
+	"assignment/app"
+	"assignment/decimal"
 	"assignment/models"
 	"assignment/services"
 	"net/http"
-	"time"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
-func GetUserPortfolio(c *gin.Context) {
-	log := initializers.Log
+type PortfolioController struct {
+	*app.App
+}
+
+func NewPortfolioController(a *app.App) *PortfolioController {
+	return &PortfolioController{App: a}
+}
+
+func (h *PortfolioController) GetUserPortfolio(c *gin.Context) {
+	log := h.Log
 	userID := c.Param("userId")
 
-	var rewards []models.StockReward
-	err := initializers.DB.Where("user_id = ?", userID).
-		Order("stock_symbol").
-		Find(&rewards).Error
+	includeFees, err := strconv.ParseBool(c.DefaultQuery("include_fees", strconv.FormatBool(h.Portfolio.IncludeFees)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid include_fees, expected true or false",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	market := h.Calendar.Status(h.Clock.Now())
 
+	positions, err := services.GetUserPositions(h.DB, userID, market.PreviousCloseAt)
 	if err != nil {
 		log.WithError(err).WithField("user_id", userID).Error("Failed to fetch portfolio")
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -28,17 +44,12 @@ func GetUserPortfolio(c *gin.Context) {
 		return
 	}
 
-	var symbols []string
-	stockMap := make(map[string]float64)
-
-	for _, reward := range rewards {
-		if _, exists := stockMap[reward.StockSymbol]; !exists {
-			symbols = append(symbols, reward.StockSymbol)
-		}
-		stockMap[reward.StockSymbol] += reward.Quantity
+	symbols := make([]string, 0, len(positions))
+	for _, position := range positions {
+		symbols = append(symbols, position.StockSymbol)
 	}
 
-	prices, err := services.GetCurrentPrices(symbols)
+	prices, err := h.Prices.GetCurrentPrices(symbols)
 	if err != nil {
 		log.WithError(err).WithField("user_id", userID).Error("Failed to fetch current prices")
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -48,34 +59,86 @@ func GetUserPortfolio(c *gin.Context) {
 		return
 	}
 
+	previousCloses, err := services.GetPreviousCloses(h.DB, symbols, market.PreviousCloseAt)
+	if err != nil {
+		log.WithError(err).WithField("user_id", userID).Error("Failed to fetch previous closing prices")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch previous closing prices",
+			"details": err.Error(),
+		})
+		return
+	}
+
 	var userHoldings []models.UserStockHolding
-	totalValue := 0.0
+	totalValue := decimal.Zero
+	totalInvested := decimal.Zero
+	totalDayChange := decimal.Zero
 
-	for _, symbol := range symbols {
-		quantity := stockMap[symbol]
+	for _, position := range positions {
+		symbol := position.StockSymbol
+		quantity := position.Quantity
 		price := prices[symbol]
-		currentValue := quantity * price
+		currentValue := quantity.Mul(price)
+
+		invested := position.BookValue
+		if includeFees {
+			invested = invested.Add(position.Charges)
+		}
+		gain := currentValue.Sub(invested)
+
+		dayChange := decimal.Zero
+		previousClose, hasClose := previousCloses[symbol]
+		if hasClose && position.OpeningQuantity.IsPositive() {
+			openingValue := position.OpeningQuantity.Mul(previousClose)
+			dayChange = position.CarriedQuantity.Mul(price).Sub(openingValue)
+		}
 
 		userHoldings = append(userHoldings, models.UserStockHolding{
-			StockSymbol:   symbol,
-			TotalQuantity: float64(int(quantity*1000000)) / 1000000,
-			CurrentPrice:  float64(int(price*100)) / 100,
-			CurrentValue:  float64(int(currentValue*100)) / 100,
+			StockSymbol:           symbol,
+			TotalQuantity:         quantity.RoundBank(6),
+			LockedQuantity:        position.LockedQuantity.RoundBank(6),
+			FreeQuantity:          quantity.Sub(position.LockedQuantity).RoundBank(6),
+			CurrentPrice:          price.RoundBank(2),
+			CurrentValue:          currentValue.RoundBank(2),
+			AverageCost:           invested.Div(quantity, 4),
+			TotalInvested:         invested.RoundBank(2),
+			UnrealizedGain:        gain.RoundBank(2),
+			UnrealizedGainPercent: percentOf(gain, invested),
+			PreviousClose:         previousClose.RoundBank(2),
+			DayChange:             dayChange.RoundBank(2),
+			DayChangePercent:      percentOf(dayChange, position.OpeningQuantity.Mul(previousClose)),
 		})
 
-		totalValue += currentValue
+		totalValue = totalValue.Add(currentValue)
+		totalInvested = totalInvested.Add(invested)
+		totalDayChange = totalDayChange.Add(dayChange)
 	}
 
 	if userHoldings == nil {
 		userHoldings = []models.UserStockHolding{}
 	}
 
+	totalGain := totalValue.Sub(totalInvested)
+
 	response := models.PortfolioResponse{
-		UserID:      userID,
-		Holdings:    userHoldings,
-		TotalValue:  float64(int(totalValue*100)) / 100,
-		LastUpdated: time.Now(),
+		UserID:                userID,
+		Holdings:              userHoldings,
+		TotalValue:            totalValue.RoundBank(2),
+		TotalInvested:         totalInvested.RoundBank(2),
+		UnrealizedGain:        totalGain.RoundBank(2),
+		UnrealizedGainPercent: percentOf(totalGain, totalInvested),
+		DayChange:             totalDayChange.RoundBank(2),
+		CostIncludesFees:      includeFees,
+		Market:                market,
+		LastUpdated:           h.Clock.Now(),
 	}
 
 	c.JSON(http.StatusOK, response)
 }
+
+func percentOf(change, base decimal.Decimal) decimal.Decimal {
+	if !base.IsPositive() {
+		return decimal.Zero
+	}
+	return change.Mul(decimal.NewFromInt(100)).Div(base, 2)
+}
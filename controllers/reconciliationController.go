@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"assignment/app"
+	"assignment/models"
+	"assignment/services"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type ReconciliationController struct {
+	*app.App
+}
+
+func NewReconciliationController(a *app.App) *ReconciliationController {
+	return &ReconciliationController{App: a}
+}
+
+func (h *ReconciliationController) RunReconciliation(c *gin.Context) {
+	log := h.Log
+
+	run, err := services.RunReconciliation(h.DB.WithContext(c.Request.Context()), h.Clock)
+	if err != nil {
+		log.WithError(err).Error("Reconciliation run failed")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Reconciliation run failed",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	entry := log.WithFields(logrus.Fields{
+		"reconciliation_run_id": run.ID,
+		"rewards_scanned":       run.RewardsScanned,
+		"findings":              len(run.Findings),
+	})
+	if run.Status == models.ReconciliationStatusClean {
+		entry.Info("Reconciliation run completed")
+	} else {
+		entry.Warn("Reconciliation run found ledger issues")
+	}
+
+	c.JSON(http.StatusCreated, run)
+}
+
+func (h *ReconciliationController) ListReconciliationRuns(c *gin.Context) {
+	log := h.Log
+
+	var runs []models.ReconciliationRun
+	err := h.DB.Order("started_at DESC, id DESC").Limit(50).Find(&runs).Error
+	if err != nil {
+		log.WithError(err).Error("Failed to fetch reconciliation runs")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch reconciliation runs",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, runs)
+}
+
+func (h *ReconciliationController) GetReconciliationRun(c *gin.Context) {
+	log := h.Log
+	runID := c.Param("id")
+
+	var run models.ReconciliationRun
+	err := h.DB.Where("id = ?", runID).First(&run).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": fmt.Sprintf("Reconciliation run '%s' not found", runID),
+		})
+		return
+	} else if err != nil {
+		log.WithError(err).WithField("reconciliation_run_id", runID).Error("Failed to fetch reconciliation run")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch reconciliation run",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"assignment/app"
+	"assignment/models"
+	"assignment/services"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type RedemptionController struct {
+	*app.App
+}
+
+func NewRedemptionController(a *app.App) *RedemptionController {
+	return &RedemptionController{App: a}
+}
+
+func (h *RedemptionController) RedeemShares(c *gin.Context) {
+	log := h.Log
+	var req models.RedemptionRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.WithError(err).Warn("Invalid redemption request")
+		c.JSON(http.StatusBadRequest, models.RedemptionResponse{
+			Success: false,
+			Message: fmt.Sprintf("Invalid request: %v", err),
+		})
+		return
+	}
+
+	response, replayed, err := services.CreateRedemption(h.DB.WithContext(c.Request.Context()), h.Clock, h.Prices, req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrRedemptionConflict):
+			status = http.StatusConflict
+		case errors.Is(err, services.ErrInsufficientHoldings) || errors.Is(err, services.ErrUnknownInstrument) ||
+			errors.Is(err, services.ErrInstrumentNotActive):
+			status = http.StatusUnprocessableEntity
+		}
+
+		entry := log.WithError(err).WithField("redemption_id", req.ID)
+		if status == http.StatusInternalServerError {
+			entry.Error("Failed to record redemption")
+		} else {
+			entry.Warn("Rejected redemption")
+		}
+		c.JSON(status, models.RedemptionResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if replayed {
+		log.WithField("redemption_id", req.ID).Info("Replayed response for duplicate redemption request")
+		c.JSON(http.StatusCreated, response)
+		return
+	}
+
+	log.WithFields(logrus.Fields{
+		"redemption_id": response.Redemption.ID,
+		"user_id":       response.Redemption.UserID,
+		"symbol":        response.Redemption.StockSymbol,
+		"quantity":      response.Redemption.Quantity.String(),
+		"net_amount":    response.Redemption.NetAmount.String(),
+	}).Info("Redemption recorded successfully")
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *RedemptionController) GetUserRedemptions(c *gin.Context) {
+	log := h.Log
+	userID := c.Param("userId")
+
+	redemptions, err := services.ListUserRedemptions(h.DB, userID)
+	if err != nil {
+		log.WithError(err).WithField("user_id", userID).Error("Failed to fetch redemptions")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch redemptions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, redemptions)
+}
@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"assignment/app"
+	"assignment/models"
+	"assignment/services"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/sirupsen/logrus"
+)
+
+type RewardBatchController struct {
+	*app.App
+}
+
+func NewRewardBatchController(a *app.App) *RewardBatchController {
+	return &RewardBatchController{App: a}
+}
+
+// maxBatchItemBytes is the body size allowed per batch item, far more than a
+// reward request needs even when pretty-printed.
+const maxBatchItemBytes = 4 << 10
+
+func (h *RewardBatchController) RewardUsersBatch(c *gin.Context) {
+	log := h.Log
+
+	maxBytes := int64(h.Batch.MaxItems) * maxBatchItemBytes
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error": fmt.Sprintf("Batch body exceeds %d bytes", maxBytes),
+		})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to read request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	rawItems, err := splitBatchItems(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid batch payload, expected a JSON array or NDJSON",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	maxItems := h.Batch.MaxItems
+	if len(rawItems) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Batch contains no rewards",
+		})
+		return
+	} else if len(rawItems) > maxItems {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error": fmt.Sprintf("Batch contains %d rewards, the limit is %d", len(rawItems), maxItems),
+		})
+		return
+	}
+
+	results := make([]models.BatchItemResult, len(rawItems))
+	var items []models.BatchRewardItem
+
+	for i, raw := range rawItems {
+		var req models.RewardRequest
+		err := json.Unmarshal(raw, &req)
+		if err == nil {
+			err = binding.Validator.ValidateStruct(&req)
+		}
+
+		if err != nil {
+			results[i] = models.BatchItemResult{
+				Index:   i,
+				ID:      req.ID,
+				Status:  models.BatchStatusInvalid,
+				Message: fmt.Sprintf("Invalid request: %v", err),
+			}
+			continue
+		}
+
+		items = append(items, models.BatchRewardItem{Index: i, Request: req})
+	}
+
+	for _, result := range services.CreateRewardBatch(h.DB.WithContext(c.Request.Context()), h.Prices, h.Limits, items) {
+		results[result.Index] = result
+	}
+
+	response := models.BatchRewardResponse{
+		Total:   len(results),
+		Results: results,
+	}
+	for _, result := range results {
+		switch result.Status {
+		case models.BatchStatusCreated:
+			response.Created++
+		case models.BatchStatusDuplicate:
+			response.Duplicate++
+		case models.BatchStatusInvalid:
+			response.Invalid++
+		case models.BatchStatusFailed:
+			response.Failed++
+		}
+	}
+
+	log.WithFields(logrus.Fields{
+		"total":     response.Total,
+		"created":   response.Created,
+		"duplicate": response.Duplicate,
+		"invalid":   response.Invalid,
+		"failed":    response.Failed,
+	}).Info("Reward batch processed")
+
+	c.JSON(http.StatusOK, response)
+}
+
+// splitBatchItems accepts either a JSON array of rewards or one reward per
+// line (NDJSON) and returns the raw item payloads.
+func splitBatchItems(body []byte) ([]json.RawMessage, error) {
+	trimmed := bytes.TrimSpace(body)
+
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		var items []json.RawMessage
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, err
+		}
+		return items, nil
+	}
+
+	var items []json.RawMessage
+	scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		items = append(items, json.RawMessage(append([]byte(nil), line...)))
+	}
+
+	return items, scanner.Err()
+}
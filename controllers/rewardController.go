@@ -1,19 +1,27 @@
 package controllers
 
 import (
-	"assignment/initializers"
+	"assignment/app"
 	"assignment/models"
 	"assignment/services"
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
-	"gorm.io/gorm"
 )
 
-func RewardUser(c *gin.Context) {
-	log := initializers.Log
+type RewardController struct {
+	*app.App
+}
+
+func NewRewardController(a *app.App) *RewardController {
+	return &RewardController{App: a}
+}
+
+func (h *RewardController) RewardUser(c *gin.Context) {
+	log := h.Log
 	var req models.RewardRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -25,81 +33,145 @@ func RewardUser(c *gin.Context) {
 		return
 	}
 
-	var existingReward models.StockReward
-	err := initializers.DB.Where("id = ?", req.ID).First(&existingReward).Error
+	response, replayed, err := services.CreateReward(h.DB.WithContext(c.Request.Context()), h.Prices, h.Limits, req)
+	if err != nil {
+		var conflict *services.RewardConflictError
+		var limitErr *services.LimitExceededError
+		switch {
+		case errors.As(err, &conflict):
+			log.WithField("reward_id", req.ID).Warn("Duplicate reward ID")
+			c.JSON(http.StatusConflict, models.RewardResponse{
+				Success: false,
+				Message: conflict.Error(),
+				Diff:    conflict.Diff,
+			})
+		case errors.As(err, &limitErr):
+			log.WithError(err).WithFields(logrus.Fields{
+				"reward_id": req.ID,
+				"user_id":   req.UserID,
+				"code":      limitErr.Code,
+			}).Warn("Rejected reward over limit")
+			c.JSON(http.StatusUnprocessableEntity, models.RewardResponse{
+				Success: false,
+				Message: limitErr.Error(),
+				Code:    limitErr.Code,
+			})
+		case errors.Is(err, services.ErrUnknownInstrument) || errors.Is(err, services.ErrInstrumentNotActive) ||
+			errors.Is(err, services.ErrRewardBeforeExDate):
+			log.WithError(err).WithField("stock_symbol", req.StockSymbol).Warn("Rejected reward for untradable symbol")
+			c.JSON(http.StatusUnprocessableEntity, models.RewardResponse{
+				Success: false,
+				Message: err.Error(),
+			})
+		case errors.Is(err, services.ErrUnknownCampaign) || errors.Is(err, services.ErrCampaignNotActive) ||
+			errors.Is(err, services.ErrSymbolNotEligible):
+			log.WithError(err).WithField("campaign_id", req.CampaignID).Warn("Rejected reward for campaign")
+			c.JSON(http.StatusUnprocessableEntity, models.RewardResponse{
+				Success: false,
+				Message: err.Error(),
+			})
+		default:
+			log.WithError(err).WithField("reward_id", req.ID).Error("Failed to record reward")
+			c.JSON(http.StatusInternalServerError, models.RewardResponse{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+		return
+	}
 
-	if err == nil {
-		log.WithField("reward_id", req.ID).Warn("Duplicate reward ID")
-		c.JSON(http.StatusConflict, models.RewardResponse{
-			Success: false,
-			Message: fmt.Sprintf("Reward with ID '%s' has already been processed", req.ID),
-		})
+	if replayed {
+		log.WithField("reward_id", req.ID).Info("Replayed response for duplicate reward request")
+		c.JSON(http.StatusCreated, response)
 		return
-	} else if err != gorm.ErrRecordNotFound {
-		log.WithError(err).WithField("reward_id", req.ID).Error("Database error checking reward")
-		c.JSON(http.StatusInternalServerError, models.RewardResponse{
+	}
+
+	log.WithFields(logrus.Fields{
+		"reward_id":  response.Reward.ID,
+		"user_id":    response.Reward.UserID,
+		"symbol":     response.Reward.StockSymbol,
+		"quantity":   response.Reward.Quantity.String(),
+		"total_cost": response.CompanyCharges.TotalCost.String(),
+	}).Info("Reward recorded successfully")
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *RewardController) ReverseReward(c *gin.Context) {
+	log := h.Log
+	rewardID := c.Param("id")
+
+	var req models.ReverseRewardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.WithError(err).WithField("reward_id", rewardID).Warn("Invalid reversal request")
+		c.JSON(http.StatusBadRequest, models.ReversalResponse{
 			Success: false,
-			Message: fmt.Sprintf("Database error: %v", err),
+			Message: fmt.Sprintf("Invalid request: %v", err),
 		})
 		return
 	}
 
-	stockPrice, err := services.GetCurrentStockPrice(req.StockSymbol)
+	reward, entries, err := services.ReverseReward(h.DB.WithContext(c.Request.Context()), h.Clock, h.Limits, rewardID, req.Reason)
 	if err != nil {
-		log.WithError(err).WithField("stock_symbol", req.StockSymbol).Error("Failed to get stock price")
-		c.JSON(http.StatusInternalServerError, models.RewardResponse{
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrRewardNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, services.ErrRewardAlreadyReversed) || errors.Is(err, services.ErrRewardSharesDisposed) ||
+			errors.Is(err, services.ErrRewardSharesLocked):
+			status = http.StatusConflict
+		}
+
+		log.WithError(err).WithField("reward_id", rewardID).Warn("Failed to reverse reward")
+		c.JSON(status, models.ReversalResponse{
 			Success: false,
-			Message: fmt.Sprintf("Failed to get stock price: %v", err),
+			Message: err.Error(),
 		})
 		return
 	}
 
-	stockCost := stockPrice * req.Quantity
-	charges := services.CalculateCompanyCharges(stockCost)
-
-	reward := &models.StockReward{
-		ID:                 req.ID,
-		UserID:             req.UserID,
-		StockSymbol:        req.StockSymbol,
-		Quantity:           req.Quantity,
-		RewardTimestamp:    req.RewardTimestamp,
-		StockPriceAtReward: stockPrice,
-	}
-
-	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Create(reward).Error; err != nil {
-			return fmt.Errorf("failed to record reward: %v", err)
-		}
-
-		if err := services.RecordLedgerEntriesGORM(tx, reward, charges); err != nil {
-			return fmt.Errorf("failed to record ledger entries: %v", err)
-		}
+	log.WithFields(logrus.Fields{
+		"reward_id": reward.ID,
+		"user_id":   reward.UserID,
+		"reason":    req.Reason,
+	}).Info("Reward reversed successfully")
 
-		return nil
+	c.JSON(http.StatusOK, models.ReversalResponse{
+		Success:       true,
+		Message:       "Stock reward reversed successfully",
+		Reward:        reward,
+		LedgerEntries: entries,
 	})
+}
 
-	if err != nil {
-		log.WithError(err).WithField("reward_id", req.ID).Error("Failed to record reward")
-		c.JSON(http.StatusInternalServerError, models.RewardResponse{
-			Success: false,
-			Message: err.Error(),
+func (h *RewardController) ListUserRewards(c *gin.Context) {
+	log := h.Log
+	userID := c.Param("userId")
+
+	var query models.RewardListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
 		})
 		return
 	}
 
-	log.WithFields(logrus.Fields{
-		"reward_id":  reward.ID,
-		"user_id":    reward.UserID,
-		"symbol":     reward.StockSymbol,
-		"quantity":   reward.Quantity,
-		"total_cost": charges.TotalCost,
-	}).Info("Reward recorded successfully")
+	response, err := services.ListUserRewards(h.DB, userID, query)
+	if errors.Is(err, services.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid cursor",
+			"details": err.Error(),
+		})
+		return
+	} else if err != nil {
+		log.WithError(err).WithField("user_id", userID).Error("Failed to fetch user rewards")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch user rewards",
+			"details": err.Error(),
+		})
+		return
+	}
 
-	c.JSON(http.StatusCreated, models.RewardResponse{
-		Success:        true,
-		Message:        "Stock reward recorded successfully",
-		Reward:         reward,
-		INRValue:       stockCost,
-		CompanyCharges: charges,
-	})
+	c.JSON(http.StatusOK, response)
 }
@@ -1,28 +1,33 @@
 package controllers
 
 import (
-	"assignment/initializers"
+	"assignment/app"
+	"assignment/decimal"
 	"assignment/models"
 	"assignment/services"
 	"net/http"
-	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-func GetUserStats(c *gin.Context) {
-	log := initializers.Log
-	userID := c.Param("userId")
+type StatsController struct {
+	*app.App
+}
+
+func NewStatsController(a *app.App) *StatsController {
+	return &StatsController{App: a}
+}
 
-	now := time.Now()
-	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	endOfDay := startOfDay.Add(24 * time.Hour)
+func (h *StatsController) GetUserStats(c *gin.Context) {
+	log := h.Log
+	userID := c.Param("userId")
 
-	var allRewards []models.StockReward
-	err := initializers.DB.Where("user_id = ?", userID).
-		Order("reward_timestamp DESC").
-		Find(&allRewards).Error
+	today, _, ok := requestToday(c, h.Clock.Now(), h.Location)
+	if !ok {
+		return
+	}
 
+	todayTotals, err := services.GetRewardTotals(h.DB, userID, today)
 	if err != nil {
 		log.WithError(err).WithField("user_id", userID).Error("Failed to fetch user rewards")
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -32,36 +37,29 @@ func GetUserStats(c *gin.Context) {
 		return
 	}
 
-	if len(allRewards) == 0 {
-		c.JSON(http.StatusOK, models.StatsResponse{
-			UserID:              userID,
-			TodayRewards:        make(map[string]float64),
-			CurrentPortfolioINR: 0.0,
-			TotalSharesRewarded: 0.0,
-		})
-		return
+	todayRewardsMap := make(map[string]decimal.Decimal)
+	for _, total := range todayTotals {
+		todayRewardsMap[total.StockSymbol] = total.Quantity
 	}
 
-	todayRewardsMap := make(map[string]float64)
-	portfolioQuantities := make(map[string]float64)
-	totalSharesRewarded := 0.0
-	uniqueSymbols := make(map[string]bool)
-
-	for _, reward := range allRewards {
-		uniqueSymbols[reward.StockSymbol] = true
-		totalSharesRewarded += reward.Quantity
-		portfolioQuantities[reward.StockSymbol] += reward.Quantity
-		if reward.RewardTimestamp.After(startOfDay) && reward.RewardTimestamp.Before(endOfDay) {
-			todayRewardsMap[reward.StockSymbol] += reward.Quantity
-		}
+	holdings, err := services.GetUserHoldings(h.DB, userID)
+	if err != nil {
+		log.WithError(err).WithField("user_id", userID).Error("Failed to fetch user holdings")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch user holdings",
+			"details": err.Error(),
+		})
+		return
 	}
 
-	symbolsList := make([]string, 0, len(uniqueSymbols))
-	for symbol := range uniqueSymbols {
-		symbolsList = append(symbolsList, symbol)
+	totalSharesRewarded := decimal.Zero
+	symbolsList := make([]string, 0, len(holdings))
+	for _, holding := range holdings {
+		totalSharesRewarded = totalSharesRewarded.Add(holding.Quantity)
+		symbolsList = append(symbolsList, holding.StockSymbol)
 	}
 
-	prices, err := services.GetCurrentPrices(symbolsList)
+	prices, err := h.Prices.GetCurrentPrices(symbolsList)
 	if err != nil {
 		log.WithError(err).WithField("user_id", userID).Error("Failed to fetch current prices")
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -71,18 +69,17 @@ func GetUserStats(c *gin.Context) {
 		return
 	}
 
-	totalPortfolioValue := 0.0
-	for symbol, quantity := range portfolioQuantities {
-		if price, ok := prices[symbol]; ok {
-			totalPortfolioValue += quantity * price
-		}
+	totalPortfolioValue := decimal.Zero
+	for _, holding := range holdings {
+		totalPortfolioValue = totalPortfolioValue.Add(holding.Quantity.Mul(prices[holding.StockSymbol]))
 	}
 
 	response := models.StatsResponse{
 		UserID:              userID,
 		TodayRewards:        todayRewardsMap,
-		CurrentPortfolioINR: float64(int(totalPortfolioValue*100)) / 100,
-		TotalSharesRewarded: float64(int(totalSharesRewarded*1000000)) / 1000000,
+		CurrentPortfolioINR: totalPortfolioValue.RoundBank(2),
+		TotalSharesRewarded: totalSharesRewarded.RoundBank(6),
+		Market:              h.Calendar.Status(h.Clock.Now()),
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -1,25 +1,33 @@
 package controllers
 
 import (
-	"assignment/initializers"
+	"assignment/app"
 	"assignment/models"
 	"net/http"
-	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-func GetTodayStocks(c *gin.Context) {
-	log := initializers.Log
+type TodayStocksController struct {
+	*app.App
+}
+
+func NewTodayStocksController(a *app.App) *TodayStocksController {
+	return &TodayStocksController{App: a}
+}
+
+func (h *TodayStocksController) GetTodayStocks(c *gin.Context) {
+	log := h.Log
 	userID := c.Param("userId")
 
-	now := time.Now()
-	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	endOfDay := startOfDay.Add(24 * time.Hour)
+	today, _, ok := requestToday(c, h.Clock.Now(), h.Location)
+	if !ok {
+		return
+	}
 
 	var rewards []models.StockReward
-	err := initializers.DB.Where("user_id = ? AND reward_timestamp >= ? AND reward_timestamp < ?",
-		userID, startOfDay, endOfDay).
+	err := h.DB.Where("user_id = ? AND reward_timestamp >= ? AND reward_timestamp < ?",
+		userID, today.Start.UTC(), today.End.UTC()).
 		Order("reward_timestamp DESC").
 		Find(&rewards).Error
 
@@ -34,7 +42,7 @@ func GetTodayStocks(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"user_id":       userID,
-		"date":          startOfDay.Format("2006-01-02"),
+		"date":          today.Start.Format("2006-01-02"),
 		"total_rewards": len(rewards),
 		"rewards":       rewards,
 	})
@@ -0,0 +1,333 @@
+package decimal
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an exact base-10 number stored as an integer coefficient and a
+// number of fractional digits. The zero value is 0.
+type Decimal struct {
+	value *big.Int
+	scale int32
+}
+
+var Zero = Decimal{}
+
+var bigTen = big.NewInt(10)
+
+// maxExponent bounds the exponent accepted by NewFromString, so a short input
+// such as "1e30000000" cannot expand into a huge coefficient.
+const maxExponent = 28
+
+func New(value int64, scale int32) Decimal {
+	if scale < 0 {
+		return Decimal{value: new(big.Int).Mul(big.NewInt(value), pow10(-scale))}
+	}
+	return Decimal{value: big.NewInt(value), scale: scale}
+}
+
+func NewFromInt(value int64) Decimal {
+	return New(value, 0)
+}
+
+func NewFromFloat(value float64) Decimal {
+	d, err := NewFromString(strconv.FormatFloat(value, 'f', -1, 64))
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func NewFromString(value string) (Decimal, error) {
+	original := value
+	value = strings.TrimSpace(value)
+
+	exponent := int64(0)
+	if i := strings.IndexAny(value, "eE"); i >= 0 {
+		exp, err := strconv.ParseInt(value[i+1:], 10, 32)
+		if err != nil {
+			return Zero, fmt.Errorf("can't convert %q to decimal", original)
+		}
+		if exp > maxExponent || exp < -maxExponent {
+			return Zero, fmt.Errorf("can't convert %q to decimal: exponent out of range", original)
+		}
+		exponent = exp
+		value = value[:i]
+	}
+
+	negative := false
+	if strings.HasPrefix(value, "-") || strings.HasPrefix(value, "+") {
+		negative = value[0] == '-'
+		value = value[1:]
+	}
+
+	intPart, fracPart := value, ""
+	if i := strings.IndexByte(value, '.'); i >= 0 {
+		intPart, fracPart = value[:i], value[i+1:]
+	}
+
+	digits := intPart + fracPart
+	if digits == "" || strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }) >= 0 {
+		return Zero, fmt.Errorf("can't convert %q to decimal", original)
+	}
+
+	coefficient, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return Zero, fmt.Errorf("can't convert %q to decimal", original)
+	}
+	if negative {
+		coefficient.Neg(coefficient)
+	}
+
+	scale := int64(len(fracPart)) - exponent
+	if scale > math.MaxInt32 {
+		return Zero, fmt.Errorf("can't convert %q to decimal: scale out of range", original)
+	}
+	if scale < 0 {
+		coefficient.Mul(coefficient, pow10(int32(-scale)))
+		scale = 0
+	}
+
+	return Decimal{value: coefficient, scale: int32(scale)}, nil
+}
+
+func RequireFromString(value string) Decimal {
+	d, err := NewFromString(value)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+func (d Decimal) coefficient() *big.Int {
+	if d.value == nil {
+		return new(big.Int)
+	}
+	return d.value
+}
+
+func (d Decimal) rescale(scale int32) *big.Int {
+	if scale <= d.scale {
+		return new(big.Int).Set(d.coefficient())
+	}
+	return new(big.Int).Mul(d.coefficient(), pow10(scale-d.scale))
+}
+
+func align(a, b Decimal) (*big.Int, *big.Int, int32) {
+	scale := a.scale
+	if b.scale > scale {
+		scale = b.scale
+	}
+	return a.rescale(scale), b.rescale(scale), scale
+}
+
+func (d Decimal) Add(other Decimal) Decimal {
+	a, b, scale := align(d, other)
+	return Decimal{value: a.Add(a, b), scale: scale}
+}
+
+func (d Decimal) Sub(other Decimal) Decimal {
+	a, b, scale := align(d, other)
+	return Decimal{value: a.Sub(a, b), scale: scale}
+}
+
+func (d Decimal) Mul(other Decimal) Decimal {
+	return Decimal{
+		value: new(big.Int).Mul(d.coefficient(), other.coefficient()),
+		scale: d.scale + other.scale,
+	}
+}
+
+// Div divides d by other and rounds the quotient half-to-even to places
+// fractional digits. It panics on division by zero.
+func (d Decimal) Div(other Decimal, places int32) Decimal {
+	if other.IsZero() {
+		panic("decimal division by zero")
+	}
+
+	numerator := new(big.Int).Mul(d.coefficient(), pow10(other.scale+places))
+	denominator := new(big.Int).Mul(other.coefficient(), pow10(d.scale))
+
+	return Decimal{value: roundQuotient(numerator, denominator), scale: places}
+}
+
+// RoundBank rounds to places fractional digits using banker's rounding
+// (round half to even).
+func (d Decimal) RoundBank(places int32) Decimal {
+	if d.scale <= places {
+		return Decimal{value: d.rescale(places), scale: places}
+	}
+	return Decimal{value: roundQuotient(d.coefficient(), pow10(d.scale-places)), scale: places}
+}
+
+func roundQuotient(numerator, denominator *big.Int) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+	if remainder.Sign() == 0 {
+		return quotient
+	}
+
+	twiceRemainder := new(big.Int).Abs(remainder)
+	twiceRemainder.Lsh(twiceRemainder, 1)
+
+	cmp := twiceRemainder.Cmp(new(big.Int).Abs(denominator))
+	if cmp > 0 || (cmp == 0 && quotient.Bit(0) == 1) {
+		if numerator.Sign()*denominator.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+
+	return quotient
+}
+
+func (d Decimal) Neg() Decimal {
+	return Decimal{value: new(big.Int).Neg(d.coefficient()), scale: d.scale}
+}
+
+func (d Decimal) Abs() Decimal {
+	return Decimal{value: new(big.Int).Abs(d.coefficient()), scale: d.scale}
+}
+
+func (d Decimal) Cmp(other Decimal) int {
+	a, b, _ := align(d, other)
+	return a.Cmp(b)
+}
+
+func (d Decimal) Equal(other Decimal) bool {
+	return d.Cmp(other) == 0
+}
+
+func (d Decimal) GreaterThan(other Decimal) bool {
+	return d.Cmp(other) > 0
+}
+
+func (d Decimal) GreaterThanOrEqual(other Decimal) bool {
+	return d.Cmp(other) >= 0
+}
+
+func (d Decimal) LessThan(other Decimal) bool {
+	return d.Cmp(other) < 0
+}
+
+func (d Decimal) LessThanOrEqual(other Decimal) bool {
+	return d.Cmp(other) <= 0
+}
+
+func (d Decimal) Sign() int {
+	return d.coefficient().Sign()
+}
+
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+func (d Decimal) IsPositive() bool {
+	return d.Sign() > 0
+}
+
+func (d Decimal) IsNegative() bool {
+	return d.Sign() < 0
+}
+
+func Min(a, b Decimal) Decimal {
+	if a.LessThan(b) {
+		return a
+	}
+	return b
+}
+
+func Max(a, b Decimal) Decimal {
+	if a.GreaterThan(b) {
+		return a
+	}
+	return b
+}
+
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.coefficient()).String()
+	sign := ""
+	if d.Sign() < 0 {
+		sign = "-"
+	}
+
+	if d.scale <= 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= int(d.scale) {
+		digits = strings.Repeat("0", int(d.scale)-len(digits)+1) + digits
+	}
+
+	point := len(digits) - int(d.scale)
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+func (d Decimal) StringFixed(places int32) string {
+	return d.RoundBank(places).String()
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(d.String())), nil
+}
+
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	value := string(data)
+	if value == "null" {
+		*d = Zero
+		return nil
+	}
+
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		value = unquoted
+	}
+
+	parsed, err := NewFromString(value)
+	if err != nil {
+		return err
+	}
+
+	*d = parsed
+	return nil
+}
+
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+func (d *Decimal) Scan(value interface{}) error {
+	var err error
+
+	switch v := value.(type) {
+	case nil:
+		*d = Zero
+	case float32:
+		*d, err = NewFromString(strconv.FormatFloat(float64(v), 'f', -1, 32))
+	case float64:
+		*d, err = NewFromString(strconv.FormatFloat(v, 'f', -1, 64))
+	case int64:
+		*d = NewFromInt(v)
+	case []byte:
+		*d, err = NewFromString(string(v))
+	case string:
+		*d, err = NewFromString(v)
+	default:
+		err = fmt.Errorf("can't scan %T into decimal", value)
+	}
+
+	return err
+}
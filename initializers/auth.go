@@ -0,0 +1,66 @@
+package initializers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// minAuthKeyLength is the shortest HMAC secret accepted, matching the output
+// size of SHA-256.
+const minAuthKeyLength = 32
+
+// AuthConfig holds the HMAC keys used to verify bearer tokens, by key ID. The
+// key with an empty ID verifies tokens that carry no kid header.
+type AuthConfig struct {
+	Keys       map[string][]byte
+	SigningKey string
+	Issuer     string
+	Audience   string
+	Leeway     time.Duration
+}
+
+func LoadAuthConfig() (AuthConfig, error) {
+	config := AuthConfig{
+		Keys:     make(map[string][]byte),
+		Issuer:   GetEnv("AUTH_JWT_ISSUER", ""),
+		Audience: GetEnv("AUTH_JWT_AUDIENCE", ""),
+	}
+
+	leeway, err := time.ParseDuration(GetEnv("AUTH_JWT_LEEWAY", "30s"))
+	if err != nil || leeway < 0 {
+		return config, fmt.Errorf("AUTH_JWT_LEEWAY: invalid duration %q", GetEnv("AUTH_JWT_LEEWAY", ""))
+	}
+	config.Leeway = leeway
+
+	if secret := GetEnv("AUTH_JWT_SECRET", ""); secret != "" {
+		config.Keys[""] = []byte(secret)
+	}
+
+	for _, pair := range strings.Split(GetEnv("AUTH_JWT_KEYS", ""), ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		keyID, secret, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(keyID) == "" {
+			return config, fmt.Errorf("AUTH_JWT_KEYS: expected comma-separated kid=secret pairs")
+		}
+		config.Keys[strings.TrimSpace(keyID)] = []byte(strings.TrimSpace(secret))
+	}
+
+	if len(config.Keys) == 0 {
+		return config, fmt.Errorf("AUTH_JWT_SECRET or AUTH_JWT_KEYS must be set")
+	}
+	for keyID, secret := range config.Keys {
+		if len(secret) < minAuthKeyLength {
+			return config, fmt.Errorf("auth key %q must be at least %d bytes", keyID, minAuthKeyLength)
+		}
+	}
+
+	config.SigningKey = GetEnv("AUTH_JWT_SIGNING_KID", "")
+	if _, ok := config.Keys[config.SigningKey]; !ok {
+		return config, fmt.Errorf("AUTH_JWT_SIGNING_KID: no key with ID %q", config.SigningKey)
+	}
+
+	return config, nil
+}
@@ -5,59 +5,100 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/sirupsen/logrus"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
-var DB *gorm.DB
-
-func ConnectDB() {
-	var err error
+func ConnectDB(log *logrus.Logger) *gorm.DB {
 	dbURL := os.Getenv("DB_URL")
 
 	if dbURL == "" {
-		Log.Fatal("DB_URL environment variable is not set")
+		log.Fatal("DB_URL environment variable is not set")
 	}
 
 	dbLogLevel := logger.Warn
-	DB, err = gorm.Open(postgres.Open(dbURL), &gorm.Config{
-		Logger: logger.Default.LogMode(dbLogLevel),
+	db, err := gorm.Open(postgres.Open(dbURL), &gorm.Config{
+		Logger:         logger.Default.LogMode(dbLogLevel),
+		TranslateError: true,
 	})
 
 	if err != nil {
-		Log.WithError(err).Fatal("Unable to connect to database")
+		log.WithError(err).Fatal("Unable to connect to database")
 	}
 
-	Log.Info("Connected to database successfully")
+	log.Info("Connected to database successfully")
 
-	err = runMigrations()
+	err = RunMigrations(db, log)
 	if err != nil {
-		Log.WithError(err).Fatal("Failed to run migrations")
+		log.WithError(err).Fatal("Failed to run migrations")
 	}
+
+	return db
 }
 
-func runMigrations() error {
-	Log.Info("Running database migrations...")
-	err := DB.AutoMigrate(
+// RunMigrations brings the schema up to date and backfills old ledger rows.
+func RunMigrations(db *gorm.DB, log *logrus.Logger) error {
+	log.Info("Running database migrations...")
+	err := db.AutoMigrate(
 		&models.StockReward{},
 		&models.LedgerEntry{},
+		&models.StockPrice{},
+		&models.Instrument{},
+		&models.FeeSchedule{},
+		&models.ReconciliationRun{},
+		&models.RewardIdempotencyKey{},
+		&models.CorporateAction{},
+		&models.DividendDeclaration{},
+		&models.DividendEntitlement{},
+		&models.Redemption{},
+		&models.DematTransfer{},
+		&models.DematTransferLot{},
+		&models.RewardLimitUsage{},
+		&models.RewardLimitReservation{},
+		&models.Campaign{},
 	)
 
 	if err != nil {
 		return fmt.Errorf("auto migration error: %v", err)
 	}
 
-	Log.Info("Database migrations completed successfully")
+	if err := backfillLedgerEntries(db); err != nil {
+		return fmt.Errorf("ledger backfill error: %v", err)
+	}
+
+	log.Info("Database migrations completed successfully")
 	return nil
 }
 
-func CloseDB() {
-	if DB != nil {
-		sqlDB, err := DB.DB()
-		if err == nil {
-			sqlDB.Close()
-			Log.Info("Database connection closed")
-		}
+// backfillLedgerEntries upgrades entries written before ledger_entries had an
+// effective_at column: their reversal quantities are made negative and they
+// are dated, so that holdings can be summed from the ledger. Only undated
+// rows are touched, which makes it safe to run on every start.
+func backfillLedgerEntries(db *gorm.DB) error {
+	err := db.Exec(`UPDATE ledger_entries SET quantity = -quantity
+		WHERE effective_at IS NULL AND entry_type = ? AND account_type = ? AND quantity > 0`,
+		models.EntryTypeReversal, models.AccountTypeStockAsset).Error
+	if err != nil {
+		return err
+	}
+
+	err = db.Exec(`UPDATE ledger_entries SET effective_at = created_at
+		WHERE effective_at IS NULL AND entry_type = ?`, models.EntryTypeReversal).Error
+	if err != nil {
+		return err
+	}
+
+	return db.Exec(`UPDATE ledger_entries SET effective_at =
+		(SELECT reward_timestamp FROM stock_rewards WHERE stock_rewards.id = ledger_entries.reward_id)
+		WHERE effective_at IS NULL`).Error
+}
+
+func CloseDB(db *gorm.DB, log *logrus.Logger) {
+	sqlDB, err := db.DB()
+	if err == nil {
+		sqlDB.Close()
+		log.Info("Database connection closed")
 	}
 }
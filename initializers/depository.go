@@ -0,0 +1,27 @@
+package initializers
+
+import (
+	"strings"
+	"time"
+)
+
+type DepositoryConfig struct {
+	Adapter string
+	URL     string
+	APIKey  string
+	Timeout time.Duration
+}
+
+func LoadDepositoryConfig() DepositoryConfig {
+	timeout, err := time.ParseDuration(GetEnv("DEPOSITORY_TIMEOUT", "10s"))
+	if err != nil {
+		timeout = 10 * time.Second
+	}
+
+	return DepositoryConfig{
+		Adapter: strings.ToLower(GetEnv("DEPOSITORY_ADAPTER", "sandbox")),
+		URL:     GetEnv("DEPOSITORY_URL", ""),
+		APIKey:  GetEnv("DEPOSITORY_API_KEY", ""),
+		Timeout: timeout,
+	}
+}
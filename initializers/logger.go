@@ -8,25 +8,24 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-var Log *logrus.Logger
-
-func InitLogger() {
-	Log = logrus.New()
+func NewLogger() *logrus.Logger {
+	log := logrus.New()
 
 	if level, err := logrus.ParseLevel("info"); err == nil {
-		Log.SetLevel(level)
+		log.SetLevel(level)
 	}
-	Log.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	log.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
 
 	logPath := filepath.Join(GetEnv("LOG_DIR", "logs"), GetEnv("LOG_FILE", "app.log"))
 	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err == nil {
 		if file, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666); err == nil {
-			Log.SetOutput(io.MultiWriter(os.Stdout, file))
-			Log.WithField("log_file", logPath).Info("Logger initialized")
-			return
+			log.SetOutput(io.MultiWriter(os.Stdout, file))
+			log.WithField("log_file", logPath).Info("Logger initialized")
+			return log
 		}
 	}
 
-	Log.SetOutput(os.Stdout)
-	Log.Info("Logger initialized")
+	log.SetOutput(os.Stdout)
+	log.Info("Logger initialized")
+	return log
 }
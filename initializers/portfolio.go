@@ -0,0 +1,21 @@
+package initializers
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// PortfolioConfig holds the defaults for GET /portfolio; requests can
+// override them.
+type PortfolioConfig struct {
+	IncludeFees bool
+}
+
+func LoadPortfolioConfig() (PortfolioConfig, error) {
+	raw := GetEnv("PORTFOLIO_INCLUDE_FEES", "false")
+	includeFees, err := strconv.ParseBool(raw)
+	if err != nil {
+		return PortfolioConfig{}, fmt.Errorf("PORTFOLIO_INCLUDE_FEES: expected true or false, got %q", raw)
+	}
+	return PortfolioConfig{IncludeFees: includeFees}, nil
+}
@@ -0,0 +1,38 @@
+package initializers
+
+import (
+	"strings"
+	"time"
+)
+
+type PriceProviderConfig struct {
+	Provider string
+	FilePath string
+	FeedURL  string
+	APIKey   string
+	Timeout  time.Duration
+	Symbols  []string
+}
+
+func LoadPriceProviderConfig() PriceProviderConfig {
+	timeout, err := time.ParseDuration(GetEnv("PRICE_FEED_TIMEOUT", "5s"))
+	if err != nil {
+		timeout = 5 * time.Second
+	}
+
+	var symbols []string
+	for _, symbol := range strings.Split(GetEnv("PRICE_FEED_SYMBOLS", ""), ",") {
+		if symbol = strings.TrimSpace(symbol); symbol != "" {
+			symbols = append(symbols, strings.ToUpper(symbol))
+		}
+	}
+
+	return PriceProviderConfig{
+		Provider: strings.ToLower(GetEnv("PRICE_PROVIDER", "random")),
+		FilePath: GetEnv("PRICE_FILE", ""),
+		FeedURL:  GetEnv("PRICE_FEED_URL", ""),
+		APIKey:   GetEnv("PRICE_FEED_API_KEY", ""),
+		Timeout:  timeout,
+		Symbols:  symbols,
+	}
+}
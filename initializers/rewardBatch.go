@@ -0,0 +1,21 @@
+package initializers
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const DefaultRewardBatchMaxItems = 1000
+
+type RewardBatchConfig struct {
+	MaxItems int
+}
+
+func LoadRewardBatchConfig() (RewardBatchConfig, error) {
+	raw := GetEnv("REWARD_BATCH_MAX_ITEMS", strconv.Itoa(DefaultRewardBatchMaxItems))
+	maxItems, err := strconv.Atoi(raw)
+	if err != nil || maxItems <= 0 {
+		return RewardBatchConfig{}, fmt.Errorf("REWARD_BATCH_MAX_ITEMS: expected a positive integer, got %q", raw)
+	}
+	return RewardBatchConfig{MaxItems: maxItems}, nil
+}
@@ -0,0 +1,75 @@
+package initializers
+
+import (
+	"assignment/decimal"
+	"fmt"
+	"strings"
+)
+
+// RewardLimitsConfig holds the caps on reward value in INR. A zero cap means
+// no limit.
+type RewardLimitsConfig struct {
+	MaxRewardValue       decimal.Decimal
+	UserDailyLimit       decimal.Decimal
+	UserMonthlyLimit     decimal.Decimal
+	CampaignDailyBudget  decimal.Decimal
+	CampaignDailyBudgets map[string]decimal.Decimal
+}
+
+func LoadRewardLimitsConfig() (RewardLimitsConfig, error) {
+	config := RewardLimitsConfig{CampaignDailyBudgets: make(map[string]decimal.Decimal)}
+
+	caps := []struct {
+		name  string
+		value *decimal.Decimal
+	}{
+		{"REWARD_MAX_VALUE_INR", &config.MaxRewardValue},
+		{"REWARD_USER_DAILY_LIMIT_INR", &config.UserDailyLimit},
+		{"REWARD_USER_MONTHLY_LIMIT_INR", &config.UserMonthlyLimit},
+		{"REWARD_CAMPAIGN_DAILY_BUDGET_INR", &config.CampaignDailyBudget},
+	}
+	for _, limit := range caps {
+		value, err := parseLimit(limit.name, GetEnv(limit.name, "0"))
+		if err != nil {
+			return config, err
+		}
+		*limit.value = value
+	}
+
+	for _, pair := range strings.Split(GetEnv("REWARD_CAMPAIGN_DAILY_BUDGETS", ""), ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		campaignID, amount, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(campaignID) == "" {
+			return config, fmt.Errorf("REWARD_CAMPAIGN_DAILY_BUDGETS: expected campaign=amount, got %q", pair)
+		}
+		value, err := parseLimit("REWARD_CAMPAIGN_DAILY_BUDGETS", amount)
+		if err != nil {
+			return config, err
+		}
+		config.CampaignDailyBudgets[strings.TrimSpace(campaignID)] = value
+	}
+
+	return config, nil
+}
+
+// CampaignBudget returns the daily budget for a campaign, falling back to the
+// default budget.
+func (c RewardLimitsConfig) CampaignBudget(campaignID string) decimal.Decimal {
+	if budget, ok := c.CampaignDailyBudgets[campaignID]; ok {
+		return budget
+	}
+	return c.CampaignDailyBudget
+}
+
+func parseLimit(name, raw string) (decimal.Decimal, error) {
+	value, err := decimal.NewFromString(strings.TrimSpace(raw))
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("%s: invalid amount %q", name, raw)
+	}
+	if value.IsNegative() {
+		return decimal.Zero, fmt.Errorf("%s: amount cannot be negative", name)
+	}
+	return value, nil
+}
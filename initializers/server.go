@@ -0,0 +1,24 @@
+package initializers
+
+import (
+	"fmt"
+	"time"
+)
+
+type ServerConfig struct {
+	Port            string
+	ShutdownTimeout time.Duration
+}
+
+func LoadServerConfig() (ServerConfig, error) {
+	config := ServerConfig{Port: GetEnv("PORT", "8080")}
+
+	timeout := GetEnv("SHUTDOWN_TIMEOUT", "15s")
+	var err error
+	config.ShutdownTimeout, err = time.ParseDuration(timeout)
+	if err != nil || config.ShutdownTimeout <= 0 {
+		return config, fmt.Errorf("SHUTDOWN_TIMEOUT: expected a positive duration, got %q", timeout)
+	}
+
+	return config, nil
+}
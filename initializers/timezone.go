@@ -0,0 +1,20 @@
+package initializers
+
+import (
+	"fmt"
+	"time"
+	_ "time/tzdata"
+)
+
+const DefaultBusinessTimezone = "Asia/Kolkata"
+
+// LoadBusinessLocation returns the zone that day and month boundaries are
+// drawn in, independent of where the server runs.
+func LoadBusinessLocation() (*time.Location, error) {
+	name := GetEnv("BUSINESS_TIMEZONE", DefaultBusinessTimezone)
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("BUSINESS_TIMEZONE: unknown timezone %q", name)
+	}
+	return location, nil
+}
@@ -0,0 +1,46 @@
+package initializers
+
+import (
+	"fmt"
+	"time"
+)
+
+// TradingCalendarConfig holds the exchange session, as offsets from local
+// midnight in the business timezone, and the scheduler's refresh interval.
+type TradingCalendarConfig struct {
+	HolidaysFile    string
+	SessionOpen     time.Duration
+	SessionClose    time.Duration
+	RefreshInterval time.Duration
+}
+
+func LoadTradingCalendarConfig() (TradingCalendarConfig, error) {
+	config := TradingCalendarConfig{HolidaysFile: GetEnv("MARKET_HOLIDAYS_FILE", "")}
+
+	var err error
+	if config.SessionOpen, err = parseClockTime("MARKET_OPEN", GetEnv("MARKET_OPEN", "09:15")); err != nil {
+		return config, err
+	}
+	if config.SessionClose, err = parseClockTime("MARKET_CLOSE", GetEnv("MARKET_CLOSE", "15:30")); err != nil {
+		return config, err
+	}
+	if config.SessionClose <= config.SessionOpen {
+		return config, fmt.Errorf("MARKET_CLOSE must be after MARKET_OPEN")
+	}
+
+	interval := GetEnv("PRICE_REFRESH_INTERVAL", "1m")
+	config.RefreshInterval, err = time.ParseDuration(interval)
+	if err != nil || config.RefreshInterval < time.Second {
+		return config, fmt.Errorf("PRICE_REFRESH_INTERVAL: expected a duration of at least 1s, got %q", interval)
+	}
+
+	return config, nil
+}
+
+func parseClockTime(name, value string) (time.Duration, error) {
+	clock, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%s: expected HH:MM, got %q", name, value)
+	}
+	return time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute, nil
+}
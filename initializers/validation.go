@@ -0,0 +1,35 @@
+package initializers
+
+import (
+	"assignment/decimal"
+	"assignment/models"
+	"reflect"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// quantityPlaces matches the numeric(18,6) quantity columns. A request with
+// more places would be priced on a quantity that is rounded when stored.
+const quantityPlaces = 6
+
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+			if value, ok := field.Interface().(decimal.Decimal); ok {
+				return value.Float64()
+			}
+			return nil
+		}, decimal.Decimal{})
+
+		v.RegisterStructValidation(validateQuantityPlaces,
+			models.RewardRequest{}, models.RedemptionRequest{}, models.DematTransferRequest{})
+	}
+}
+
+func validateQuantityPlaces(sl validator.StructLevel) {
+	quantity, ok := sl.Current().FieldByName("Quantity").Interface().(decimal.Decimal)
+	if ok && !quantity.Equal(quantity.RoundBank(quantityPlaces)) {
+		sl.ReportError(quantity, "Quantity", "quantity", "max_places", "6")
+	}
+}
@@ -0,0 +1,63 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Serve runs server on listener until ctx is cancelled, then stops accepting
+// connections and gives in-flight requests up to drainTimeout to finish.
+// Requests still running after that have their context cancelled, so their
+// database transactions roll back, and Serve waits for them to return.
+func Serve(ctx context.Context, server *http.Server, listener net.Listener, drainTimeout time.Duration) error {
+	requests, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+	server.BaseContext = func(net.Listener) context.Context { return requests }
+
+	// A connection's handlers run on its goroutine, which reports the
+	// connection closed only after they return. StateNew is reported by
+	// server.Serve itself, so every Add happens before it returns and before
+	// the Wait below.
+	var conns sync.WaitGroup
+	connState := server.ConnState
+	server.ConnState = func(conn net.Conn, state http.ConnState) {
+		switch state {
+		case http.StateNew:
+			conns.Add(1)
+		case http.StateClosed, http.StateHijacked:
+			conns.Done()
+		}
+		if connState != nil {
+			connState(conn, state)
+		}
+	}
+
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(listener) }()
+
+	select {
+	case err := <-served:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancelDrain()
+
+	err := server.Shutdown(drainCtx)
+	if err != nil {
+		cancelRequests()
+		server.Close()
+	}
+	<-served
+	conns.Wait()
+
+	return err
+}
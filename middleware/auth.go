@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"assignment/initializers"
+	"assignment/models"
+	"assignment/services"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const claimsKey = "auth_claims"
+
+// Auth checks bearer tokens against config and logs rejected requests.
+type Auth struct {
+	config initializers.AuthConfig
+	clock  services.Clock
+	log    *logrus.Logger
+}
+
+// NewAuth returns middleware that checks token expiry against clock.
+func NewAuth(config initializers.AuthConfig, clock services.Clock, log *logrus.Logger) *Auth {
+	return &Auth{config: config, clock: clock, log: log}
+}
+
+// Authenticate rejects requests without a valid bearer token and stores the
+// token's claims on the context.
+func (a *Auth) Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scheme, token, found := strings.Cut(c.GetHeader("Authorization"), " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+			abortUnauthorized(c, "Missing bearer token")
+			return
+		}
+
+		claims, err := services.ParseToken(a.config, strings.TrimSpace(token), a.clock.Now())
+		if err != nil {
+			a.log.WithError(err).WithField("path", c.FullPath()).Warn("Rejected bearer token")
+			if errors.Is(err, services.ErrTokenExpired) {
+				abortUnauthorized(c, "Token has expired")
+			} else {
+				abortUnauthorized(c, "Invalid token")
+			}
+			return
+		}
+
+		c.Set(claimsKey, claims)
+		c.Next()
+	}
+}
+
+// RequireRoles lets through only callers holding one of roles.
+func (a *Auth) RequireRoles(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := Claims(c)
+		if claims == nil {
+			abortUnauthorized(c, "Missing bearer token")
+			return
+		}
+
+		if !hasRole(claims, roles) {
+			a.abortForbidden(c, claims, fmt.Sprintf("Role '%s' cannot access this resource", claims.Role))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireSelfOrRoles lets through callers holding one of roles, and users
+// whose subject matches the userParam path parameter.
+func (a *Auth) RequireSelfOrRoles(userParam string, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := Claims(c)
+		if claims == nil {
+			abortUnauthorized(c, "Missing bearer token")
+			return
+		}
+
+		if hasRole(claims, roles) {
+			c.Next()
+			return
+		}
+		if claims.Role == models.RoleUser && claims.Subject == c.Param(userParam) {
+			c.Next()
+			return
+		}
+
+		if claims.Role == models.RoleUser {
+			a.abortForbidden(c, claims, "Users can only access their own data")
+		} else {
+			a.abortForbidden(c, claims, fmt.Sprintf("Role '%s' cannot access this resource", claims.Role))
+		}
+	}
+}
+
+// Claims returns the authenticated caller's claims, or nil outside
+// Authenticate.
+func Claims(c *gin.Context) *models.Claims {
+	value, ok := c.Get(claimsKey)
+	if !ok {
+		return nil
+	}
+	claims, _ := value.(*models.Claims)
+	return claims
+}
+
+func hasRole(claims *models.Claims, roles []string) bool {
+	for _, role := range roles {
+		if claims.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+func abortUnauthorized(c *gin.Context, details string) {
+	c.Header("WWW-Authenticate", `Bearer realm="api"`)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+		"error":   "Unauthorized",
+		"details": details,
+	})
+}
+
+func (a *Auth) abortForbidden(c *gin.Context, claims *models.Claims, details string) {
+	a.log.WithFields(logrus.Fields{
+		"subject": claims.Subject,
+		"role":    claims.Role,
+		"path":    c.FullPath(),
+	}).Warn("Forbidden request")
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+		"error":   "Forbidden",
+		"details": details,
+	})
+}
@@ -0,0 +1,48 @@
+package models
+
+import "encoding/json"
+
+const (
+	RoleService = "service"
+	RoleUser    = "user"
+	RoleAdmin   = "admin"
+)
+
+// Claims are the JWT claims the API reads. For the user role Subject is the
+// user ID.
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Role      string   `json:"role"`
+	Issuer    string   `json:"iss,omitempty"`
+	Audience  Audience `json:"aud,omitempty"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+}
+
+// Audience accepts the aud claim as either a string or an array of strings.
+type Audience []string
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+func (a Audience) Contains(audience string) bool {
+	for _, value := range a {
+		if value == audience {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"assignment/decimal"
+	"time"
+)
+
+type Campaign struct {
+	ID              string          `gorm:"type:varchar(64);primaryKey"`
+	Name            string          `gorm:"type:varchar(255);not null"`
+	Description     string          `gorm:"type:text"`
+	Budget          decimal.Decimal `gorm:"type:numeric(18,2);not null;default:0"`
+	DailyBudget     decimal.Decimal `gorm:"type:numeric(18,2);not null;default:0"`
+	StartDate       time.Time       `gorm:"not null"`
+	EndDate         *time.Time
+	EligibleSymbols []string  `gorm:"type:text;serializer:json"`
+	Status          string    `gorm:"type:varchar(20);not null;default:ACTIVE;index"`
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime"`
+}
+
+func (Campaign) TableName() string {
+	return "campaigns"
+}
+
+const (
+	CampaignStatusActive = "ACTIVE"
+	CampaignStatusPaused = "PAUSED"
+	CampaignStatusEnded  = "ENDED"
+)
+
+type CampaignRequest struct {
+	ID              string          `json:"id" binding:"required,max=64"`
+	Name            string          `json:"name" binding:"required"`
+	Description     string          `json:"description"`
+	Budget          decimal.Decimal `json:"budget" binding:"gte=0"`
+	DailyBudget     decimal.Decimal `json:"daily_budget" binding:"gte=0"`
+	StartDate       time.Time       `json:"start_date" binding:"required"`
+	EndDate         *time.Time      `json:"end_date"`
+	EligibleSymbols []string        `json:"eligible_symbols"`
+	Status          string          `json:"status" binding:"omitempty,oneof=ACTIVE PAUSED ENDED"`
+}
+
+type CampaignSpendQuery struct {
+	From time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To   time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// CampaignSpend is the net cost of a campaign's rewards from the ledger, with
+// reversed rewards netted out.
+type CampaignSpend struct {
+	CampaignID      string
+	Name            string
+	Status          string
+	Budget          decimal.Decimal
+	RemainingBudget *decimal.Decimal
+	ActiveRewards   int64
+	ReversedRewards int64
+	StockCost       decimal.Decimal
+	Brokerage       decimal.Decimal
+	STT             decimal.Decimal
+	ExchangeCharges decimal.Decimal
+	SEBIFees        decimal.Decimal
+	StampDuty       decimal.Decimal
+	GST             decimal.Decimal
+	TotalCharges    decimal.Decimal
+	TotalCost       decimal.Decimal
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"assignment/decimal"
+	"time"
+)
+
+type CorporateAction struct {
+	ID               uint            `gorm:"primaryKey;autoIncrement"`
+	ActionType       string          `gorm:"type:varchar(20);not null"`
+	StockSymbol      string          `gorm:"type:varchar(50);not null;index"`
+	NewSymbol        string          `gorm:"type:varchar(50)"`
+	RatioNumerator   decimal.Decimal `gorm:"type:numeric(18,6);not null;default:1"`
+	RatioDenominator decimal.Decimal `gorm:"type:numeric(18,6);not null;default:1"`
+	ExDate           time.Time       `gorm:"not null;index"`
+	Status           string          `gorm:"type:varchar(20);not null;default:PENDING;index"`
+	LotsAdjusted     int             `gorm:"not null;default:0"`
+	Description      string          `gorm:"type:text"`
+	AppliedAt        *time.Time
+	CreatedAt        time.Time `gorm:"autoCreateTime"`
+}
+
+func (CorporateAction) TableName() string {
+	return "corporate_actions"
+}
+
+const (
+	CorporateActionSplit        = "SPLIT"
+	CorporateActionBonus        = "BONUS"
+	CorporateActionMerger       = "MERGER"
+	CorporateActionSymbolChange = "SYMBOL_CHANGE"
+)
+
+const (
+	CorporateActionStatusPending = "PENDING"
+	CorporateActionStatusApplied = "APPLIED"
+)
+
+type CorporateActionRequest struct {
+	ActionType       string          `json:"action_type" binding:"required,oneof=SPLIT BONUS MERGER SYMBOL_CHANGE"`
+	StockSymbol      string          `json:"stock_symbol" binding:"required"`
+	NewSymbol        string          `json:"new_symbol"`
+	RatioNumerator   decimal.Decimal `json:"ratio_numerator" binding:"gte=0"`
+	RatioDenominator decimal.Decimal `json:"ratio_denominator" binding:"gte=0"`
+	ExDate           time.Time       `json:"ex_date" binding:"required"`
+	Description      string          `json:"description"`
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"assignment/decimal"
+	"time"
+)
+
+type DematTransfer struct {
+	ID                      string          `gorm:"type:varchar(64);primaryKey"`
+	UserID                  string          `gorm:"type:varchar(255);not null;index"`
+	StockSymbol             string          `gorm:"type:varchar(50);not null"`
+	ISIN                    string          `gorm:"type:varchar(12);not null"`
+	Quantity                decimal.Decimal `gorm:"type:numeric(18,6);not null"`
+	CostBasis               decimal.Decimal `gorm:"type:numeric(18,4);not null"`
+	DepositoryParticipantID string          `gorm:"type:varchar(16);not null"`
+	BeneficiaryAccount      string          `gorm:"type:varchar(16);not null"`
+	Status                  string          `gorm:"type:varchar(20);not null;default:REQUESTED;index"`
+	DepositoryReference     string          `gorm:"type:varchar(64);index"`
+	FailureReason           string          `gorm:"type:text"`
+	RequestedAt             time.Time       `gorm:"not null"`
+	ApprovedAt              *time.Time
+	SubmittedAt             *time.Time
+	CompletedAt             *time.Time
+	FailedAt                *time.Time
+	Lots                    []DematTransferLot `gorm:"foreignKey:TransferID"`
+	CreatedAt               time.Time          `gorm:"autoCreateTime"`
+	UpdatedAt               time.Time          `gorm:"autoUpdateTime"`
+}
+
+func (DematTransfer) TableName() string {
+	return "demat_transfers"
+}
+
+const (
+	TransferStatusRequested = "REQUESTED"
+	TransferStatusApproved  = "APPROVED"
+	TransferStatusSubmitted = "SUBMITTED"
+	TransferStatusCompleted = "COMPLETED"
+	TransferStatusFailed    = "FAILED"
+)
+
+// TransferPendingStatuses are the statuses in which a transfer's shares are
+// locked.
+var TransferPendingStatuses = []string{TransferStatusRequested, TransferStatusApproved, TransferStatusSubmitted}
+
+// DematTransferLot is the part of a reward lot locked by a transfer.
+type DematTransferLot struct {
+	ID         uint            `gorm:"primaryKey;autoIncrement"`
+	TransferID string          `gorm:"type:varchar(64);not null;index"`
+	RewardID   string          `gorm:"type:varchar(255);not null;index"`
+	Quantity   decimal.Decimal `gorm:"type:numeric(18,6);not null"`
+	CostBasis  decimal.Decimal `gorm:"type:numeric(18,4);not null"`
+}
+
+func (DematTransferLot) TableName() string {
+	return "demat_transfer_lots"
+}
+
+type DematTransferRequest struct {
+	ID                      string          `json:"id" binding:"required,max=60"`
+	UserID                  string          `json:"user_id" binding:"required"`
+	StockSymbol             string          `json:"stock_symbol" binding:"required"`
+	Quantity                decimal.Decimal `json:"quantity" binding:"gt=0"`
+	DepositoryParticipantID string          `json:"depository_participant_id" binding:"required,max=16"`
+	BeneficiaryAccount      string          `json:"beneficiary_account" binding:"required,max=16"`
+}
+
+type RejectTransferRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+type DematTransferResponse struct {
+	Success  bool
+	Message  string
+	Transfer DematTransfer
+}
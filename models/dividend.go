@@ -0,0 +1,88 @@
+package models
+
+import (
+	"assignment/decimal"
+	"time"
+)
+
+type DividendDeclaration struct {
+	ID                uint            `gorm:"primaryKey;autoIncrement"`
+	StockSymbol       string          `gorm:"type:varchar(50);not null;index"`
+	ExDate            time.Time       `gorm:"not null"`
+	RecordDate        time.Time       `gorm:"not null;index"`
+	PaymentDate       *time.Time      `gorm:"index"`
+	AmountPerShare    decimal.Decimal `gorm:"type:numeric(18,4);not null"`
+	Status            string          `gorm:"type:varchar(20);not null;default:DECLARED;index"`
+	EntitlementsCount int             `gorm:"not null;default:0"`
+	TotalAmount       decimal.Decimal `gorm:"type:numeric(18,4);not null;default:0"`
+	Description       string          `gorm:"type:text"`
+	AccruedAt         *time.Time
+	PaidAt            *time.Time
+	CreatedAt         time.Time `gorm:"autoCreateTime"`
+}
+
+func (DividendDeclaration) TableName() string {
+	return "dividend_declarations"
+}
+
+const (
+	DividendStatusDeclared = "DECLARED"
+	DividendStatusAccrued  = "ACCRUED"
+	DividendStatusPaid     = "PAID"
+
+	// DividendStatusCancelled marks an entitlement whose reward was reversed
+	// before the dividend was paid.
+	DividendStatusCancelled = "CANCELLED"
+)
+
+type DividendEntitlement struct {
+	ID             uint            `gorm:"primaryKey;autoIncrement"`
+	DividendID     uint            `gorm:"not null;uniqueIndex:idx_dividend_entitlements_dividend_reward,priority:1"`
+	RewardID       string          `gorm:"type:varchar(255);not null;uniqueIndex:idx_dividend_entitlements_dividend_reward,priority:2"`
+	UserID         string          `gorm:"type:varchar(255);not null;index"`
+	StockSymbol    string          `gorm:"type:varchar(50);not null"`
+	Quantity       decimal.Decimal `gorm:"type:numeric(18,6);not null"`
+	AmountPerShare decimal.Decimal `gorm:"type:numeric(18,4);not null"`
+	Amount         decimal.Decimal `gorm:"type:numeric(18,4);not null"`
+	Status         string          `gorm:"type:varchar(20);not null;index"`
+	PaidAt         *time.Time
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+}
+
+func (DividendEntitlement) TableName() string {
+	return "dividend_entitlements"
+}
+
+type DividendDeclarationRequest struct {
+	StockSymbol    string          `json:"stock_symbol" binding:"required"`
+	ExDate         time.Time       `json:"ex_date" binding:"required"`
+	RecordDate     time.Time       `json:"record_date" binding:"required"`
+	PaymentDate    *time.Time      `json:"payment_date"`
+	AmountPerShare decimal.Decimal `json:"amount_per_share" binding:"gt=0"`
+	Description    string          `json:"description"`
+}
+
+type UserDividend struct {
+	DividendID     uint
+	StockSymbol    string
+	ExDate         time.Time
+	RecordDate     time.Time
+	PaymentDate    *time.Time
+	AmountPerShare decimal.Decimal
+	Quantity       decimal.Decimal
+	Amount         decimal.Decimal
+	Status         string
+	PaidAt         *time.Time
+}
+
+type UserDividendsResponse struct {
+	UserID       string
+	TotalAccrued decimal.Decimal
+	TotalPaid    decimal.Decimal
+	Dividends    []UserDividend
+}
+
+type DividendRunResult struct {
+	Accrued []DividendDeclaration
+	Paid    []DividendDeclaration
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"assignment/decimal"
+	"time"
+)
+
+type FeeSchedule struct {
+	ID              uint            `gorm:"primaryKey;autoIncrement"`
+	Name            string          `gorm:"type:varchar(255);not null"`
+	EffectiveFrom   time.Time       `gorm:"not null;index"`
+	Exchange        string          `gorm:"type:varchar(20);not null"`
+	InstrumentType  string          `gorm:"type:varchar(20);not null"`
+	BrokerageRate   decimal.Decimal `gorm:"type:numeric(12,8);not null"`
+	BrokerageMin    decimal.Decimal `gorm:"type:numeric(18,4);not null;default:0"`
+	BrokerageMax    decimal.Decimal `gorm:"type:numeric(18,4);not null;default:0"`
+	STTRate         decimal.Decimal `gorm:"type:numeric(12,8);not null"`
+	GSTRate         decimal.Decimal `gorm:"type:numeric(12,8);not null"`
+	StampDutyRate   decimal.Decimal `gorm:"type:numeric(12,8);not null;default:0"`
+	ExchangeTxnRate decimal.Decimal `gorm:"type:numeric(12,8);not null;default:0"`
+	SEBIFeeRate     decimal.Decimal `gorm:"type:numeric(12,8);not null;default:0"`
+	CreatedAt       time.Time       `gorm:"autoCreateTime"`
+}
+
+func (FeeSchedule) TableName() string {
+	return "fee_schedules"
+}
+
+type FeeScheduleRequest struct {
+	Name            string          `json:"name" binding:"required"`
+	EffectiveFrom   time.Time       `json:"effective_from" binding:"required"`
+	Exchange        string          `json:"exchange" binding:"omitempty,oneof=NSE BSE"`
+	InstrumentType  string          `json:"instrument_type" binding:"omitempty,oneof=EQUITY ETF"`
+	BrokerageRate   decimal.Decimal `json:"brokerage_rate" binding:"gte=0,lt=1"`
+	BrokerageMin    decimal.Decimal `json:"brokerage_min" binding:"gte=0"`
+	BrokerageMax    decimal.Decimal `json:"brokerage_max" binding:"gte=0"`
+	STTRate         decimal.Decimal `json:"stt_rate" binding:"gte=0,lt=1"`
+	GSTRate         decimal.Decimal `json:"gst_rate" binding:"gte=0,lt=1"`
+	StampDutyRate   decimal.Decimal `json:"stamp_duty_rate" binding:"gte=0,lt=1"`
+	ExchangeTxnRate decimal.Decimal `json:"exchange_txn_rate" binding:"gte=0,lt=1"`
+	SEBIFeeRate     decimal.Decimal `json:"sebi_fee_rate" binding:"gte=0,lt=1"`
+}
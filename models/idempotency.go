@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type RewardIdempotencyKey struct {
+	RewardID     string    `gorm:"type:varchar(255);primaryKey"`
+	RequestHash  string    `gorm:"type:varchar(64);not null"`
+	RequestBody  string    `gorm:"type:text;not null"`
+	ResponseBody string    `gorm:"type:text;not null"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+}
+
+func (RewardIdempotencyKey) TableName() string {
+	return "reward_idempotency_keys"
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+)
+
+type Instrument struct {
+	Symbol         string    `gorm:"type:varchar(50);primaryKey"`
+	ISIN           string    `gorm:"type:varchar(12);not null;uniqueIndex"`
+	Exchange       string    `gorm:"type:varchar(20);not null"`
+	InstrumentType string    `gorm:"type:varchar(20);not null;default:EQUITY"`
+	Name           string    `gorm:"type:varchar(255);not null"`
+	LotSize        int       `gorm:"not null;default:1"`
+	Status         string    `gorm:"type:varchar(20);not null;default:ACTIVE;index"`
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+}
+
+func (Instrument) TableName() string {
+	return "instruments"
+}
+
+const (
+	InstrumentStatusActive   = "ACTIVE"
+	InstrumentStatusDelisted = "DELISTED"
+)
+
+const (
+	InstrumentTypeEquity = "EQUITY"
+	InstrumentTypeETF    = "ETF"
+)
+
+type InstrumentRequest struct {
+	Symbol         string `json:"symbol" binding:"required"`
+	ISIN           string `json:"isin" binding:"required,len=12,alphanum"`
+	Exchange       string `json:"exchange" binding:"required,oneof=NSE BSE"`
+	InstrumentType string `json:"instrument_type" binding:"omitempty,oneof=EQUITY ETF"`
+	Name           string `json:"name" binding:"required"`
+	LotSize        int    `json:"lot_size" binding:"omitempty,gt=0"`
+	Status         string `json:"status" binding:"omitempty,oneof=ACTIVE DELISTED"`
+}
@@ -1,20 +1,24 @@
 package models
 
 import (
+	"assignment/decimal"
 	"time"
 )
 
 type LedgerEntry struct {
-	ID           uint        `gorm:"primaryKey;autoIncrement"`
-	RewardID     string      `gorm:"type:varchar(255);not null;index"`
-	Reward       StockReward `gorm:"foreignKey:RewardID;references:ID;constraint:OnDelete:CASCADE"`
-	AccountType  string      `gorm:"type:varchar(50);not null"`
-	StockSymbol  *string     `gorm:"type:varchar(50)"`
-	DebitAmount  float64     `gorm:"type:numeric(18,4);not null;default:0"`
-	CreditAmount float64     `gorm:"type:numeric(18,4);not null;default:0"`
-	Quantity     *float64    `gorm:"type:numeric(18,6)"`
-	Description  string      `gorm:"type:text"`
-	CreatedAt    time.Time   `gorm:"autoCreateTime"`
+	ID           uint             `gorm:"primaryKey;autoIncrement"`
+	RewardID     string           `gorm:"type:varchar(255);not null;index"`
+	Reward       StockReward      `gorm:"foreignKey:RewardID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+	EntryType    string           `gorm:"type:varchar(20);not null;default:REWARD;index"`
+	AccountType  string           `gorm:"type:varchar(50);not null"`
+	StockSymbol  *string          `gorm:"type:varchar(50)"`
+	DebitAmount  decimal.Decimal  `gorm:"type:numeric(18,4);not null;default:0"`
+	CreditAmount decimal.Decimal  `gorm:"type:numeric(18,4);not null;default:0"`
+	Quantity     *decimal.Decimal `gorm:"type:numeric(18,6)"`
+	Description  string           `gorm:"type:text"`
+	ReferenceID  string           `gorm:"type:varchar(64);index"`
+	EffectiveAt  time.Time        `gorm:"index"`
+	CreatedAt    time.Time        `gorm:"autoCreateTime"`
 }
 
 func (LedgerEntry) TableName() string {
@@ -22,9 +26,97 @@ func (LedgerEntry) TableName() string {
 }
 
 const (
-	AccountTypeStockAsset   = "STOCK_ASSET"
-	AccountTypeCashAccount  = "CASH_ACCOUNT"
-	AccountTypeBrokerageExp = "BROKERAGE_EXPENSE"
-	AccountTypeSTTExp       = "STT_EXPENSE"
-	AccountTypeGSTExp       = "GST_EXPENSE"
+	AccountTypeStockAsset         = "STOCK_ASSET"
+	AccountTypeCashAccount        = "CASH_ACCOUNT"
+	AccountTypeDividendReceivable = "DIVIDEND_RECEIVABLE"
+	AccountTypeDividendPayable    = "DIVIDEND_PAYABLE"
+	AccountTypeRedemptionPayout   = "REDEMPTION_PAYOUT"
+	AccountTypeRealizedGainLoss   = "REALIZED_GAIN_LOSS"
+	AccountTypeTransferredOut     = "SHARES_TRANSFERRED_OUT"
+	AccountTypeBrokerageExp       = "BROKERAGE_EXPENSE"
+	AccountTypeSTTExp             = "STT_EXPENSE"
+	AccountTypeGSTExp             = "GST_EXPENSE"
+	AccountTypeStampDutyExp       = "STAMP_DUTY_EXPENSE"
+	AccountTypeExchangeExp        = "EXCHANGE_CHARGES_EXPENSE"
+	AccountTypeSEBIFeesExp        = "SEBI_FEES_EXPENSE"
 )
+
+const (
+	EntryTypeReward          = "REWARD"
+	EntryTypeReversal        = "REVERSAL"
+	EntryTypeCorporateAction = "CORPORATE_ACTION"
+	EntryTypeDividend        = "DIVIDEND"
+	EntryTypeRedemption      = "REDEMPTION"
+	EntryTypeTransfer        = "TRANSFER"
+)
+
+type LedgerEntryQuery struct {
+	RewardID    string    `form:"reward_id"`
+	AccountType string    `form:"account_type"`
+	StockSymbol string    `form:"stock_symbol"`
+	From        time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To          time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Page        int       `form:"page" binding:"omitempty,gte=1"`
+	PageSize    int       `form:"page_size" binding:"omitempty,gte=1,lte=500"`
+}
+
+type LedgerEntriesResponse struct {
+	Entries    []LedgerEntry
+	Page       int
+	PageSize   int
+	TotalCount int64
+}
+
+type AccountBalance struct {
+	AccountType string
+	TotalDebit  decimal.Decimal
+	TotalCredit decimal.Decimal
+	Balance     decimal.Decimal
+}
+
+type AccountBalancesResponse struct {
+	AsOf     time.Time
+	Balances []AccountBalance
+}
+
+type RewardImbalance struct {
+	RewardID    string
+	TotalDebit  decimal.Decimal
+	TotalCredit decimal.Decimal
+	Difference  decimal.Decimal
+}
+
+type TrialBalanceResponse struct {
+	AsOf              time.Time
+	TotalDebit        decimal.Decimal
+	TotalCredit       decimal.Decimal
+	Balanced          bool
+	UnbalancedRewards []RewardImbalance
+}
+
+// HoldingMovement is a signed change in a reward lot's STOCK_ASSET quantity.
+type HoldingMovement struct {
+	RewardID           string
+	StockSymbol        string
+	EntryType          string
+	Quantity           decimal.Decimal
+	DebitAmount        decimal.Decimal
+	CreditAmount       decimal.Decimal
+	EffectiveAt        time.Time
+	StockPriceAtReward decimal.Decimal
+}
+
+type Holding struct {
+	StockSymbol string
+	Quantity    decimal.Decimal
+}
+
+type Position struct {
+	StockSymbol     string
+	Quantity        decimal.Decimal
+	LockedQuantity  decimal.Decimal
+	BookValue       decimal.Decimal
+	Charges         decimal.Decimal
+	OpeningQuantity decimal.Decimal
+	CarriedQuantity decimal.Decimal
+}
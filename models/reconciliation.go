@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+type ReconciliationRun struct {
+	ID                    uint                    `gorm:"primaryKey;autoIncrement"`
+	StartedAt             time.Time               `gorm:"not null;index"`
+	CompletedAt           time.Time               `gorm:"not null"`
+	Status                string                  `gorm:"type:varchar(20);not null"`
+	RewardsScanned        int                     `gorm:"not null"`
+	UnbalancedCount       int                     `gorm:"not null"`
+	MissingEntriesCount   int                     `gorm:"not null"`
+	QuantityMismatchCount int                     `gorm:"not null"`
+	Findings              []ReconciliationFinding `gorm:"type:text;serializer:json"`
+	CreatedAt             time.Time               `gorm:"autoCreateTime"`
+}
+
+func (ReconciliationRun) TableName() string {
+	return "reconciliation_runs"
+}
+
+type ReconciliationFinding struct {
+	Type     string
+	RewardID string
+	Message  string
+}
+
+const (
+	ReconciliationStatusClean       = "CLEAN"
+	ReconciliationStatusIssuesFound = "ISSUES_FOUND"
+)
+
+const (
+	FindingUnbalancedJournal = "UNBALANCED_JOURNAL"
+	FindingMissingEntries    = "MISSING_LEDGER_ENTRIES"
+	FindingQuantityMismatch  = "QUANTITY_MISMATCH"
+)
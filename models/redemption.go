@@ -0,0 +1,58 @@
+package models
+
+import (
+	"assignment/decimal"
+	"time"
+)
+
+type Redemption struct {
+	ID              string          `gorm:"type:varchar(64);primaryKey"`
+	UserID          string          `gorm:"type:varchar(255);not null;index"`
+	StockSymbol     string          `gorm:"type:varchar(50);not null"`
+	Quantity        decimal.Decimal `gorm:"type:numeric(18,6);not null"`
+	Price           decimal.Decimal `gorm:"type:numeric(18,4);not null"`
+	GrossAmount     decimal.Decimal `gorm:"type:numeric(18,4);not null"`
+	CostBasis       decimal.Decimal `gorm:"type:numeric(18,4);not null"`
+	RealizedGain    decimal.Decimal `gorm:"type:numeric(18,4);not null"`
+	Brokerage       decimal.Decimal `gorm:"type:numeric(18,4);not null;default:0"`
+	STT             decimal.Decimal `gorm:"type:numeric(18,4);not null;default:0"`
+	ExchangeCharges decimal.Decimal `gorm:"type:numeric(18,4);not null;default:0"`
+	SEBIFees        decimal.Decimal `gorm:"type:numeric(18,4);not null;default:0"`
+	GST             decimal.Decimal `gorm:"type:numeric(18,4);not null;default:0"`
+	TotalCharges    decimal.Decimal `gorm:"type:numeric(18,4);not null"`
+	NetAmount       decimal.Decimal `gorm:"type:numeric(18,4);not null"`
+	FeeScheduleID   uint            `gorm:"index"`
+	LotsRedeemed    int             `gorm:"not null"`
+	RedeemedAt      time.Time       `gorm:"not null;index"`
+	CreatedAt       time.Time       `gorm:"autoCreateTime"`
+}
+
+func (Redemption) TableName() string {
+	return "redemptions"
+}
+
+type RedemptionRequest struct {
+	ID          string          `json:"id" binding:"required,max=60"`
+	UserID      string          `json:"user_id" binding:"required"`
+	StockSymbol string          `json:"stock_symbol" binding:"required"`
+	Quantity    decimal.Decimal `json:"quantity" binding:"gt=0"`
+}
+
+type SaleCharges struct {
+	FeeScheduleID   uint
+	GrossAmount     decimal.Decimal
+	Brokerage       decimal.Decimal
+	STT             decimal.Decimal
+	ExchangeCharges decimal.Decimal
+	SEBIFees        decimal.Decimal
+	GST             decimal.Decimal
+	TotalCharges    decimal.Decimal
+	NetAmount       decimal.Decimal
+}
+
+type RedemptionResponse struct {
+	Success       bool
+	Message       string
+	Redemption    Redemption
+	LedgerEntries []LedgerEntry
+}
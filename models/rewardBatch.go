@@ -0,0 +1,34 @@
+package models
+
+const (
+	BatchStatusCreated   = "created"
+	BatchStatusDuplicate = "duplicate"
+	BatchStatusInvalid   = "invalid"
+	BatchStatusFailed    = "failed"
+	BatchStatusValid     = "valid"
+)
+
+type BatchRewardItem struct {
+	Index   int
+	Request RewardRequest
+}
+
+type BatchItemResult struct {
+	Index          int
+	ID             string
+	Status         string
+	Message        string
+	Code           string
+	Reward         *StockReward
+	CompanyCharges *CompanyCharges
+	Diff           map[string]FieldDiff
+}
+
+type BatchRewardResponse struct {
+	Total     int
+	Created   int
+	Duplicate int
+	Invalid   int
+	Failed    int
+	Results   []BatchItemResult
+}
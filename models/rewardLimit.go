@@ -0,0 +1,71 @@
+package models
+
+import (
+	"assignment/decimal"
+	"time"
+)
+
+// RewardLimitUsage is the INR value of active rewards counted against one
+// limit for one period. Rows are locked while a reward is recorded so
+// concurrent rewards cannot overshoot the limit.
+type RewardLimitUsage struct {
+	ID          uint            `gorm:"primaryKey;autoIncrement"`
+	Scope       string          `gorm:"type:varchar(20);not null;uniqueIndex:idx_reward_limit_usages_period,priority:1"`
+	ScopeKey    string          `gorm:"type:varchar(255);not null;uniqueIndex:idx_reward_limit_usages_period,priority:2"`
+	PeriodStart time.Time       `gorm:"not null;uniqueIndex:idx_reward_limit_usages_period,priority:3"`
+	Amount      decimal.Decimal `gorm:"type:numeric(18,4);not null;default:0"`
+	RewardCount int             `gorm:"not null;default:0"`
+	UpdatedAt   time.Time       `gorm:"autoUpdateTime"`
+}
+
+func (RewardLimitUsage) TableName() string {
+	return "reward_limit_usages"
+}
+
+// RewardLimitReservation records the amount a reward added to one usage row,
+// so reversing it gives back exactly what it took even if the caps change.
+type RewardLimitReservation struct {
+	ID        uint            `gorm:"primaryKey;autoIncrement"`
+	RewardID  string          `gorm:"type:varchar(255);not null;index"`
+	UsageID   uint            `gorm:"not null;index"`
+	Amount    decimal.Decimal `gorm:"type:numeric(18,4);not null"`
+	CreatedAt time.Time       `gorm:"autoCreateTime"`
+}
+
+func (RewardLimitReservation) TableName() string {
+	return "reward_limit_reservations"
+}
+
+const (
+	LimitScopeUserDaily     = "USER_DAILY"
+	LimitScopeUserMonthly   = "USER_MONTHLY"
+	LimitScopeCampaignDaily = "CAMPAIGN_DAILY"
+	LimitScopeCampaignTotal = "CAMPAIGN_TOTAL"
+)
+
+const (
+	LimitCodeRewardValue    = "REWARD_VALUE_LIMIT_EXCEEDED"
+	LimitCodeUserDaily      = "USER_DAILY_LIMIT_EXCEEDED"
+	LimitCodeUserMonthly    = "USER_MONTHLY_LIMIT_EXCEEDED"
+	LimitCodeCampaignBudget = "CAMPAIGN_DAILY_BUDGET_EXCEEDED"
+	LimitCodeCampaignTotal  = "CAMPAIGN_BUDGET_EXCEEDED"
+)
+
+// LimitUsage reports one limit for the current period. Limit and Remaining
+// are nil when the limit is not configured.
+type LimitUsage struct {
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Limit       *decimal.Decimal
+	Used        decimal.Decimal
+	Remaining   *decimal.Decimal
+	RewardCount int
+}
+
+type UserLimitsResponse struct {
+	UserID         string
+	MaxRewardValue *decimal.Decimal
+	Daily          LimitUsage
+	Monthly        LimitUsage
+	AsOf           time.Time
+}
@@ -1,31 +1,79 @@
 package models
 
 import (
+	"assignment/decimal"
 	"time"
 )
 
 type UserStockHolding struct {
-	StockSymbol   string
-	TotalQuantity float64
-	CurrentPrice  float64
-	CurrentValue  float64
+	StockSymbol           string
+	TotalQuantity         decimal.Decimal
+	LockedQuantity        decimal.Decimal
+	FreeQuantity          decimal.Decimal
+	CurrentPrice          decimal.Decimal
+	CurrentValue          decimal.Decimal
+	AverageCost           decimal.Decimal
+	TotalInvested         decimal.Decimal
+	UnrealizedGain        decimal.Decimal
+	UnrealizedGainPercent decimal.Decimal
+	PreviousClose         decimal.Decimal
+	DayChange             decimal.Decimal
+	DayChangePercent      decimal.Decimal
 }
 
 type PortfolioResponse struct {
-	UserID      string
-	Holdings    []UserStockHolding
-	TotalValue  float64
-	LastUpdated time.Time
+	UserID                string
+	Holdings              []UserStockHolding
+	TotalValue            decimal.Decimal
+	TotalInvested         decimal.Decimal
+	UnrealizedGain        decimal.Decimal
+	UnrealizedGainPercent decimal.Decimal
+	DayChange             decimal.Decimal
+	CostIncludesFees      bool
+	Market                MarketStatus
+	LastUpdated           time.Time
 }
 
 type StatsResponse struct {
 	UserID              string
-	TodayRewards        map[string]float64
-	CurrentPortfolioINR float64
-	TotalSharesRewarded float64
+	TodayRewards        map[string]decimal.Decimal
+	CurrentPortfolioINR decimal.Decimal
+	TotalSharesRewarded decimal.Decimal
+	Market              MarketStatus
 }
 
 type HistoricalINRResponse struct {
 	UserID      string
-	DailyValues map[string]float64
+	DailyValues map[string]decimal.Decimal
+}
+
+type StockPrice struct {
+	ID          uint            `gorm:"primaryKey;autoIncrement"`
+	StockSymbol string          `gorm:"type:varchar(50);not null;uniqueIndex:idx_stock_prices_symbol_timestamp,priority:1"`
+	Price       decimal.Decimal `gorm:"type:numeric(18,4);not null"`
+	Timestamp   time.Time       `gorm:"not null;uniqueIndex:idx_stock_prices_symbol_timestamp,priority:2"`
+	CreatedAt   time.Time       `gorm:"autoCreateTime"`
+}
+
+func (StockPrice) TableName() string {
+	return "stock_prices"
+}
+
+const (
+	MarketStatusOpen       = "OPEN"
+	MarketStatusPreOpen    = "PRE_OPEN"
+	MarketStatusClosed     = "CLOSED"
+	MarketStatusHoliday    = "HOLIDAY"
+	MarketStatusNonTrading = "NON_TRADING_DAY"
+)
+
+// MarketStatus describes the trading session at a point in time. ClosesAt is
+// set only while the market is open, and Holiday only on a listed holiday.
+// PreviousCloseAt is the session close that day change is measured from.
+type MarketStatus struct {
+	Status          string
+	Holiday         string
+	ClosesAt        *time.Time
+	NextOpen        time.Time
+	PreviousCloseAt time.Time
 }
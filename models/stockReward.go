@@ -1,16 +1,22 @@
 package models
 
 import (
+	"assignment/decimal"
 	"time"
 )
 
 type StockReward struct {
-	ID                 string    `gorm:"type:varchar(255);primaryKey"`
-	UserID             string    `gorm:"type:varchar(255);not null;index"`
-	StockSymbol        string    `gorm:"type:varchar(50);not null"`
-	Quantity           float64   `gorm:"type:numeric(18,6);not null"`
-	RewardTimestamp    time.Time `gorm:"not null;index"`
-	StockPriceAtReward float64   `gorm:"type:numeric(18,4);not null"`
+	ID                 string          `gorm:"type:varchar(255);primaryKey"`
+	UserID             string          `gorm:"type:varchar(255);not null;index"`
+	StockSymbol        string          `gorm:"type:varchar(50);not null"`
+	Quantity           decimal.Decimal `gorm:"type:numeric(18,6);not null"`
+	RewardTimestamp    time.Time       `gorm:"not null;index"`
+	StockPriceAtReward decimal.Decimal `gorm:"type:numeric(18,4);not null"`
+	CampaignID         *string         `gorm:"type:varchar(64);index"`
+	FeeScheduleID      *uint           `gorm:"index"`
+	Status             string          `gorm:"type:varchar(20);not null;default:ACTIVE;index"`
+	ReversalReason     string          `gorm:"type:text"`
+	ReversedAt         *time.Time
 	CreatedAt          time.Time `gorm:"autoCreateTime"`
 }
 
@@ -18,26 +24,70 @@ func (StockReward) TableName() string {
 	return "stock_rewards"
 }
 
+const (
+	RewardStatusActive   = "ACTIVE"
+	RewardStatusReversed = "REVERSED"
+)
+
 type RewardRequest struct {
-	ID              string    `json:"id" binding:"required"`
-	UserID          string    `json:"user_id" binding:"required"`
-	StockSymbol     string    `json:"stock_symbol" binding:"required"`
-	Quantity        float64   `json:"quantity" binding:"required,gt=0"`
-	RewardTimestamp time.Time `json:"reward_timestamp" binding:"required"`
+	ID              string          `json:"id" binding:"required"`
+	UserID          string          `json:"user_id" binding:"required"`
+	StockSymbol     string          `json:"stock_symbol" binding:"required"`
+	Quantity        decimal.Decimal `json:"quantity" binding:"required,gt=0"`
+	RewardTimestamp time.Time       `json:"reward_timestamp" binding:"required"`
+	CampaignID      string          `json:"campaign_id,omitempty" binding:"omitempty,max=64"`
+}
+
+type ReverseRewardRequest struct {
+	Reason string `json:"reason" binding:"required"`
 }
 
 type RewardResponse struct {
 	Success        bool
 	Message        string
 	Reward         *StockReward
-	INRValue       float64
+	INRValue       decimal.Decimal
 	CompanyCharges *CompanyCharges
+	Code           string
+	Diff           map[string]FieldDiff
+}
+
+type FieldDiff struct {
+	Stored   string
+	Received string
+}
+
+type ReversalResponse struct {
+	Success       bool
+	Message       string
+	Reward        *StockReward
+	LedgerEntries []LedgerEntry
 }
 
 type CompanyCharges struct {
-	StockCost float64
-	Brokerage float64
-	STT       float64
-	GST       float64
-	TotalCost float64
+	FeeScheduleID   uint
+	StockCost       decimal.Decimal
+	Brokerage       decimal.Decimal
+	STT             decimal.Decimal
+	ExchangeCharges decimal.Decimal
+	SEBIFees        decimal.Decimal
+	StampDuty       decimal.Decimal
+	GST             decimal.Decimal
+	TotalCost       decimal.Decimal
+}
+
+type RewardListQuery struct {
+	From   time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To     time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Symbol string    `form:"symbol"`
+	Status string    `form:"status"`
+	Sort   string    `form:"sort" binding:"omitempty,oneof=asc desc"`
+	Cursor string    `form:"cursor"`
+	Limit  int       `form:"limit" binding:"omitempty,gte=1,lte=500"`
+}
+
+type RewardListResponse struct {
+	UserID     string
+	Rewards    []StockReward
+	NextCursor string
 }
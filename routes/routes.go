@@ -0,0 +1,99 @@
+package routes
+
+import (
+	"assignment/app"
+	"assignment/controllers"
+	"assignment/initializers"
+	"assignment/middleware"
+	"assignment/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// New builds the API router with every handler bound to a.
+func New(a *app.App, authConfig initializers.AuthConfig) *gin.Engine {
+	rewards := controllers.NewRewardController(a)
+	batches := controllers.NewRewardBatchController(a)
+	todayStocks := controllers.NewTodayStocksController(a)
+	historical := controllers.NewHistoricalController(a)
+	stats := controllers.NewStatsController(a)
+	portfolio := controllers.NewPortfolioController(a)
+	dividends := controllers.NewDividendController(a)
+	redemptions := controllers.NewRedemptionController(a)
+	transfers := controllers.NewDematTransferController(a)
+	limits := controllers.NewLimitController(a)
+	ledger := controllers.NewLedgerController(a)
+	instruments := controllers.NewInstrumentController(a)
+	campaigns := controllers.NewCampaignController(a)
+	feeSchedules := controllers.NewFeeScheduleController(a)
+	reconciliation := controllers.NewReconciliationController(a)
+	corporateActions := controllers.NewCorporateActionController(a)
+
+	auth := middleware.NewAuth(authConfig, a.Clock, a.Log)
+
+	server := gin.Default()
+	server.Use(auth.Authenticate())
+
+	service := server.Group("", auth.RequireRoles(models.RoleService, models.RoleAdmin))
+	service.POST("/reward", rewards.RewardUser)
+	service.POST("/rewards/batch", batches.RewardUsersBatch)
+	service.POST("/redemptions", redemptions.RedeemShares)
+	service.POST("/transfers", transfers.RequestDematTransfer)
+
+	user := server.Group("", auth.RequireSelfOrRoles("userId", models.RoleService, models.RoleAdmin))
+	user.GET("/rewards/:userId", rewards.ListUserRewards)
+	user.GET("/today-stocks/:userId", todayStocks.GetTodayStocks)
+	user.GET("/historical-inr/:userId", historical.GetHistoricalINR)
+	user.GET("/stats/:userId", stats.GetUserStats)
+	user.GET("/portfolio/:userId", portfolio.GetUserPortfolio)
+	user.GET("/dividends/:userId", dividends.GetUserDividends)
+	user.GET("/redemptions/:userId", redemptions.GetUserRedemptions)
+	user.GET("/transfers/:userId", transfers.GetUserDematTransfers)
+	user.GET("/limits/:userId", limits.GetUserLimits)
+
+	reversals := server.Group("/reward", auth.RequireRoles(models.RoleAdmin))
+	reversals.POST("/:id/reverse", rewards.ReverseReward)
+
+	ledgerGroup := server.Group("/ledger", auth.RequireRoles(models.RoleAdmin))
+	ledgerGroup.GET("/entries", ledger.ListLedgerEntries)
+	ledgerGroup.GET("/balances", ledger.GetAccountBalances)
+	ledgerGroup.GET("/trial-balance", ledger.GetTrialBalance)
+
+	admin := server.Group("/admin", auth.RequireRoles(models.RoleAdmin))
+	admin.GET("/instruments", instruments.ListInstruments)
+	admin.GET("/instruments/:symbol", instruments.GetInstrument)
+	admin.POST("/instruments", instruments.CreateInstrument)
+	admin.PUT("/instruments/:symbol", instruments.UpdateInstrument)
+	admin.DELETE("/instruments/:symbol", instruments.DeleteInstrument)
+	admin.GET("/campaigns", campaigns.ListCampaigns)
+	admin.GET("/campaigns/spend", campaigns.ListCampaignSpend)
+	admin.GET("/campaigns/:id", campaigns.GetCampaign)
+	admin.GET("/campaigns/:id/spend", campaigns.GetCampaignSpend)
+	admin.POST("/campaigns", campaigns.CreateCampaign)
+	admin.PUT("/campaigns/:id", campaigns.UpdateCampaign)
+	admin.DELETE("/campaigns/:id", campaigns.DeleteCampaign)
+	admin.GET("/fee-schedules", feeSchedules.ListFeeSchedules)
+	admin.GET("/fee-schedules/effective", feeSchedules.GetEffectiveFeeSchedule)
+	admin.GET("/fee-schedules/:id", feeSchedules.GetFeeSchedule)
+	admin.POST("/fee-schedules", feeSchedules.CreateFeeSchedule)
+	admin.GET("/reconciliation-runs", reconciliation.ListReconciliationRuns)
+	admin.GET("/reconciliation-runs/:id", reconciliation.GetReconciliationRun)
+	admin.POST("/reconciliation-runs", reconciliation.RunReconciliation)
+	admin.GET("/corporate-actions", corporateActions.ListCorporateActions)
+	admin.GET("/corporate-actions/:id", corporateActions.GetCorporateAction)
+	admin.POST("/corporate-actions", corporateActions.CreateCorporateAction)
+	admin.POST("/corporate-actions/:id/apply", corporateActions.ApplyCorporateAction)
+	admin.GET("/dividends", dividends.ListDividendDeclarations)
+	admin.GET("/dividends/:id", dividends.GetDividendDeclaration)
+	admin.POST("/dividends", dividends.CreateDividendDeclaration)
+	admin.POST("/dividends/:id/accrue", dividends.AccrueDividend)
+	admin.POST("/dividends/:id/pay", dividends.PayDividend)
+	admin.GET("/transfers", transfers.ListDematTransfers)
+	admin.GET("/transfers/:id", transfers.GetDematTransfer)
+	admin.POST("/transfers/:id/approve", transfers.ApproveDematTransfer)
+	admin.POST("/transfers/:id/submit", transfers.SubmitDematTransfer)
+	admin.POST("/transfers/:id/refresh", transfers.RefreshDematTransfer)
+	admin.POST("/transfers/:id/reject", transfers.RejectDematTransfer)
+
+	return server
+}
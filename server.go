@@ -1,38 +1,97 @@
 package main
 
 import (
-	"assignment/controllers"
+	"assignment/app"
+	"assignment/commands"
 	"assignment/initializers"
+	"assignment/lifecycle"
+	"assignment/routes"
 	"assignment/services"
+	"context"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
-
-	"github.com/gin-gonic/gin"
+	"os/signal"
+	"syscall"
 )
 
-func init() {
+func main() {
 	initializers.LoadEnv()
-	initializers.InitLogger()
-	initializers.ConnectDB()
-}
+	log := initializers.NewLogger()
+	db := initializers.ConnectDB(log)
 
-func main() {
-	services.StartPriceUpdateScheduler()
+	if err := services.SeedInstruments(db); err != nil {
+		log.WithError(err).Fatal("Failed to seed instruments")
+	}
 
-	server := gin.Default()
+	if err := services.SeedFeeSchedules(db); err != nil {
+		log.WithError(err).Fatal("Failed to seed fee schedules")
+	}
 
-	server.POST("/reward", controllers.RewardUser)
-	server.GET("/today-stocks/:userId", controllers.GetTodayStocks)
-	server.GET("/historical-inr/:userId", controllers.GetHistoricalINR)
-	server.GET("/stats/:userId", controllers.GetUserStats)
-	server.GET("/portfolio/:userId", controllers.GetUserPortfolio)
+	priceProvider, err := services.NewPriceProvider(initializers.LoadPriceProviderConfig())
+	if err != nil {
+		log.WithError(err).Fatal("Failed to configure price provider")
+	}
 
-	port := initializers.GetEnv("PORT", "8080")
+	config, err := app.LoadConfig()
+	if err != nil {
+		log.WithError(err).Fatal("Invalid configuration")
+	}
+
+	a := app.New(db, log, priceProvider, services.SystemClock{}, config)
+
+	if len(os.Args) > 1 {
+		code := commands.Run(a, os.Args[1:])
+		initializers.CloseDB(db, log)
+		os.Exit(code)
+	}
 
-	fmt.Printf("Server starting on port %s\n", port)
+	a.Depository, err = services.NewDepositoryAdapter(initializers.LoadDepositoryConfig())
+	if err != nil {
+		log.WithError(err).Fatal("Failed to configure depository adapter")
+	}
 
-	if err := server.Run(":" + port); err != nil {
+	authConfig, err := initializers.LoadAuthConfig()
+	if err != nil {
+		log.WithError(err).Fatal("Invalid auth configuration")
+	}
+
+	calendarConfig, err := initializers.LoadTradingCalendarConfig()
+	if err != nil {
+		log.WithError(err).Fatal("Invalid trading calendar")
+	}
+	a.Calendar, err = services.NewMarketCalendar(calendarConfig, a.Location)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to load trading calendar")
+	}
+
+	serverConfig, err := initializers.LoadServerConfig()
+	if err != nil {
+		log.WithError(err).Fatal("Invalid server configuration")
+	}
+
+	listener, err := net.Listen("tcp", ":"+serverConfig.Port)
+	if err != nil {
 		fmt.Printf("Failed to start server: %v\n", err)
 		os.Exit(1)
 	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	schedulerDone := a.Prices.StartPriceUpdateScheduler(ctx, a.Calendar, calendarConfig.RefreshInterval)
+
+	fmt.Printf("Server starting on port %s\n", serverConfig.Port)
+
+	httpServer := &http.Server{Handler: routes.New(a, authConfig)}
+	err = lifecycle.Serve(ctx, httpServer, listener, serverConfig.ShutdownTimeout)
+	stop()
+	if err != nil {
+		log.WithError(err).Error("Server did not shut down cleanly")
+	}
+
+	<-schedulerDone
+	initializers.CloseDB(db, log)
+	log.Info("Server stopped")
 }
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"assignment/decimal"
+	"assignment/models"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUnknownCampaign   = errors.New("unknown campaign")
+	ErrCampaignNotActive = errors.New("campaign is not active")
+	ErrSymbolNotEligible = errors.New("stock symbol is not eligible for the campaign")
+	ErrInvalidCampaign   = errors.New("invalid campaign")
+	ErrCampaignNotFound  = errors.New("campaign not found")
+)
+
+// ValidateCampaign normalizes the eligible symbols and checks that they are
+// known instruments and that the dates are in order.
+func ValidateCampaign(db *gorm.DB, campaign *models.Campaign) error {
+	if campaign.EndDate != nil && campaign.EndDate.Before(campaign.StartDate) {
+		return fmt.Errorf("%w: end_date cannot be before start_date", ErrInvalidCampaign)
+	}
+
+	seen := make(map[string]bool)
+	symbols := make([]string, 0, len(campaign.EligibleSymbols))
+	for _, symbol := range campaign.EligibleSymbols {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if symbol == "" || seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+
+		var count int64
+		if err := db.Model(&models.Instrument{}).Where("symbol = ?", symbol).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return fmt.Errorf("%w: %s", ErrUnknownInstrument, symbol)
+		}
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	campaign.EligibleSymbols = symbols
+
+	return nil
+}
+
+func GetCampaign(db *gorm.DB, campaignID string) (*models.Campaign, error) {
+	var campaign models.Campaign
+	err := db.Where("id = ?", campaignID).First(&campaign).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrCampaignNotFound, campaignID)
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &campaign, nil
+}
+
+// GetRewardCampaign returns the campaign a reward names, checking that it is
+// running at the reward time and covers the symbol.
+func GetRewardCampaign(db *gorm.DB, campaignID, stockSymbol string, at time.Time) (*models.Campaign, error) {
+	campaign, err := GetCampaign(db, campaignID)
+	if errors.Is(err, ErrCampaignNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCampaign, campaignID)
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := checkCampaignEligibility(campaign, stockSymbol, at); err != nil {
+		return nil, err
+	}
+	return campaign, nil
+}
+
+func checkCampaignEligibility(campaign *models.Campaign, stockSymbol string, at time.Time) error {
+	if campaign.Status != models.CampaignStatusActive {
+		return fmt.Errorf("%w: %s is %s", ErrCampaignNotActive, campaign.ID, campaign.Status)
+	}
+	if at.Before(campaign.StartDate) || (campaign.EndDate != nil && at.After(*campaign.EndDate)) {
+		return fmt.Errorf("%w: %s does not run at %s", ErrCampaignNotActive, campaign.ID, at.UTC().Format(time.RFC3339))
+	}
+
+	if len(campaign.EligibleSymbols) == 0 {
+		return nil
+	}
+	for _, symbol := range campaign.EligibleSymbols {
+		if symbol == stockSymbol {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s is not in %s", ErrSymbolNotEligible, stockSymbol, campaign.ID)
+}
+
+// isCampaignError reports whether err rejects a reward because of its
+// campaign.
+func isCampaignError(err error) bool {
+	return errors.Is(err, ErrUnknownCampaign) || errors.Is(err, ErrCampaignNotActive) || errors.Is(err, ErrSymbolNotEligible)
+}
+
+// GetCampaignSpend aggregates the REWARD and REVERSAL ledger entries of each
+// campaign's rewards effective in [from, to). Zero times leave that end open.
+// An empty campaignID reports every campaign.
+func GetCampaignSpend(db *gorm.DB, campaignID string, from, to time.Time) ([]models.CampaignSpend, error) {
+	campaignQuery := db.Order("id")
+	if campaignID != "" {
+		campaignQuery = campaignQuery.Where("id = ?", campaignID)
+	}
+	var campaigns []models.Campaign
+	if err := campaignQuery.Find(&campaigns).Error; err != nil {
+		return nil, err
+	}
+	if campaignID != "" && len(campaigns) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrCampaignNotFound, campaignID)
+	}
+
+	ledgerQuery := db.Table("ledger_entries").
+		Select("stock_rewards.campaign_id, ledger_entries.account_type, "+
+			"SUM(ledger_entries.debit_amount) AS debit, SUM(ledger_entries.credit_amount) AS credit").
+		Joins("JOIN stock_rewards ON stock_rewards.id = ledger_entries.reward_id").
+		Where("stock_rewards.campaign_id IS NOT NULL").
+		Where("ledger_entries.entry_type IN ?", []string{models.EntryTypeReward, models.EntryTypeReversal})
+	if campaignID != "" {
+		ledgerQuery = ledgerQuery.Where("stock_rewards.campaign_id = ?", campaignID)
+	}
+	if !from.IsZero() {
+		ledgerQuery = ledgerQuery.Where("ledger_entries.effective_at >= ?", from.UTC())
+	}
+	if !to.IsZero() {
+		ledgerQuery = ledgerQuery.Where("ledger_entries.effective_at < ?", to.UTC())
+	}
+
+	var totals []struct {
+		CampaignID  string
+		AccountType string
+		Debit       decimal.Decimal
+		Credit      decimal.Decimal
+	}
+	err := ledgerQuery.Group("stock_rewards.campaign_id, ledger_entries.account_type").Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+
+	countQuery := db.Model(&models.StockReward{}).
+		Select("campaign_id, status, COUNT(*) AS count").
+		Where("campaign_id IS NOT NULL")
+	if campaignID != "" {
+		countQuery = countQuery.Where("campaign_id = ?", campaignID)
+	}
+	if !from.IsZero() {
+		countQuery = countQuery.Where("reward_timestamp >= ?", from.UTC())
+	}
+	if !to.IsZero() {
+		countQuery = countQuery.Where("reward_timestamp < ?", to.UTC())
+	}
+
+	var counts []struct {
+		CampaignID string
+		Status     string
+		Count      int64
+	}
+	if err := countQuery.Group("campaign_id, status").Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+
+	spends := make([]models.CampaignSpend, len(campaigns))
+	byID := make(map[string]*models.CampaignSpend, len(campaigns))
+	for i, campaign := range campaigns {
+		spends[i] = models.CampaignSpend{
+			CampaignID: campaign.ID,
+			Name:       campaign.Name,
+			Status:     campaign.Status,
+			Budget:     campaign.Budget,
+		}
+		byID[campaign.ID] = &spends[i]
+	}
+
+	for _, total := range totals {
+		spend, ok := byID[total.CampaignID]
+		if !ok {
+			continue
+		}
+		amount := total.Debit.Sub(total.Credit).RoundBank(2)
+		switch total.AccountType {
+		case models.AccountTypeStockAsset:
+			spend.StockCost = amount
+		case models.AccountTypeBrokerageExp:
+			spend.Brokerage = amount
+		case models.AccountTypeSTTExp:
+			spend.STT = amount
+		case models.AccountTypeExchangeExp:
+			spend.ExchangeCharges = amount
+		case models.AccountTypeSEBIFeesExp:
+			spend.SEBIFees = amount
+		case models.AccountTypeStampDutyExp:
+			spend.StampDuty = amount
+		case models.AccountTypeGSTExp:
+			spend.GST = amount
+		}
+	}
+
+	for _, count := range counts {
+		spend, ok := byID[count.CampaignID]
+		if !ok {
+			continue
+		}
+		if count.Status == models.RewardStatusActive {
+			spend.ActiveRewards = count.Count
+		} else {
+			spend.ReversedRewards += count.Count
+		}
+	}
+
+	for i := range spends {
+		spend := &spends[i]
+		spend.TotalCharges = spend.Brokerage.Add(spend.STT).Add(spend.ExchangeCharges).Add(spend.SEBIFees).Add(spend.StampDuty).Add(spend.GST)
+		spend.TotalCost = spend.StockCost.Add(spend.TotalCharges)
+		if spend.Budget.IsPositive() {
+			remaining := decimal.Max(spend.Budget.Sub(spend.StockCost), decimal.Zero)
+			spend.RemainingBudget = &remaining
+		}
+	}
+
+	return spends, nil
+}
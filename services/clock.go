@@ -0,0 +1,21 @@
+package services
+
+import "time"
+
+// Clock tells handlers and jobs the current time, so tests can fix it.
+type Clock interface {
+	Now() time.Time
+}
+
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// FixedClock always reports the same instant.
+type FixedClock time.Time
+
+func (c FixedClock) Now() time.Time {
+	return time.Time(c)
+}
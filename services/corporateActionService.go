@@ -0,0 +1,255 @@
+package services
+
+import (
+	"assignment/decimal"
+	"assignment/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const quantityScale = 6
+
+var (
+	ErrCorporateActionNotFound = errors.New("corporate action not found")
+	ErrCorporateActionApplied  = errors.New("corporate action has already been applied")
+	ErrInvalidCorporateAction  = errors.New("invalid corporate action")
+	ErrCorporateActionNotDue   = errors.New("corporate action ex-date has not been reached")
+	ErrRewardBeforeExDate      = errors.New("reward timestamp is before an applied corporate action")
+	ErrCorporateActionBlocked  = errors.New("corporate action blocked by pending transfers")
+)
+
+func ValidateCorporateAction(db *gorm.DB, action *models.CorporateAction) error {
+	var count int64
+	if err := db.Model(&models.Instrument{}).Where("symbol = ?", action.StockSymbol).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("%w: %s", ErrUnknownInstrument, action.StockSymbol)
+	}
+
+	switch action.ActionType {
+	case models.CorporateActionSplit, models.CorporateActionBonus, models.CorporateActionMerger:
+		if !action.RatioNumerator.IsPositive() || !action.RatioDenominator.IsPositive() {
+			return fmt.Errorf("%w: %s needs a positive ratio_numerator and ratio_denominator", ErrInvalidCorporateAction, action.ActionType)
+		}
+	case models.CorporateActionSymbolChange:
+		action.RatioNumerator = decimal.NewFromInt(1)
+		action.RatioDenominator = decimal.NewFromInt(1)
+	}
+
+	switch action.ActionType {
+	case models.CorporateActionMerger, models.CorporateActionSymbolChange:
+		if action.NewSymbol == "" || action.NewSymbol == action.StockSymbol {
+			return fmt.Errorf("%w: %s needs a new_symbol different from stock_symbol", ErrInvalidCorporateAction, action.ActionType)
+		}
+		if _, err := GetTradableInstrument(db, action.NewSymbol); err != nil {
+			return err
+		}
+	default:
+		action.NewSymbol = ""
+	}
+
+	return nil
+}
+
+// ApplyCorporateAction adjusts every active reward lot of the action's symbol
+// that was rewarded before the ex-date, once the ex-date has passed. Each lot
+// is adjusted by what it holds when the action is applied, so shares redeemed
+// or transferred since the ex-date are not adjusted again. Past entries are
+// never edited: splits and bonuses add zero-cost shares, while mergers and
+// symbol changes move each lot's quantity and book value from the old symbol
+// to the new one.
+func ApplyCorporateAction(db *gorm.DB, clock Clock, actionID uint) (*models.CorporateAction, error) {
+	var action models.CorporateAction
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", actionID).First(&action).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: %d", ErrCorporateActionNotFound, actionID)
+			}
+			return err
+		}
+
+		appliedAt := clock.Now()
+		if action.ExDate.After(appliedAt) {
+			return fmt.Errorf("%w: %d takes effect on %s", ErrCorporateActionNotDue, actionID, action.ExDate.Format(time.RFC3339))
+		}
+
+		result := tx.Model(&models.CorporateAction{}).
+			Where("id = ? AND status = ?", actionID, models.CorporateActionStatusPending).
+			Updates(map[string]interface{}{
+				"status":     models.CorporateActionStatusApplied,
+				"applied_at": appliedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: %d", ErrCorporateActionApplied, actionID)
+		}
+
+		lots, err := lockActionLots(tx, action.StockSymbol, action.ExDate)
+		if err != nil {
+			return err
+		}
+
+		var entries []models.LedgerEntry
+		for _, lot := range lots {
+			entries = append(entries, corporateActionEntries(&action, lot)...)
+		}
+
+		if len(entries) > 0 {
+			if err := tx.CreateInBatches(&entries, 500).Error; err != nil {
+				return fmt.Errorf("failed to record corporate action entries: %w", err)
+			}
+		}
+
+		if action.NewSymbol != "" {
+			err := tx.Model(&models.Instrument{}).
+				Where("symbol = ?", action.StockSymbol).
+				Update("status", models.InstrumentStatusDelisted).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		action.Status = models.CorporateActionStatusApplied
+		action.AppliedAt = &appliedAt
+		action.LotsAdjusted = len(lots)
+
+		return tx.Model(&models.CorporateAction{}).Where("id = ?", actionID).
+			Update("lots_adjusted", action.LotsAdjusted).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &action, nil
+}
+
+// lockActionLots locks the rewards holding symbol before the ex-date and
+// returns their lots. A pending transfer has locked lots that the action
+// would move or resize, so the action must wait until it completes or fails;
+// new transfers and redemptions of these rewards wait for the action.
+func lockActionLots(tx *gorm.DB, symbol string, exDate time.Time) ([]rewardLot, error) {
+	lots, err := loadRewardLots(tx, symbol, rewardedBefore(exDate))
+	if err != nil || len(lots) == 0 {
+		return lots, err
+	}
+
+	rewardIDs := make([]string, len(lots))
+	for i, lot := range lots {
+		rewardIDs[i] = lot.rewardID
+	}
+	var locked []models.StockReward
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		Where("id IN ?", rewardIDs).
+		Order("id").
+		Find(&locked).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var pending int64
+	err = tx.Model(&models.DematTransfer{}).
+		Where("stock_symbol = ? AND status IN ?", symbol, models.TransferPendingStatuses).
+		Count(&pending).Error
+	if err != nil {
+		return nil, err
+	}
+	if pending > 0 {
+		return nil, fmt.Errorf("%w: %d transfers of %s are pending", ErrCorporateActionBlocked, pending, symbol)
+	}
+
+	// Reload in case a redemption committed before the locks were taken.
+	return loadRewardLots(tx, symbol, rewardedBefore(exDate))
+}
+
+func corporateActionEntries(action *models.CorporateAction, lot rewardLot) []models.LedgerEntry {
+	rewardID := lot.rewardID
+	referenceID := fmt.Sprintf("CA-%d", action.ID)
+	ratio := fmt.Sprintf("%s:%s", trimZeros(action.RatioNumerator), trimZeros(action.RatioDenominator))
+	oldSymbol := action.StockSymbol
+
+	switch action.ActionType {
+	case models.CorporateActionSplit, models.CorporateActionBonus:
+		added := lot.quantity.Mul(action.RatioNumerator).Div(action.RatioDenominator, quantityScale)
+		if action.ActionType == models.CorporateActionSplit {
+			added = added.Sub(lot.quantity)
+		}
+		if added.IsZero() {
+			return nil
+		}
+
+		return []models.LedgerEntry{{
+			RewardID:     rewardID,
+			EntryType:    models.EntryTypeCorporateAction,
+			AccountType:  models.AccountTypeStockAsset,
+			StockSymbol:  &oldSymbol,
+			DebitAmount:  decimal.Zero,
+			CreditAmount: decimal.Zero,
+			Quantity:     &added,
+			Description:  fmt.Sprintf("%s %s of %s: %s shares added", action.ActionType, ratio, oldSymbol, added),
+			ReferenceID:  referenceID,
+			EffectiveAt:  action.ExDate,
+		}}
+	}
+
+	removed := lot.quantity.Neg()
+	added := lot.quantity.Mul(action.RatioNumerator).Div(action.RatioDenominator, quantityScale)
+	newSymbol := action.NewSymbol
+
+	return []models.LedgerEntry{
+		{
+			RewardID:     rewardID,
+			EntryType:    models.EntryTypeCorporateAction,
+			AccountType:  models.AccountTypeStockAsset,
+			StockSymbol:  &oldSymbol,
+			DebitAmount:  decimal.Zero,
+			CreditAmount: lot.bookValue,
+			Quantity:     &removed,
+			Description:  fmt.Sprintf("%s %s: %s shares of %s exchanged", action.ActionType, ratio, lot.quantity, oldSymbol),
+			ReferenceID:  referenceID,
+			EffectiveAt:  action.ExDate,
+		},
+		{
+			RewardID:     rewardID,
+			EntryType:    models.EntryTypeCorporateAction,
+			AccountType:  models.AccountTypeStockAsset,
+			StockSymbol:  &newSymbol,
+			DebitAmount:  lot.bookValue,
+			CreditAmount: decimal.Zero,
+			Quantity:     &added,
+			Description:  fmt.Sprintf("%s %s: %s shares of %s received", action.ActionType, ratio, added, newSymbol),
+			ReferenceID:  referenceID,
+			EffectiveAt:  action.ExDate,
+		},
+	}
+}
+
+// appliedExDate returns the latest ex-date of a corporate action applied to
+// symbol, or the zero time if there is none.
+func appliedExDate(db *gorm.DB, symbol string) (time.Time, error) {
+	var action models.CorporateAction
+	err := db.Where("stock_symbol = ? AND status = ?", symbol, models.CorporateActionStatusApplied).
+		Order("ex_date DESC").
+		Limit(1).
+		Find(&action).Error
+	return action.ExDate, err
+}
+
+// checkRewardAfterExDate rejects a reward dated before exDate: the corporate
+// action applied on that date has already adjusted the symbol's lots and
+// would never reach it.
+func checkRewardAfterExDate(symbol string, rewardedAt, exDate time.Time) error {
+	if rewardedAt.Before(exDate) {
+		return fmt.Errorf("%w: %s was adjusted on %s", ErrRewardBeforeExDate, symbol, exDate.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidTimezone = errors.New("invalid timezone")
+
+// DateRange is the half-open interval [Start, End).
+type DateRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+func (r DateRange) Contains(at time.Time) bool {
+	return !at.Before(r.Start) && at.Before(r.End)
+}
+
+// ResolveLocation returns the IANA zone name, or fallback when name is
+// empty.
+func ResolveLocation(name string, fallback *time.Location) (*time.Location, error) {
+	if name == "" {
+		return fallback, nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil || name == "Local" {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTimezone, name)
+	}
+	return location, nil
+}
+
+// DayRange is the calendar day containing at in location. Days are not
+// assumed to be 24 hours long.
+func DayRange(at time.Time, location *time.Location) DateRange {
+	at = at.In(location)
+	start := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, location)
+	return DateRange{Start: start, End: start.AddDate(0, 0, 1)}
+}
+
+// MonthRange is the calendar month containing at in location.
+func MonthRange(at time.Time, location *time.Location) DateRange {
+	at = at.In(location)
+	start := time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, location)
+	return DateRange{Start: start, End: start.AddDate(0, 1, 0)}
+}
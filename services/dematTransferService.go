@@ -0,0 +1,423 @@
+package services
+
+import (
+	"assignment/decimal"
+	"assignment/models"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrTransferNotFound      = errors.New("demat transfer not found")
+	ErrTransferInvalidStatus = errors.New("demat transfer is not in the required status")
+	ErrTransferConflict      = errors.New("transfer ID already used with different values")
+	ErrDepositoryUnavailable = errors.New("depository unavailable")
+)
+
+// CreateDematTransfer records a request to move shares to the user's own
+// demat account and locks them, oldest reward lots first, until the transfer
+// completes or fails. A retry with the same ID and values returns the
+// original transfer.
+func CreateDematTransfer(db *gorm.DB, clock Clock, req models.DematTransferRequest) (response *models.DematTransferResponse, replayed bool, err error) {
+	req.StockSymbol = strings.ToUpper(req.StockSymbol)
+	req.DepositoryParticipantID = strings.ToUpper(req.DepositoryParticipantID)
+
+	if response, err := replayDematTransfer(db, req); response != nil || err != nil {
+		return response, response != nil, err
+	}
+
+	instrument, err := GetTradableInstrument(db, req.StockSymbol)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var transfer models.DematTransfer
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var locked []models.StockReward
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			Where("user_id = ? AND status = ?", req.UserID, models.RewardStatusActive).
+			Find(&locked).Error
+		if err != nil {
+			return err
+		}
+
+		lots, err := loadFreeLots(tx, req.StockSymbol, req.UserID)
+		if err != nil {
+			return err
+		}
+
+		portions, err := allocateLots(lots, req.Quantity)
+		if err != nil {
+			return err
+		}
+
+		transfer = models.DematTransfer{
+			ID:                      req.ID,
+			UserID:                  req.UserID,
+			StockSymbol:             req.StockSymbol,
+			ISIN:                    instrument.ISIN,
+			Quantity:                req.Quantity,
+			DepositoryParticipantID: req.DepositoryParticipantID,
+			BeneficiaryAccount:      req.BeneficiaryAccount,
+			Status:                  models.TransferStatusRequested,
+			RequestedAt:             clock.Now(),
+		}
+		for _, portion := range portions {
+			transfer.CostBasis = transfer.CostBasis.Add(portion.cost)
+			transfer.Lots = append(transfer.Lots, models.DematTransferLot{
+				TransferID: req.ID,
+				RewardID:   portion.lot.rewardID,
+				Quantity:   portion.quantity,
+				CostBasis:  portion.cost,
+			})
+		}
+
+		return tx.Create(&transfer).Error
+	})
+
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		response, err := replayDematTransfer(db, req)
+		return response, response != nil, err
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	return &models.DematTransferResponse{
+		Success:  true,
+		Message:  "Transfer requested successfully",
+		Transfer: transfer,
+	}, false, nil
+}
+
+func replayDematTransfer(db *gorm.DB, req models.DematTransferRequest) (*models.DematTransferResponse, error) {
+	var existing models.DematTransfer
+	err := db.Preload("Lots", orderByID).Where("id = ?", req.ID).Limit(1).Find(&existing).Error
+	if err != nil || existing.ID == "" {
+		return nil, err
+	}
+
+	if existing.UserID != req.UserID || existing.StockSymbol != req.StockSymbol || !existing.Quantity.Equal(req.Quantity) ||
+		existing.DepositoryParticipantID != req.DepositoryParticipantID || existing.BeneficiaryAccount != req.BeneficiaryAccount {
+		return nil, fmt.Errorf("%w: %s", ErrTransferConflict, req.ID)
+	}
+
+	return &models.DematTransferResponse{
+		Success:  true,
+		Message:  "Transfer requested successfully",
+		Transfer: existing,
+	}, nil
+}
+
+func GetDematTransfer(db *gorm.DB, transferID string) (*models.DematTransfer, error) {
+	var transfer models.DematTransfer
+	err := db.Preload("Lots", orderByID).Where("id = ?", transferID).First(&transfer).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrTransferNotFound, transferID)
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &transfer, nil
+}
+
+func ListUserDematTransfers(db *gorm.DB, userID string) ([]models.DematTransfer, error) {
+	transfers := []models.DematTransfer{}
+	err := db.Preload("Lots", orderByID).
+		Where("user_id = ?", userID).
+		Order("requested_at DESC, id").
+		Find(&transfers).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return transfers, nil
+}
+
+func ApproveDematTransfer(db *gorm.DB, clock Clock, transferID string) (*models.DematTransfer, error) {
+	var transfer models.DematTransfer
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return transitionTransfer(tx, transferID, []string{models.TransferStatusRequested}, map[string]interface{}{
+			"status":      models.TransferStatusApproved,
+			"approved_at": clock.Now(),
+		}, &transfer)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &transfer, nil
+}
+
+// RejectDematTransfer fails a transfer that has not been sent to the
+// depository yet, releasing its shares.
+func RejectDematTransfer(db *gorm.DB, clock Clock, transferID, reason string) (*models.DematTransfer, error) {
+	var transfer models.DematTransfer
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return transitionTransfer(tx, transferID, []string{models.TransferStatusRequested, models.TransferStatusApproved}, map[string]interface{}{
+			"status":         models.TransferStatusFailed,
+			"failure_reason": reason,
+			"failed_at":      clock.Now(),
+		}, &transfer)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &transfer, nil
+}
+
+// SubmitDematTransfer sends an approved transfer to the depository. The
+// depository is called before the status changes, so it must treat a
+// resubmitted transfer ID as the same instruction.
+func SubmitDematTransfer(db *gorm.DB, clock Clock, depository DepositoryAdapter, transferID string) (*models.DematTransfer, error) {
+	transfer, err := GetDematTransfer(db, transferID)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.Status != models.TransferStatusApproved {
+		return nil, fmt.Errorf("%w: transfer %s is %s, expected %s", ErrTransferInvalidStatus, transferID, transfer.Status, models.TransferStatusApproved)
+	}
+
+	status, err := depository.Submit(DepositoryInstruction{
+		TransferID:              transfer.ID,
+		ISIN:                    transfer.ISIN,
+		StockSymbol:             transfer.StockSymbol,
+		Quantity:                transfer.Quantity,
+		DepositoryParticipantID: transfer.DepositoryParticipantID,
+		BeneficiaryAccount:      transfer.BeneficiaryAccount,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDepositoryUnavailable, err)
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := transitionTransfer(tx, transferID, []string{models.TransferStatusApproved}, map[string]interface{}{
+			"status":               models.TransferStatusSubmitted,
+			"depository_reference": status.Reference,
+			"submitted_at":         clock.Now(),
+		}, transfer)
+		if err != nil {
+			return err
+		}
+		return applyDepositoryStatus(tx, clock, transfer, status)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// RefreshDematTransfer asks the depository how a submitted transfer is
+// progressing and completes or fails it accordingly.
+func RefreshDematTransfer(db *gorm.DB, clock Clock, depository DepositoryAdapter, transferID string) (*models.DematTransfer, error) {
+	transfer, err := GetDematTransfer(db, transferID)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.Status != models.TransferStatusSubmitted {
+		return nil, fmt.Errorf("%w: transfer %s is %s, expected %s", ErrTransferInvalidStatus, transferID, transfer.Status, models.TransferStatusSubmitted)
+	}
+
+	status, err := depository.Status(transfer.DepositoryReference)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDepositoryUnavailable, err)
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		return applyDepositoryStatus(tx, clock, transfer, status)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+func applyDepositoryStatus(tx *gorm.DB, clock Clock, transfer *models.DematTransfer, status DepositoryStatus) error {
+	switch status.State {
+	case DepositoryStateCompleted:
+		return completeTransfer(tx, transfer, clock.Now())
+	case DepositoryStateFailed:
+		reason := status.Reason
+		if reason == "" {
+			reason = "Rejected by depository"
+		}
+		return transitionTransfer(tx, transfer.ID, []string{models.TransferStatusSubmitted}, map[string]interface{}{
+			"status":         models.TransferStatusFailed,
+			"failure_reason": reason,
+			"failed_at":      clock.Now(),
+		}, transfer)
+	default:
+		return nil
+	}
+}
+
+// completeTransfer derecognises the transferred shares: each locked lot is
+// credited out of STOCK_ASSET at its locked cost and debited to
+// SHARES_TRANSFERRED_OUT.
+func completeTransfer(tx *gorm.DB, transfer *models.DematTransfer, completedAt time.Time) error {
+	lots, err := loadRewardLots(tx, transfer.StockSymbol, ownedBy(transfer.UserID))
+	if err != nil {
+		return err
+	}
+	held := make(map[string]decimal.Decimal, len(lots))
+	for _, lot := range lots {
+		held[lot.rewardID] = lot.quantity
+	}
+	for _, lot := range transfer.Lots {
+		if held[lot.RewardID].LessThan(lot.Quantity) {
+			return fmt.Errorf("%w: reward %s no longer holds %s shares of %s", ErrInsufficientHoldings, lot.RewardID, trimZeros(lot.Quantity), transfer.StockSymbol)
+		}
+	}
+
+	err = transitionTransfer(tx, transfer.ID, []string{models.TransferStatusSubmitted}, map[string]interface{}{
+		"status":       models.TransferStatusCompleted,
+		"completed_at": completedAt,
+	}, transfer)
+	if err != nil {
+		return err
+	}
+
+	symbol := transfer.StockSymbol
+	referenceID := transferReference(transfer.ID)
+	entries := make([]models.LedgerEntry, 0, 2*len(transfer.Lots))
+	for _, lot := range transfer.Lots {
+		moved := lot.Quantity.Neg()
+		entries = append(entries,
+			models.LedgerEntry{
+				RewardID:     lot.RewardID,
+				EntryType:    models.EntryTypeTransfer,
+				AccountType:  models.AccountTypeStockAsset,
+				StockSymbol:  &symbol,
+				CreditAmount: lot.CostBasis,
+				Quantity:     &moved,
+				Description:  fmt.Sprintf("Transferred %s shares of %s to demat account %s", trimZeros(lot.Quantity), symbol, transfer.BeneficiaryAccount),
+				ReferenceID:  referenceID,
+				EffectiveAt:  completedAt,
+			},
+			models.LedgerEntry{
+				RewardID:    lot.RewardID,
+				EntryType:   models.EntryTypeTransfer,
+				AccountType: models.AccountTypeTransferredOut,
+				StockSymbol: &symbol,
+				DebitAmount: lot.CostBasis,
+				Description: fmt.Sprintf("Shares delivered to user %s", transfer.UserID),
+				ReferenceID: referenceID,
+				EffectiveAt: completedAt,
+			},
+		)
+	}
+
+	if err := tx.CreateInBatches(&entries, 500).Error; err != nil {
+		return fmt.Errorf("failed to record transfer entries: %w", err)
+	}
+	return nil
+}
+
+func transitionTransfer(tx *gorm.DB, transferID string, from []string, updates map[string]interface{}, transfer *models.DematTransfer) error {
+	if err := tx.Where("id = ?", transferID).First(transfer).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: %s", ErrTransferNotFound, transferID)
+		}
+		return err
+	}
+
+	result := tx.Model(&models.DematTransfer{}).
+		Where("id = ? AND status IN ?", transferID, from).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: transfer %s is %s, expected %s", ErrTransferInvalidStatus, transferID, transfer.Status, strings.Join(from, " or "))
+	}
+
+	return tx.Preload("Lots", orderByID).Where("id = ?", transferID).First(transfer).Error
+}
+
+func transferReference(transferID string) string {
+	return "TRF-" + transferID
+}
+
+func orderByID(db *gorm.DB) *gorm.DB {
+	return db.Order("id")
+}
+
+// lockedLots returns the quantity and cost of each of the user's reward lots
+// that is locked by pending transfers of the symbol.
+func lockedLots(db *gorm.DB, stockSymbol, userID string) (map[string]models.DematTransferLot, error) {
+	var rows []models.DematTransferLot
+	err := db.Model(&models.DematTransferLot{}).
+		Select("demat_transfer_lots.*").
+		Joins("JOIN demat_transfers ON demat_transfers.id = demat_transfer_lots.transfer_id").
+		Where("demat_transfers.user_id = ? AND demat_transfers.stock_symbol = ? AND demat_transfers.status IN ?",
+			userID, stockSymbol, models.TransferPendingStatuses).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	locked := make(map[string]models.DematTransferLot)
+	for _, row := range rows {
+		lot := locked[row.RewardID]
+		lot.Quantity = lot.Quantity.Add(row.Quantity)
+		lot.CostBasis = lot.CostBasis.Add(row.CostBasis)
+		locked[row.RewardID] = lot
+	}
+
+	return locked, nil
+}
+
+// loadFreeLots is loadRewardLots for one user, less whatever pending transfers
+// have locked.
+func loadFreeLots(db *gorm.DB, stockSymbol, userID string) ([]rewardLot, error) {
+	lots, err := loadRewardLots(db, stockSymbol, ownedBy(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	locked, err := lockedLots(db, stockSymbol, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	free := lots[:0]
+	for _, lot := range lots {
+		if lock, ok := locked[lot.rewardID]; ok {
+			lot.quantity = lot.quantity.Sub(lock.Quantity)
+			lot.bookValue = lot.bookValue.Sub(lock.CostBasis)
+		}
+		if lot.quantity.IsPositive() {
+			free = append(free, lot)
+		}
+	}
+
+	return free, nil
+}
+
+// GetLockedQuantities returns how many shares of each symbol the user has
+// locked in pending transfers.
+func GetLockedQuantities(db *gorm.DB, userID string) (map[string]decimal.Decimal, error) {
+	var transfers []models.DematTransfer
+	err := db.Select("stock_symbol", "quantity").
+		Where("user_id = ? AND status IN ?", userID, models.TransferPendingStatuses).
+		Find(&transfers).Error
+	if err != nil {
+		return nil, err
+	}
+
+	locked := make(map[string]decimal.Decimal)
+	for _, transfer := range transfers {
+		locked[transfer.StockSymbol] = locked[transfer.StockSymbol].Add(transfer.Quantity)
+	}
+
+	return locked, nil
+}
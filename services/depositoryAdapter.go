@@ -0,0 +1,52 @@
+package services
+
+import (
+	"assignment/decimal"
+	"assignment/initializers"
+	"fmt"
+)
+
+const (
+	DepositoryStatePending   = "PENDING"
+	DepositoryStateCompleted = "COMPLETED"
+	DepositoryStateFailed    = "FAILED"
+)
+
+// DepositoryInstruction asks the depository to move shares from the company's
+// pool account to the user's demat account.
+type DepositoryInstruction struct {
+	TransferID              string
+	ISIN                    string
+	StockSymbol             string
+	Quantity                decimal.Decimal
+	DepositoryParticipantID string
+	BeneficiaryAccount      string
+}
+
+type DepositoryStatus struct {
+	Reference string
+	State     string
+	Reason    string
+}
+
+// DepositoryAdapter submits transfer instructions to a depository and reports
+// on their progress. An error means the depository could not be reached; a
+// rejected instruction is reported as DepositoryStateFailed.
+type DepositoryAdapter interface {
+	Submit(instruction DepositoryInstruction) (DepositoryStatus, error)
+	Status(reference string) (DepositoryStatus, error)
+}
+
+func NewDepositoryAdapter(config initializers.DepositoryConfig) (DepositoryAdapter, error) {
+	switch config.Adapter {
+	case "", "sandbox":
+		return NewSandboxDepositoryAdapter(), nil
+	case "http":
+		if config.URL == "" {
+			return nil, fmt.Errorf("DEPOSITORY_URL must be set for the http depository adapter")
+		}
+		return NewHTTPDepositoryAdapter(config.URL, config.APIKey, config.Timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown depository adapter %q", config.Adapter)
+	}
+}
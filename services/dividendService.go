@@ -0,0 +1,375 @@
+package services
+
+import (
+	"assignment/decimal"
+	"assignment/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrDividendNotFound       = errors.New("dividend declaration not found")
+	ErrDividendInvalidStatus  = errors.New("dividend declaration is not in the required status")
+	ErrInvalidDividendRequest = errors.New("invalid dividend declaration")
+)
+
+func ValidateDividendDeclaration(db *gorm.DB, declaration *models.DividendDeclaration) error {
+	var count int64
+	if err := db.Model(&models.Instrument{}).Where("symbol = ?", declaration.StockSymbol).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("%w: %s", ErrUnknownInstrument, declaration.StockSymbol)
+	}
+
+	if declaration.RecordDate.Before(declaration.ExDate) {
+		return fmt.Errorf("%w: record_date cannot be before ex_date", ErrInvalidDividendRequest)
+	}
+	if declaration.PaymentDate != nil && declaration.PaymentDate.Before(declaration.RecordDate) {
+		return fmt.Errorf("%w: payment_date cannot be before record_date", ErrInvalidDividendRequest)
+	}
+
+	return nil
+}
+
+// AccrueDividend records an entitlement for every active reward lot that holds
+// the symbol as of the record date, and books the amount as receivable from
+// the issuer and payable to the user.
+func AccrueDividend(db *gorm.DB, clock Clock, dividendID uint) (*models.DividendDeclaration, error) {
+	var declaration models.DividendDeclaration
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := transitionDividend(tx, dividendID, models.DividendStatusDeclared, models.DividendStatusAccrued, "accrued_at", clock.Now(), &declaration); err != nil {
+			return err
+		}
+
+		lots, err := loadRewardLots(tx, declaration.StockSymbol, heldBefore(declaration.RecordDate))
+		if err != nil {
+			return err
+		}
+
+		referenceID := fmt.Sprintf("DIV-%d", declaration.ID)
+		symbol := declaration.StockSymbol
+		total := decimal.Zero
+
+		var entitlements []models.DividendEntitlement
+		var entries []models.LedgerEntry
+		for _, lot := range lots {
+			rewardID := lot.rewardID
+			amount := lot.quantity.Mul(declaration.AmountPerShare).RoundBank(2)
+			if !amount.IsPositive() {
+				continue
+			}
+
+			entitlements = append(entitlements, models.DividendEntitlement{
+				DividendID:     declaration.ID,
+				RewardID:       rewardID,
+				UserID:         lot.userID,
+				StockSymbol:    symbol,
+				Quantity:       lot.quantity,
+				AmountPerShare: declaration.AmountPerShare,
+				Amount:         amount,
+				Status:         models.DividendStatusAccrued,
+			})
+
+			description := fmt.Sprintf("Dividend of %s per share on %s shares of %s", declaration.AmountPerShare, lot.quantity, symbol)
+			entries = append(entries,
+				dividendEntry(rewardID, referenceID, symbol, models.AccountTypeDividendReceivable, amount, decimal.Zero, description, declaration.RecordDate),
+				dividendEntry(rewardID, referenceID, symbol, models.AccountTypeDividendPayable, decimal.Zero, amount, description, declaration.RecordDate),
+			)
+			total = total.Add(amount)
+		}
+
+		if len(entitlements) > 0 {
+			if err := tx.CreateInBatches(&entitlements, 500).Error; err != nil {
+				return fmt.Errorf("failed to record dividend entitlements: %w", err)
+			}
+			if err := tx.CreateInBatches(&entries, 500).Error; err != nil {
+				return fmt.Errorf("failed to record dividend entries: %w", err)
+			}
+		}
+
+		declaration.EntitlementsCount = len(entitlements)
+		declaration.TotalAmount = total
+
+		return tx.Model(&models.DividendDeclaration{}).Where("id = ?", declaration.ID).
+			Updates(map[string]interface{}{
+				"entitlements_count": declaration.EntitlementsCount,
+				"total_amount":       declaration.TotalAmount,
+			}).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &declaration, nil
+}
+
+// PayDividend settles every accrued entitlement of an active reward: the
+// issuer's payment clears the receivable into cash, and the payout to the
+// user clears the payable.
+func PayDividend(db *gorm.DB, clock Clock, dividendID uint) (*models.DividendDeclaration, error) {
+	var declaration models.DividendDeclaration
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := transitionDividend(tx, dividendID, models.DividendStatusAccrued, models.DividendStatusPaid, "paid_at", clock.Now(), &declaration); err != nil {
+			return err
+		}
+
+		var entitlements []models.DividendEntitlement
+		err := tx.Joins("JOIN stock_rewards ON stock_rewards.id = dividend_entitlements.reward_id").
+			Where("dividend_entitlements.dividend_id = ? AND dividend_entitlements.status = ? AND stock_rewards.status = ?",
+				declaration.ID, models.DividendStatusAccrued, models.RewardStatusActive).
+			Order("dividend_entitlements.reward_id").
+			Find(&entitlements).Error
+		if err != nil {
+			return err
+		}
+		if len(entitlements) == 0 {
+			return nil
+		}
+
+		paidAt := *declaration.PaidAt
+		referenceID := fmt.Sprintf("DIV-%d", declaration.ID)
+
+		var entries []models.LedgerEntry
+		for _, entitlement := range entitlements {
+			received := fmt.Sprintf("Dividend received from issuer for %s shares of %s", entitlement.Quantity, entitlement.StockSymbol)
+			paid := fmt.Sprintf("Dividend paid to user %s", entitlement.UserID)
+			entries = append(entries,
+				dividendEntry(entitlement.RewardID, referenceID, entitlement.StockSymbol, models.AccountTypeCashAccount, entitlement.Amount, decimal.Zero, received, paidAt),
+				dividendEntry(entitlement.RewardID, referenceID, entitlement.StockSymbol, models.AccountTypeDividendReceivable, decimal.Zero, entitlement.Amount, received, paidAt),
+				dividendEntry(entitlement.RewardID, referenceID, entitlement.StockSymbol, models.AccountTypeDividendPayable, entitlement.Amount, decimal.Zero, paid, paidAt),
+				dividendEntry(entitlement.RewardID, referenceID, entitlement.StockSymbol, models.AccountTypeCashAccount, decimal.Zero, entitlement.Amount, paid, paidAt),
+			)
+		}
+
+		if err := tx.CreateInBatches(&entries, 500).Error; err != nil {
+			return fmt.Errorf("failed to record dividend entries: %w", err)
+		}
+
+		ids := make([]uint, 0, len(entitlements))
+		for _, entitlement := range entitlements {
+			ids = append(ids, entitlement.ID)
+		}
+
+		return tx.Model(&models.DividendEntitlement{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"status":  models.DividendStatusPaid,
+				"paid_at": paidAt,
+			}).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &declaration, nil
+}
+
+// ProcessDueDividends accrues declarations whose record date has passed and
+// pays accrued declarations whose payment date has passed, as of the clock's
+// time. Each declaration is handled in its own transaction.
+func ProcessDueDividends(db *gorm.DB, clock Clock) (*models.DividendRunResult, error) {
+	now := clock.Now()
+	result := &models.DividendRunResult{
+		Accrued: []models.DividendDeclaration{},
+		Paid:    []models.DividendDeclaration{},
+	}
+
+	var due []models.DividendDeclaration
+	err := db.Where("status = ? AND record_date <= ?", models.DividendStatusDeclared, now.UTC()).
+		Order("record_date, id").
+		Find(&due).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, declaration := range due {
+		accrued, err := AccrueDividend(db, clock, declaration.ID)
+		if errors.Is(err, ErrDividendInvalidStatus) {
+			continue
+		} else if err != nil {
+			return result, fmt.Errorf("dividend %d: %w", declaration.ID, err)
+		}
+		result.Accrued = append(result.Accrued, *accrued)
+	}
+
+	due = nil
+	err = db.Where("status = ? AND payment_date IS NOT NULL AND payment_date <= ?", models.DividendStatusAccrued, now.UTC()).
+		Order("payment_date, id").
+		Find(&due).Error
+	if err != nil {
+		return result, err
+	}
+
+	for _, declaration := range due {
+		paid, err := PayDividend(db, clock, declaration.ID)
+		if errors.Is(err, ErrDividendInvalidStatus) {
+			continue
+		} else if err != nil {
+			return result, fmt.Errorf("dividend %d: %w", declaration.ID, err)
+		}
+		result.Paid = append(result.Paid, *paid)
+	}
+
+	return result, nil
+}
+
+func GetUserDividends(db *gorm.DB, userID string) (*models.UserDividendsResponse, error) {
+	var rows []struct {
+		models.DividendEntitlement
+		ExDate      time.Time
+		RecordDate  time.Time
+		PaymentDate *time.Time
+	}
+	err := db.Model(&models.DividendEntitlement{}).
+		Select("dividend_entitlements.*, dividend_declarations.ex_date, dividend_declarations.record_date, dividend_declarations.payment_date").
+		Joins("JOIN dividend_declarations ON dividend_declarations.id = dividend_entitlements.dividend_id").
+		Where("dividend_entitlements.user_id = ? AND dividend_entitlements.status <> ?", userID, models.DividendStatusCancelled).
+		Order("dividend_declarations.record_date DESC, dividend_entitlements.dividend_id DESC").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.UserDividendsResponse{
+		UserID:       userID,
+		TotalAccrued: decimal.Zero,
+		TotalPaid:    decimal.Zero,
+		Dividends:    []models.UserDividend{},
+	}
+
+	positions := make(map[uint]int)
+	for _, row := range rows {
+		i, seen := positions[row.DividendID]
+		if !seen {
+			i = len(response.Dividends)
+			positions[row.DividendID] = i
+			response.Dividends = append(response.Dividends, models.UserDividend{
+				DividendID:     row.DividendID,
+				StockSymbol:    row.StockSymbol,
+				ExDate:         row.ExDate,
+				RecordDate:     row.RecordDate,
+				PaymentDate:    row.PaymentDate,
+				AmountPerShare: row.AmountPerShare,
+				Quantity:       decimal.Zero,
+				Amount:         decimal.Zero,
+				Status:         row.Status,
+				PaidAt:         row.PaidAt,
+			})
+		}
+
+		dividend := &response.Dividends[i]
+		dividend.Quantity = dividend.Quantity.Add(row.Quantity)
+		dividend.Amount = dividend.Amount.Add(row.Amount)
+
+		if row.Status == models.DividendStatusPaid {
+			response.TotalPaid = response.TotalPaid.Add(row.Amount)
+		} else {
+			response.TotalAccrued = response.TotalAccrued.Add(row.Amount)
+		}
+	}
+
+	return response, nil
+}
+
+// cancelRewardDividends withdraws a reversed reward's unpaid entitlements. The
+// receivable and payable booked at accrual are reversed and the declarations'
+// totals stop counting them; dividends already paid are left alone. It must
+// run inside the reversal's transaction.
+func cancelRewardDividends(tx *gorm.DB, reward *models.StockReward, reason string) ([]models.LedgerEntry, error) {
+	var entitlements []models.DividendEntitlement
+	err := tx.Where("reward_id = ? AND status = ?", reward.ID, models.DividendStatusAccrued).
+		Order("dividend_id").
+		Find(&entitlements).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch dividend entitlements: %w", err)
+	}
+
+	if reward.ReversedAt == nil {
+		return nil, fmt.Errorf("reward %s has no reversal time", reward.ID)
+	}
+	effectiveAt := *reward.ReversedAt
+
+	var entries []models.LedgerEntry
+	for _, entitlement := range entitlements {
+		result := tx.Model(&models.DividendEntitlement{}).
+			Where("id = ? AND status = ?", entitlement.ID, models.DividendStatusAccrued).
+			Update("status", models.DividendStatusCancelled)
+		if result.Error != nil {
+			return nil, fmt.Errorf("failed to cancel dividend entitlement: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		err := tx.Model(&models.DividendDeclaration{}).Where("id = ?", entitlement.DividendID).
+			Updates(map[string]interface{}{
+				"entitlements_count": gorm.Expr("entitlements_count - 1"),
+				"total_amount":       gorm.Expr("total_amount - ?", entitlement.Amount),
+			}).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to update dividend totals: %w", err)
+		}
+
+		referenceID := fmt.Sprintf("DIV-%d", entitlement.DividendID)
+		description := fmt.Sprintf("Reversal of dividend %s on %s shares of %s: %s", referenceID, entitlement.Quantity, entitlement.StockSymbol, reason)
+		receivable := dividendEntry(reward.ID, referenceID, entitlement.StockSymbol, models.AccountTypeDividendReceivable, decimal.Zero, entitlement.Amount, description, effectiveAt)
+		payable := dividendEntry(reward.ID, referenceID, entitlement.StockSymbol, models.AccountTypeDividendPayable, entitlement.Amount, decimal.Zero, description, effectiveAt)
+		receivable.EntryType = models.EntryTypeReversal
+		payable.EntryType = models.EntryTypeReversal
+		entries = append(entries, receivable, payable)
+	}
+
+	if len(entries) > 0 {
+		if err := tx.Create(&entries).Error; err != nil {
+			return nil, fmt.Errorf("failed to record dividend reversal entries: %w", err)
+		}
+	}
+
+	return entries, nil
+}
+
+func transitionDividend(tx *gorm.DB, dividendID uint, from, to, timestampColumn string, at time.Time, declaration *models.DividendDeclaration) error {
+	if err := tx.Where("id = ?", dividendID).First(declaration).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: %d", ErrDividendNotFound, dividendID)
+		}
+		return err
+	}
+
+	result := tx.Model(&models.DividendDeclaration{}).
+		Where("id = ? AND status = ?", dividendID, from).
+		Updates(map[string]interface{}{
+			"status":        to,
+			timestampColumn: at,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: dividend %d is %s, expected %s", ErrDividendInvalidStatus, dividendID, declaration.Status, from)
+	}
+
+	return tx.Where("id = ?", dividendID).First(declaration).Error
+}
+
+func dividendEntry(rewardID, referenceID, symbol, accountType string, debit, credit decimal.Decimal, description string, effectiveAt time.Time) models.LedgerEntry {
+	return models.LedgerEntry{
+		RewardID:     rewardID,
+		EntryType:    models.EntryTypeDividend,
+		AccountType:  accountType,
+		StockSymbol:  &symbol,
+		DebitAmount:  debit,
+		CreditAmount: credit,
+		Description:  description,
+		ReferenceID:  referenceID,
+		EffectiveAt:  effectiveAt,
+	}
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"assignment/decimal"
+	"assignment/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrNoFeeSchedule = errors.New("no fee schedule in effect")
+
+func DefaultFeeSchedule() models.FeeSchedule {
+	return models.FeeSchedule{
+		Name:            "Default schedule",
+		EffectiveFrom:   time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		BrokerageRate:   decimal.RequireFromString("0.0003"),
+		BrokerageMin:    decimal.Zero,
+		BrokerageMax:    decimal.Zero,
+		STTRate:         decimal.RequireFromString("0.001"),
+		GSTRate:         decimal.RequireFromString("0.18"),
+		StampDutyRate:   decimal.Zero,
+		ExchangeTxnRate: decimal.Zero,
+		SEBIFeeRate:     decimal.Zero,
+	}
+}
+
+func SeedFeeSchedules(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&models.FeeSchedule{}).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to count fee schedules: %v", err)
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	schedule := DefaultFeeSchedule()
+	if err := db.Create(&schedule).Error; err != nil {
+		return fmt.Errorf("failed to seed fee schedule: %v", err)
+	}
+
+	return nil
+}
+
+func GetEffectiveFeeSchedule(db *gorm.DB, at time.Time, exchange, instrumentType string) (*models.FeeSchedule, error) {
+	var schedule models.FeeSchedule
+	err := db.Where("effective_from <= ?", at.UTC()).
+		Where("exchange = ? OR exchange = ''", exchange).
+		Where("instrument_type = ? OR instrument_type = ''", instrumentType).
+		Order("effective_from DESC, exchange DESC, instrument_type DESC, id DESC").
+		First(&schedule).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w at %s for %s %s", ErrNoFeeSchedule, at.UTC().Format(time.RFC3339), exchange, instrumentType)
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &schedule, nil
+}
+
+// FeeScheduleSet holds every schedule in GetEffectiveFeeSchedule's precedence
+// order so bulk callers can resolve schedules without a query per reward.
+type FeeScheduleSet []models.FeeSchedule
+
+func LoadFeeSchedules(db *gorm.DB) (FeeScheduleSet, error) {
+	var schedules []models.FeeSchedule
+	err := db.Order("effective_from DESC, exchange DESC, instrument_type DESC, id DESC").
+		Find(&schedules).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return schedules, nil
+}
+
+func (s FeeScheduleSet) Effective(at time.Time, exchange, instrumentType string) (*models.FeeSchedule, error) {
+	for i := range s {
+		schedule := &s[i]
+		if schedule.EffectiveFrom.After(at) {
+			continue
+		}
+		if schedule.Exchange != "" && schedule.Exchange != exchange {
+			continue
+		}
+		if schedule.InstrumentType != "" && schedule.InstrumentType != instrumentType {
+			continue
+		}
+		return schedule, nil
+	}
+
+	return nil, fmt.Errorf("%w at %s for %s %s", ErrNoFeeSchedule, at.UTC().Format(time.RFC3339), exchange, instrumentType)
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"assignment/decimal"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+type FilePriceProvider struct {
+	prices  map[string][]decimal.Decimal
+	cursors map[string]int
+	mutex   sync.Mutex
+}
+
+type priceQuote struct {
+	Symbol string          `json:"symbol"`
+	Price  decimal.Decimal `json:"price"`
+}
+
+func NewFilePriceProvider(path string) (*FilePriceProvider, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open price file: %v", err)
+	}
+	defer file.Close()
+
+	var quotes []priceQuote
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		quotes, err = readJSONQuotes(file)
+	case ".csv":
+		quotes, err = readCSVQuotes(file)
+	default:
+		return nil, fmt.Errorf("unsupported price file format %q", filepath.Ext(path))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read price file %s: %v", path, err)
+	}
+
+	if len(quotes) == 0 {
+		return nil, fmt.Errorf("price file %s contains no prices", path)
+	}
+
+	provider := &FilePriceProvider{
+		prices:  make(map[string][]decimal.Decimal),
+		cursors: make(map[string]int),
+	}
+
+	for _, quote := range quotes {
+		symbol := strings.ToUpper(strings.TrimSpace(quote.Symbol))
+		if symbol == "" || !quote.Price.IsPositive() {
+			return nil, fmt.Errorf("invalid price %v for symbol %q in %s", quote.Price, quote.Symbol, path)
+		}
+		provider.prices[symbol] = append(provider.prices[symbol], quote.Price)
+	}
+
+	return provider, nil
+}
+
+func readJSONQuotes(reader io.Reader) ([]priceQuote, error) {
+	var quotes []priceQuote
+	if err := json.NewDecoder(reader).Decode(&quotes); err != nil {
+		return nil, err
+	}
+	return quotes, nil
+}
+
+func readCSVQuotes(reader io.Reader) ([]priceQuote, error) {
+	records, err := csv.NewReader(reader).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var quotes []priceQuote
+	for i, record := range records {
+		if len(record) < 2 {
+			return nil, fmt.Errorf("line %d: expected symbol,price", i+1)
+		}
+		if i == 0 && strings.EqualFold(strings.TrimSpace(record[0]), "symbol") {
+			continue
+		}
+
+		price, err := decimal.NewFromString(record[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid price %q", i+1, record[1])
+		}
+		quotes = append(quotes, priceQuote{Symbol: record[0], Price: price})
+	}
+
+	return quotes, nil
+}
+
+func (p *FilePriceProvider) GetPrice(stockSymbol string) (decimal.Decimal, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	prices, exists := p.prices[stockSymbol]
+	if !exists {
+		return decimal.Zero, fmt.Errorf("no replay prices for symbol %s", stockSymbol)
+	}
+
+	cursor := p.cursors[stockSymbol]
+	p.cursors[stockSymbol] = (cursor + 1) % len(prices)
+
+	return prices[cursor], nil
+}
+
+func (p *FilePriceProvider) Symbols() []string {
+	symbols := make(map[string]bool, len(p.prices))
+	for symbol := range p.prices {
+		symbols[symbol] = true
+	}
+	return sortedSymbols(symbols)
+}
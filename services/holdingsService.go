@@ -0,0 +1,259 @@
+package services
+
+import (
+	"assignment/decimal"
+	"assignment/models"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// userStockAssetEntries scopes ledger_entries to the STOCK_ASSET entries of
+// the user's active rewards.
+func userStockAssetEntries(db *gorm.DB, userID string) *gorm.DB {
+	return db.Table("ledger_entries").
+		Joins("JOIN stock_rewards ON stock_rewards.id = ledger_entries.reward_id").
+		Where("stock_rewards.user_id = ? AND stock_rewards.status = ?", userID, models.RewardStatusActive).
+		Where("ledger_entries.account_type = ?", models.AccountTypeStockAsset)
+}
+
+// GetHoldingMovements returns the STOCK_ASSET quantity changes of a user's
+// active reward lots in the order they took effect. A zero before returns
+// every movement.
+func GetHoldingMovements(db *gorm.DB, userID string, before time.Time) ([]models.HoldingMovement, error) {
+	query := userStockAssetEntries(db, userID).
+		Select("ledger_entries.reward_id, ledger_entries.stock_symbol, ledger_entries.entry_type, ledger_entries.quantity, " +
+			"ledger_entries.debit_amount, ledger_entries.credit_amount, ledger_entries.effective_at, stock_rewards.stock_price_at_reward")
+
+	if !before.IsZero() {
+		query = query.Where("ledger_entries.effective_at < ?", before.UTC())
+	}
+
+	var movements []models.HoldingMovement
+	err := query.Order("ledger_entries.effective_at ASC, ledger_entries.id ASC").
+		Scan(&movements).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return movements, nil
+}
+
+// GetUserHoldings returns the user's net quantity per symbol, after reversals
+// and corporate actions, sorted by symbol. Symbols with nothing left are
+// omitted.
+func GetUserHoldings(db *gorm.DB, userID string) ([]models.Holding, error) {
+	var totals []models.Holding
+	err := userStockAssetEntries(db, userID).
+		Select("ledger_entries.stock_symbol, SUM(ledger_entries.quantity) AS quantity").
+		Group("ledger_entries.stock_symbol").
+		Order("ledger_entries.stock_symbol").
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+
+	holdings := make([]models.Holding, 0, len(totals))
+	for _, total := range totals {
+		total.Quantity = total.Quantity.RoundBank(quantityScale)
+		if !total.Quantity.IsZero() {
+			holdings = append(holdings, total)
+		}
+	}
+
+	return holdings, nil
+}
+
+// MovementPrice is the price implied by a movement that brought shares in at
+// a cost, or zero for movements that carry no price (splits and bonuses).
+func MovementPrice(movement models.HoldingMovement) decimal.Decimal {
+	if movement.EntryType == models.EntryTypeReward {
+		return movement.StockPriceAtReward
+	}
+	if movement.Quantity.IsPositive() && movement.DebitAmount.IsPositive() {
+		return movement.DebitAmount.Div(movement.Quantity, 4)
+	}
+	return decimal.Zero
+}
+
+type rewardLot struct {
+	rewardID   string
+	userID     string
+	acquiredAt time.Time
+	quantity   decimal.Decimal
+	bookValue  decimal.Decimal
+}
+
+func heldBefore(at time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("ledger_entries.effective_at < ?", at.UTC())
+	}
+}
+
+// rewardedBefore keeps the entries of rewards granted before at, whatever
+// their own effective dates.
+func rewardedBefore(at time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("stock_rewards.reward_timestamp < ?", at.UTC())
+	}
+}
+
+func ownedBy(userID string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("stock_rewards.user_id = ?", userID)
+	}
+}
+
+// loadRewardLots returns the active reward lots that hold a positive quantity
+// of the symbol, oldest reward first. Quantity and book value are summed from
+// the lot's STOCK_ASSET entries.
+func loadRewardLots(db *gorm.DB, stockSymbol string, scopes ...func(*gorm.DB) *gorm.DB) ([]rewardLot, error) {
+	var entries []struct {
+		models.LedgerEntry
+		UserID          string
+		RewardTimestamp time.Time
+	}
+	err := db.Model(&models.LedgerEntry{}).
+		Select("ledger_entries.*, stock_rewards.user_id, stock_rewards.reward_timestamp").
+		Joins("JOIN stock_rewards ON stock_rewards.id = ledger_entries.reward_id").
+		Where("stock_rewards.status = ?", models.RewardStatusActive).
+		Where("ledger_entries.account_type = ? AND ledger_entries.stock_symbol = ?", models.AccountTypeStockAsset, stockSymbol).
+		Scopes(scopes...).
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byReward := make(map[string]*rewardLot)
+	for _, entry := range entries {
+		lot, ok := byReward[entry.RewardID]
+		if !ok {
+			lot = &rewardLot{rewardID: entry.RewardID, userID: entry.UserID, acquiredAt: entry.RewardTimestamp}
+			byReward[entry.RewardID] = lot
+		}
+		if entry.Quantity != nil {
+			lot.quantity = lot.quantity.Add(*entry.Quantity)
+		}
+		lot.bookValue = lot.bookValue.Add(entry.DebitAmount).Sub(entry.CreditAmount)
+	}
+
+	lots := make([]rewardLot, 0, len(byReward))
+	for _, lot := range byReward {
+		if lot.quantity.IsPositive() {
+			lots = append(lots, *lot)
+		}
+	}
+
+	sort.Slice(lots, func(i, j int) bool {
+		if !lots[i].acquiredAt.Equal(lots[j].acquiredAt) {
+			return lots[i].acquiredAt.Before(lots[j].acquiredAt)
+		}
+		return lots[i].rewardID < lots[j].rewardID
+	})
+
+	return lots, nil
+}
+
+var chargeAccountTypes = []string{
+	models.AccountTypeBrokerageExp,
+	models.AccountTypeSTTExp,
+	models.AccountTypeGSTExp,
+	models.AccountTypeStampDutyExp,
+	models.AccountTypeExchangeExp,
+	models.AccountTypeSEBIFeesExp,
+}
+
+// GetUserPositions returns the user's holdings with their cost. BookValue is
+// the STOCK_ASSET balance of each lot; Charges are the company-borne fees of
+// each reward, allocated to wherever its shares are now in proportion to book
+// value. OpeningQuantity is what was held before since, less anything
+// redeemed or transferred out since, and CarriedQuantity is the part of
+// Quantity that did not arrive as a new reward on or after it.
+// LockedQuantity is the part held back by pending demat transfers.
+func GetUserPositions(db *gorm.DB, userID string, since time.Time) ([]models.Position, error) {
+	var totals []models.Position
+	err := userStockAssetEntries(db, userID).
+		Select("ledger_entries.stock_symbol, "+
+			"SUM(ledger_entries.quantity) AS quantity, "+
+			"SUM(ledger_entries.debit_amount) - SUM(ledger_entries.credit_amount) AS book_value, "+
+			"SUM(CASE WHEN ledger_entries.effective_at < ? OR ledger_entries.entry_type IN ? "+
+			"THEN ledger_entries.quantity ELSE 0 END) AS opening_quantity, "+
+			"SUM(CASE WHEN ledger_entries.effective_at < ? OR ledger_entries.entry_type <> ? "+
+			"THEN ledger_entries.quantity ELSE 0 END) AS carried_quantity",
+			since.UTC(), []string{models.EntryTypeRedemption, models.EntryTypeTransfer}, since.UTC(), models.EntryTypeReward).
+		Group("ledger_entries.stock_symbol").
+		Order("ledger_entries.stock_symbol").
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+
+	charges, err := allocatePositionCharges(db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	locked, err := GetLockedQuantities(db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make([]models.Position, 0, len(totals))
+	for _, position := range totals {
+		position.Quantity = position.Quantity.RoundBank(quantityScale)
+		if position.Quantity.IsZero() {
+			continue
+		}
+		position.OpeningQuantity = position.OpeningQuantity.RoundBank(quantityScale)
+		position.CarriedQuantity = position.CarriedQuantity.RoundBank(quantityScale)
+		position.BookValue = position.BookValue.RoundBank(ledgerAmountScale)
+		position.Charges = charges[position.StockSymbol]
+		position.LockedQuantity = locked[position.StockSymbol]
+		positions = append(positions, position)
+	}
+
+	return positions, nil
+}
+
+// allocatePositionCharges sums, per symbol, each reward's charges in
+// proportion to the part of its original cost now held in that symbol.
+func allocatePositionCharges(db *gorm.DB, userID string) (map[string]decimal.Decimal, error) {
+	lots := userStockAssetEntries(db, userID).
+		Select("ledger_entries.reward_id, ledger_entries.stock_symbol, " +
+			"SUM(ledger_entries.debit_amount) - SUM(ledger_entries.credit_amount) AS book_value").
+		Group("ledger_entries.reward_id, ledger_entries.stock_symbol")
+
+	costs := db.Table("ledger_entries").
+		Select("ledger_entries.reward_id, "+
+			"SUM(CASE WHEN ledger_entries.account_type = ? THEN ledger_entries.debit_amount ELSE 0 END) AS cost, "+
+			"SUM(CASE WHEN ledger_entries.account_type IN ? "+
+			"THEN ledger_entries.debit_amount - ledger_entries.credit_amount ELSE 0 END) AS charges",
+			models.AccountTypeStockAsset, chargeAccountTypes).
+		Joins("JOIN stock_rewards ON stock_rewards.id = ledger_entries.reward_id").
+		Where("stock_rewards.user_id = ? AND stock_rewards.status = ?", userID, models.RewardStatusActive).
+		Where("ledger_entries.entry_type = ?", models.EntryTypeReward).
+		Group("ledger_entries.reward_id")
+
+	// Multiplying by 1.0 keeps SQLite from truncating integer division.
+	var rows []struct {
+		StockSymbol string
+		Charges     decimal.Decimal
+	}
+	err := db.Table("(?) AS lots", lots).
+		Select("lots.stock_symbol, SUM(costs.charges * lots.book_value * 1.0 / costs.cost) AS charges").
+		Joins("JOIN (?) AS costs ON costs.reward_id = lots.reward_id", costs).
+		Where("costs.cost > 0").
+		Group("lots.stock_symbol").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	charges := make(map[string]decimal.Decimal, len(rows))
+	for _, row := range rows {
+		charges[row.StockSymbol] = row.Charges.RoundBank(ledgerAmountScale)
+	}
+
+	return charges, nil
+}
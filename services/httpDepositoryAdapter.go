@@ -0,0 +1,114 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// HTTPDepositoryAdapter talks to a depository gateway that accepts
+// instructions at POST {base}/instructions and reports on them at
+// GET {base}/instructions/{reference}.
+type HTTPDepositoryAdapter struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+type depositoryInstructionBody struct {
+	TransferID              string `json:"transfer_id"`
+	ISIN                    string `json:"isin"`
+	StockSymbol             string `json:"stock_symbol"`
+	Quantity                string `json:"quantity"`
+	DepositoryParticipantID string `json:"dp_id"`
+	BeneficiaryAccount      string `json:"client_id"`
+}
+
+type depositoryStatusBody struct {
+	Reference string `json:"reference"`
+	State     string `json:"state"`
+	Reason    string `json:"reason"`
+}
+
+func NewHTTPDepositoryAdapter(baseURL, apiKey string, timeout time.Duration) *HTTPDepositoryAdapter {
+	return &HTTPDepositoryAdapter{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+func (a *HTTPDepositoryAdapter) Submit(instruction DepositoryInstruction) (DepositoryStatus, error) {
+	body, err := json.Marshal(depositoryInstructionBody{
+		TransferID:              instruction.TransferID,
+		ISIN:                    instruction.ISIN,
+		StockSymbol:             instruction.StockSymbol,
+		Quantity:                trimZeros(instruction.Quantity),
+		DepositoryParticipantID: instruction.DepositoryParticipantID,
+		BeneficiaryAccount:      instruction.BeneficiaryAccount,
+	})
+	if err != nil {
+		return DepositoryStatus{}, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, a.baseURL+"/instructions", bytes.NewReader(body))
+	if err != nil {
+		return DepositoryStatus{}, fmt.Errorf("failed to build depository request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	status, err := a.do(req, instruction.TransferID)
+	if err != nil {
+		return DepositoryStatus{}, err
+	}
+	// The reference is the only way to ask about the instruction later.
+	if status.Reference == "" {
+		return DepositoryStatus{}, fmt.Errorf("depository returned no reference for %s", instruction.TransferID)
+	}
+
+	return status, nil
+}
+
+func (a *HTTPDepositoryAdapter) Status(reference string) (DepositoryStatus, error) {
+	req, err := http.NewRequest(http.MethodGet, a.baseURL+"/instructions/"+url.PathEscape(reference), nil)
+	if err != nil {
+		return DepositoryStatus{}, fmt.Errorf("failed to build depository request: %v", err)
+	}
+
+	return a.do(req, reference)
+}
+
+func (a *HTTPDepositoryAdapter) do(req *http.Request, subject string) (DepositoryStatus, error) {
+	req.Header.Set("Accept", "application/json")
+	if a.apiKey != "" {
+		req.Header.Set("X-API-Key", a.apiKey)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return DepositoryStatus{}, fmt.Errorf("failed to reach depository for %s: %v", subject, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return DepositoryStatus{}, fmt.Errorf("depository returned status %d for %s", resp.StatusCode, subject)
+	}
+
+	var status depositoryStatusBody
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return DepositoryStatus{}, fmt.Errorf("failed to decode depository response for %s: %v", subject, err)
+	}
+
+	state := strings.ToUpper(status.State)
+	switch state {
+	case DepositoryStatePending, DepositoryStateCompleted, DepositoryStateFailed:
+	default:
+		return DepositoryStatus{}, fmt.Errorf("depository returned unknown state %q for %s", status.State, subject)
+	}
+
+	return DepositoryStatus{Reference: status.Reference, State: state, Reason: status.Reason}, nil
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"assignment/decimal"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type HTTPPriceProvider struct {
+	feedURL string
+	apiKey  string
+	symbols []string
+	client  *http.Client
+}
+
+func NewHTTPPriceProvider(feedURL, apiKey string, timeout time.Duration, symbols []string) *HTTPPriceProvider {
+	return &HTTPPriceProvider{
+		feedURL: feedURL,
+		apiKey:  apiKey,
+		symbols: symbols,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+func (p *HTTPPriceProvider) GetPrice(stockSymbol string) (decimal.Decimal, error) {
+	quoteURL, err := url.Parse(p.feedURL)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid price feed URL: %v", err)
+	}
+
+	query := quoteURL.Query()
+	query.Set("symbol", stockSymbol)
+	quoteURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, quoteURL.String(), nil)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to build quote request: %v", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("X-API-Key", p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to fetch quote for %s: %v", stockSymbol, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return decimal.Zero, fmt.Errorf("quote feed returned status %d for %s", resp.StatusCode, stockSymbol)
+	}
+
+	var quote priceQuote
+	if err := json.NewDecoder(resp.Body).Decode(&quote); err != nil {
+		return decimal.Zero, fmt.Errorf("failed to decode quote for %s: %v", stockSymbol, err)
+	}
+
+	if !quote.Price.IsPositive() {
+		return decimal.Zero, fmt.Errorf("quote feed returned invalid price %v for %s", quote.Price, stockSymbol)
+	}
+
+	return quote.Price, nil
+}
+
+func (p *HTTPPriceProvider) Symbols() []string {
+	return p.symbols
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"assignment/models"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrUnknownInstrument   = errors.New("unknown stock symbol")
+	ErrInstrumentNotActive = errors.New("stock symbol is not active")
+)
+
+var instrumentSeeds = []models.Instrument{
+	{Symbol: "RELIANCE", ISIN: "INE002A01018", Exchange: "NSE", Name: "Reliance Industries Ltd"},
+	{Symbol: "TCS", ISIN: "INE467B01029", Exchange: "NSE", Name: "Tata Consultancy Services Ltd"},
+	{Symbol: "INFOSYS", ISIN: "INE009A01021", Exchange: "NSE", Name: "Infosys Ltd"},
+	{Symbol: "HDFC", ISIN: "INE001A01036", Exchange: "NSE", Name: "Housing Development Finance Corporation Ltd"},
+	{Symbol: "WIPRO", ISIN: "INE075A01022", Exchange: "NSE", Name: "Wipro Ltd"},
+	{Symbol: "ITC", ISIN: "INE154A01025", Exchange: "NSE", Name: "ITC Ltd"},
+	{Symbol: "BHARTI", ISIN: "INE397D01024", Exchange: "NSE", Name: "Bharti Airtel Ltd"},
+	{Symbol: "SBIN", ISIN: "INE062A01020", Exchange: "NSE", Name: "State Bank of India"},
+	{Symbol: "HDFCBANK", ISIN: "INE040A01034", Exchange: "NSE", Name: "HDFC Bank Ltd"},
+	{Symbol: "ICICIBANK", ISIN: "INE090A01021", Exchange: "NSE", Name: "ICICI Bank Ltd"},
+}
+
+func SeedInstruments(db *gorm.DB) error {
+	seeds := make([]models.Instrument, 0, len(instrumentSeeds))
+	for _, seed := range instrumentSeeds {
+		if _, exists := stockPriceRanges[seed.Symbol]; !exists {
+			return fmt.Errorf("instrument seed %s has no price range", seed.Symbol)
+		}
+		seed.LotSize = 1
+		seed.InstrumentType = models.InstrumentTypeEquity
+		seed.Status = models.InstrumentStatusActive
+		seeds = append(seeds, seed)
+	}
+
+	err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&seeds).Error
+	if err != nil {
+		return fmt.Errorf("failed to seed instruments: %v", err)
+	}
+
+	return nil
+}
+
+func GetTradableInstrument(db *gorm.DB, stockSymbol string) (*models.Instrument, error) {
+	var instrument models.Instrument
+	err := db.Where("symbol = ?", stockSymbol).First(&instrument).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownInstrument, stockSymbol)
+	} else if err != nil {
+		return nil, err
+	}
+
+	if instrument.Status != models.InstrumentStatusActive {
+		return nil, fmt.Errorf("%w: %s is %s", ErrInstrumentNotActive, stockSymbol, instrument.Status)
+	}
+
+	return &instrument, nil
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"assignment/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultLedgerPageSize = 50
+	ledgerAmountScale     = 4
+)
+
+// imbalanceTolerance is half of the smallest stored amount, so float noise
+// from SUM on databases without exact numerics is not reported as an
+// imbalance.
+const imbalanceTolerance = 0.00005
+
+func ListLedgerEntries(db *gorm.DB, query models.LedgerEntryQuery) (*models.LedgerEntriesResponse, error) {
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.PageSize == 0 {
+		query.PageSize = defaultLedgerPageSize
+	}
+
+	scope := db.Model(&models.LedgerEntry{})
+	if query.RewardID != "" {
+		scope = scope.Where("reward_id = ?", query.RewardID)
+	}
+	if query.AccountType != "" {
+		scope = scope.Where("account_type = ?", query.AccountType)
+	}
+	if query.StockSymbol != "" {
+		scope = scope.Where("stock_symbol = ?", query.StockSymbol)
+	}
+	if !query.From.IsZero() {
+		scope = scope.Where("effective_at >= ?", query.From.UTC())
+	}
+	if !query.To.IsZero() {
+		scope = scope.Where("effective_at < ?", query.To.UTC())
+	}
+
+	var total int64
+	if err := scope.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	entries := []models.LedgerEntry{}
+	err := scope.Order("id").
+		Offset((query.Page - 1) * query.PageSize).
+		Limit(query.PageSize).
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.LedgerEntriesResponse{
+		Entries:    entries,
+		Page:       query.Page,
+		PageSize:   query.PageSize,
+		TotalCount: total,
+	}, nil
+}
+
+func GetAccountBalances(db *gorm.DB, asOf time.Time) ([]models.AccountBalance, error) {
+	var balances []models.AccountBalance
+	err := db.Model(&models.LedgerEntry{}).
+		Select("account_type, SUM(debit_amount) AS total_debit, SUM(credit_amount) AS total_credit").
+		Where("effective_at <= ?", asOf.UTC()).
+		Group("account_type").
+		Order("account_type").
+		Scan(&balances).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range balances {
+		balances[i].TotalDebit = balances[i].TotalDebit.RoundBank(ledgerAmountScale)
+		balances[i].TotalCredit = balances[i].TotalCredit.RoundBank(ledgerAmountScale)
+		balances[i].Balance = balances[i].TotalDebit.Sub(balances[i].TotalCredit)
+	}
+
+	if balances == nil {
+		balances = []models.AccountBalance{}
+	}
+
+	return balances, nil
+}
+
+func GetTrialBalance(db *gorm.DB, asOf time.Time) (*models.TrialBalanceResponse, error) {
+	balances, err := GetAccountBalances(db, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	trialBalance := &models.TrialBalanceResponse{AsOf: asOf}
+	for _, balance := range balances {
+		trialBalance.TotalDebit = trialBalance.TotalDebit.Add(balance.TotalDebit)
+		trialBalance.TotalCredit = trialBalance.TotalCredit.Add(balance.TotalCredit)
+	}
+
+	var imbalances []models.RewardImbalance
+	err = db.Model(&models.LedgerEntry{}).
+		Select("reward_id, SUM(debit_amount) AS total_debit, SUM(credit_amount) AS total_credit").
+		Where("effective_at <= ?", asOf.UTC()).
+		Group("reward_id").
+		Having("ABS(SUM(debit_amount) - SUM(credit_amount)) > ?", imbalanceTolerance).
+		Order("reward_id").
+		Scan(&imbalances).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range imbalances {
+		imbalances[i].TotalDebit = imbalances[i].TotalDebit.RoundBank(ledgerAmountScale)
+		imbalances[i].TotalCredit = imbalances[i].TotalCredit.RoundBank(ledgerAmountScale)
+		imbalances[i].Difference = imbalances[i].TotalDebit.Sub(imbalances[i].TotalCredit)
+	}
+
+	if imbalances == nil {
+		imbalances = []models.RewardImbalance{}
+	}
+
+	trialBalance.UnbalancedRewards = imbalances
+	trialBalance.Balanced = trialBalance.TotalDebit.Equal(trialBalance.TotalCredit) && len(imbalances) == 0
+
+	return trialBalance, nil
+}
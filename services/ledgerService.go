@@ -1,29 +1,94 @@
 package services
 
 import (
+	"assignment/decimal"
 	"assignment/models"
 	"fmt"
+	"strings"
 
 	"gorm.io/gorm"
 )
 
-func CalculateCompanyCharges(stockCost float64) *models.CompanyCharges {
-	brokerage := stockCost * 0.0003
-	stt := stockCost * 0.001
-	gst := brokerage * 0.18
+var hundred = decimal.NewFromInt(100)
 
-	totalCost := stockCost + brokerage + stt + gst
+func CalculateCompanyCharges(stockCost decimal.Decimal, schedule *models.FeeSchedule) *models.CompanyCharges {
+	stockCost = stockCost.RoundBank(2)
+
+	brokerage := stockCost.Mul(schedule.BrokerageRate).RoundBank(2)
+	if schedule.BrokerageMin.IsPositive() && brokerage.LessThan(schedule.BrokerageMin) {
+		brokerage = schedule.BrokerageMin.RoundBank(2)
+	}
+	if schedule.BrokerageMax.IsPositive() && brokerage.GreaterThan(schedule.BrokerageMax) {
+		brokerage = schedule.BrokerageMax.RoundBank(2)
+	}
+
+	stt := stockCost.Mul(schedule.STTRate).RoundBank(2)
+	exchangeCharges := stockCost.Mul(schedule.ExchangeTxnRate).RoundBank(2)
+	sebiFees := stockCost.Mul(schedule.SEBIFeeRate).RoundBank(2)
+	stampDuty := stockCost.Mul(schedule.StampDutyRate).RoundBank(2)
+	gst := brokerage.Add(exchangeCharges).Add(sebiFees).Mul(schedule.GSTRate).RoundBank(2)
+
+	totalCost := stockCost.Add(brokerage).Add(stt).Add(exchangeCharges).Add(sebiFees).Add(stampDuty).Add(gst)
 
 	return &models.CompanyCharges{
-		StockCost: stockCost,
-		Brokerage: float64(int(brokerage*100)) / 100,
-		STT:       float64(int(stt*100)) / 100,
-		GST:       float64(int(gst*100)) / 100,
-		TotalCost: float64(int(totalCost*100)) / 100,
+		FeeScheduleID:   schedule.ID,
+		StockCost:       stockCost,
+		Brokerage:       brokerage,
+		STT:             stt,
+		ExchangeCharges: exchangeCharges,
+		SEBIFees:        sebiFees,
+		StampDuty:       stampDuty,
+		GST:             gst,
+		TotalCost:       totalCost,
 	}
 }
 
-func RecordLedgerEntriesGORM(tx *gorm.DB, reward *models.StockReward, charges *models.CompanyCharges) error {
+// CalculateSaleCharges applies the sell side of a fee schedule to sale
+// proceeds. Stamp duty is only levied on purchases.
+func CalculateSaleCharges(grossAmount decimal.Decimal, schedule *models.FeeSchedule) *models.SaleCharges {
+	grossAmount = grossAmount.RoundBank(2)
+
+	brokerage := grossAmount.Mul(schedule.BrokerageRate).RoundBank(2)
+	if schedule.BrokerageMin.IsPositive() && brokerage.LessThan(schedule.BrokerageMin) {
+		brokerage = schedule.BrokerageMin.RoundBank(2)
+	}
+	if schedule.BrokerageMax.IsPositive() && brokerage.GreaterThan(schedule.BrokerageMax) {
+		brokerage = schedule.BrokerageMax.RoundBank(2)
+	}
+
+	stt := grossAmount.Mul(schedule.STTRate).RoundBank(2)
+	exchangeCharges := grossAmount.Mul(schedule.ExchangeTxnRate).RoundBank(2)
+	sebiFees := grossAmount.Mul(schedule.SEBIFeeRate).RoundBank(2)
+	gst := brokerage.Add(exchangeCharges).Add(sebiFees).Mul(schedule.GSTRate).RoundBank(2)
+
+	totalCharges := brokerage.Add(stt).Add(exchangeCharges).Add(sebiFees).Add(gst)
+
+	return &models.SaleCharges{
+		FeeScheduleID:   schedule.ID,
+		GrossAmount:     grossAmount,
+		Brokerage:       brokerage,
+		STT:             stt,
+		ExchangeCharges: exchangeCharges,
+		SEBIFees:        sebiFees,
+		GST:             gst,
+		TotalCharges:    totalCharges,
+		NetAmount:       grossAmount.Sub(totalCharges),
+	}
+}
+
+func formatRate(rate decimal.Decimal) string {
+	return trimZeros(rate.Mul(hundred)) + "%"
+}
+
+func trimZeros(value decimal.Decimal) string {
+	formatted := value.String()
+	if strings.Contains(formatted, ".") {
+		formatted = strings.TrimRight(strings.TrimRight(formatted, "0"), ".")
+	}
+	return formatted
+}
+
+func RecordLedgerEntriesGORM(tx *gorm.DB, reward *models.StockReward, charges *models.CompanyCharges, schedule *models.FeeSchedule) error {
 	totalCashOutflow := charges.TotalCost
 
 	entries := []models.LedgerEntry{
@@ -32,47 +97,124 @@ func RecordLedgerEntriesGORM(tx *gorm.DB, reward *models.StockReward, charges *m
 			AccountType:  models.AccountTypeStockAsset,
 			StockSymbol:  &reward.StockSymbol,
 			DebitAmount:  charges.StockCost,
-			CreditAmount: 0,
+			CreditAmount: decimal.Zero,
 			Quantity:     &reward.Quantity,
-			Description:  fmt.Sprintf("Stock acquired: %.6f shares of %s at ₹%.2f", reward.Quantity, reward.StockSymbol, reward.StockPriceAtReward),
+			Description:  fmt.Sprintf("Stock acquired: %s shares of %s at ₹%s", reward.Quantity.StringFixed(6), reward.StockSymbol, reward.StockPriceAtReward.StringFixed(2)),
 		},
 		{
 			RewardID:     reward.ID,
 			AccountType:  models.AccountTypeBrokerageExp,
 			StockSymbol:  &reward.StockSymbol,
 			DebitAmount:  charges.Brokerage,
-			CreditAmount: 0,
-			Description:  fmt.Sprintf("Brokerage expense for %s (0.03%%)", reward.StockSymbol),
+			CreditAmount: decimal.Zero,
+			Description:  fmt.Sprintf("Brokerage expense for %s (%s)", reward.StockSymbol, formatRate(schedule.BrokerageRate)),
 		},
 		{
 			RewardID:     reward.ID,
 			AccountType:  models.AccountTypeSTTExp,
 			StockSymbol:  &reward.StockSymbol,
 			DebitAmount:  charges.STT,
-			CreditAmount: 0,
-			Description:  fmt.Sprintf("Securities Transaction Tax for %s (0.1%%)", reward.StockSymbol),
+			CreditAmount: decimal.Zero,
+			Description:  fmt.Sprintf("Securities Transaction Tax for %s (%s)", reward.StockSymbol, formatRate(schedule.STTRate)),
 		},
 		{
 			RewardID:     reward.ID,
 			AccountType:  models.AccountTypeGSTExp,
 			StockSymbol:  &reward.StockSymbol,
 			DebitAmount:  charges.GST,
-			CreditAmount: 0,
-			Description:  fmt.Sprintf("GST on brokerage for %s (18%%)", reward.StockSymbol),
+			CreditAmount: decimal.Zero,
+			Description:  fmt.Sprintf("GST on brokerage and transaction charges for %s (%s)", reward.StockSymbol, formatRate(schedule.GSTRate)),
 		},
-		{
+	}
+
+	optionalCharges := []struct {
+		accountType string
+		amount      decimal.Decimal
+		description string
+	}{
+		{models.AccountTypeExchangeExp, charges.ExchangeCharges, fmt.Sprintf("Exchange transaction charges for %s (%s)", reward.StockSymbol, formatRate(schedule.ExchangeTxnRate))},
+		{models.AccountTypeSEBIFeesExp, charges.SEBIFees, fmt.Sprintf("SEBI turnover fees for %s (%s)", reward.StockSymbol, formatRate(schedule.SEBIFeeRate))},
+		{models.AccountTypeStampDutyExp, charges.StampDuty, fmt.Sprintf("Stamp duty for %s (%s)", reward.StockSymbol, formatRate(schedule.StampDutyRate))},
+	}
+
+	for _, charge := range optionalCharges {
+		if charge.amount.IsZero() {
+			continue
+		}
+		entries = append(entries, models.LedgerEntry{
 			RewardID:     reward.ID,
-			AccountType:  models.AccountTypeCashAccount,
+			AccountType:  charge.accountType,
 			StockSymbol:  &reward.StockSymbol,
-			DebitAmount:  0,
-			CreditAmount: totalCashOutflow,
-			Description:  fmt.Sprintf("Cash paid for stock purchase and fees for %s", reward.StockSymbol),
-		},
+			DebitAmount:  charge.amount,
+			CreditAmount: decimal.Zero,
+			Description:  charge.description,
+		})
+	}
+
+	entries = append(entries, models.LedgerEntry{
+		RewardID:     reward.ID,
+		AccountType:  models.AccountTypeCashAccount,
+		StockSymbol:  &reward.StockSymbol,
+		DebitAmount:  decimal.Zero,
+		CreditAmount: totalCashOutflow,
+		Description:  fmt.Sprintf("Cash paid for stock purchase and fees for %s", reward.StockSymbol),
+	})
+
+	for i := range entries {
+		entries[i].EntryType = models.EntryTypeReward
+		entries[i].EffectiveAt = reward.RewardTimestamp
 	}
 
 	if err := tx.Create(&entries).Error; err != nil {
-		return fmt.Errorf("failed to record ledger entry: %v", err)
+		return fmt.Errorf("failed to record ledger entry: %w", err)
 	}
 
 	return nil
 }
+
+func RecordReversalEntriesGORM(tx *gorm.DB, reward *models.StockReward, reason string) ([]models.LedgerEntry, error) {
+	var original []models.LedgerEntry
+	err := tx.Where("reward_id = ? AND entry_type IN ?", reward.ID, []string{models.EntryTypeReward, models.EntryTypeCorporateAction}).
+		Order("id").
+		Find(&original).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch ledger entries: %w", err)
+	}
+
+	if len(original) == 0 {
+		return nil, fmt.Errorf("no ledger entries found for reward %s", reward.ID)
+	}
+
+	if reward.ReversedAt == nil {
+		return nil, fmt.Errorf("reward %s has no reversal time", reward.ID)
+	}
+	effectiveAt := *reward.ReversedAt
+
+	reversals := make([]models.LedgerEntry, 0, len(original))
+	for _, entry := range original {
+		var quantity *decimal.Decimal
+		if entry.Quantity != nil {
+			negated := entry.Quantity.Neg()
+			quantity = &negated
+		}
+
+		reversals = append(reversals, models.LedgerEntry{
+			RewardID:     entry.RewardID,
+			EntryType:    models.EntryTypeReversal,
+			AccountType:  entry.AccountType,
+			StockSymbol:  entry.StockSymbol,
+			DebitAmount:  entry.CreditAmount,
+			CreditAmount: entry.DebitAmount,
+			Quantity:     quantity,
+			Description:  fmt.Sprintf("Reversal of entry %d: %s", entry.ID, reason),
+			ReferenceID:  entry.ReferenceID,
+			EffectiveAt:  effectiveAt,
+		})
+	}
+
+	if err := tx.Create(&reversals).Error; err != nil {
+		return nil, fmt.Errorf("failed to record reversal entry: %w", err)
+	}
+
+	return reversals, nil
+}
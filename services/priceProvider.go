@@ -0,0 +1,45 @@
+package services
+
+import (
+	"assignment/decimal"
+	"assignment/initializers"
+	"fmt"
+	"sort"
+)
+
+type PriceProvider interface {
+	GetPrice(stockSymbol string) (decimal.Decimal, error)
+	Symbols() []string
+}
+
+func NewPriceProvider(config initializers.PriceProviderConfig) (PriceProvider, error) {
+	switch config.Provider {
+	case "", "random":
+		return NewRandomPriceProvider(), nil
+	case "file":
+		if config.FilePath == "" {
+			return nil, fmt.Errorf("PRICE_FILE must be set for the file price provider")
+		}
+		return NewFilePriceProvider(config.FilePath)
+	case "http":
+		if config.FeedURL == "" {
+			return nil, fmt.Errorf("PRICE_FEED_URL must be set for the http price provider")
+		}
+		symbols := config.Symbols
+		if len(symbols) == 0 {
+			symbols = NewRandomPriceProvider().Symbols()
+		}
+		return NewHTTPPriceProvider(config.FeedURL, config.APIKey, config.Timeout, symbols), nil
+	default:
+		return nil, fmt.Errorf("unknown price provider %q", config.Provider)
+	}
+}
+
+func sortedSymbols(symbols map[string]bool) []string {
+	result := make([]string, 0, len(symbols))
+	for symbol := range symbols {
+		result = append(result, symbol)
+	}
+	sort.Strings(result)
+	return result
+}
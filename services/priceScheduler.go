@@ -0,0 +1,97 @@
+package services
+
+import (
+	"assignment/initializers"
+	"context"
+	"fmt"
+	"time"
+)
+
+// PriceUpdate is the scheduler's next wake-up. Closing marks the snapshot
+// taken when Session ends.
+type PriceUpdate struct {
+	At      time.Time
+	Session DateRange
+	Closing bool
+}
+
+// NextPriceUpdate plans the wake-up after now: every interval during a
+// session, once more at its close, and otherwise not until the next open.
+func NextPriceUpdate(calendar *TradingCalendar, interval time.Duration, now time.Time) (PriceUpdate, bool) {
+	session, ok := calendar.NextSession(now)
+	if !ok {
+		return PriceUpdate{}, false
+	}
+
+	if now.Before(session.Start) {
+		return PriceUpdate{At: session.Start, Session: session}, true
+	}
+	if next := now.Add(interval); next.Before(session.End) {
+		return PriceUpdate{At: next, Session: session}, true
+	}
+	return PriceUpdate{At: session.End, Session: session, Closing: true}, true
+}
+
+// NewMarketCalendar builds the exchange calendar from configuration, loading
+// holidays from the configured file.
+func NewMarketCalendar(config initializers.TradingCalendarConfig, location *time.Location) (*TradingCalendar, error) {
+	var holidays map[string]string
+	if config.HolidaysFile != "" {
+		var err error
+		if holidays, err = LoadHolidays(config.HolidaysFile); err != nil {
+			return nil, err
+		}
+	}
+
+	return NewTradingCalendar(location, config.SessionOpen, config.SessionClose, NSETradingDays, holidays)
+}
+
+// StartPriceUpdateScheduler refreshes prices on calendar until ctx is
+// cancelled. The returned channel is closed once the scheduler has finished
+// any refresh in progress and stopped.
+func (s *PriceService) StartPriceUpdateScheduler(ctx context.Context, calendar *TradingCalendar, interval time.Duration) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.runPriceScheduler(ctx, calendar, interval)
+	}()
+
+	fmt.Println("Stock price update scheduler started")
+	return done
+}
+
+func (s *PriceService) runPriceScheduler(ctx context.Context, calendar *TradingCalendar, interval time.Duration) {
+	if now := s.clock.Now(); calendar.IsTradingDay(now) && calendar.Session(now).Contains(now) {
+		if err := s.UpdateStockPrices(); err != nil {
+			fmt.Printf("Error updating stock prices: %v\n", err)
+		}
+	}
+
+	for {
+		update, ok := NextPriceUpdate(calendar, interval, s.clock.Now())
+		if !ok {
+			fmt.Println("No trading session ahead, stock price update scheduler stopped")
+			return
+		}
+
+		timer := time.NewTimer(update.At.Sub(s.clock.Now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			fmt.Println("Stock price update scheduler stopped")
+			return
+		case <-timer.C:
+		}
+
+		if update.Closing {
+			if err := s.RecordClosingPrices(update.Session.End); err != nil {
+				fmt.Printf("Error recording closing prices: %v\n", err)
+			}
+			continue
+		}
+
+		if err := s.UpdateStockPrices(); err != nil {
+			fmt.Printf("Error updating stock prices: %v\n", err)
+		}
+	}
+}
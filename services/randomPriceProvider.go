@@ -0,0 +1,46 @@
+package services
+
+import (
+	"assignment/decimal"
+	"math/rand"
+)
+
+var stockPriceRanges = map[string]struct{ min, max float64 }{
+	"RELIANCE":  {2200.0, 2800.0},
+	"TCS":       {3200.0, 4000.0},
+	"INFOSYS":   {1400.0, 1800.0},
+	"HDFC":      {1500.0, 2000.0},
+	"WIPRO":     {400.0, 600.0},
+	"ITC":       {380.0, 480.0},
+	"BHARTI":    {800.0, 1200.0},
+	"SBIN":      {500.0, 700.0},
+	"HDFCBANK":  {1400.0, 1700.0},
+	"ICICIBANK": {900.0, 1200.0},
+}
+
+type RandomPriceProvider struct{}
+
+func NewRandomPriceProvider() *RandomPriceProvider {
+	return &RandomPriceProvider{}
+}
+
+func (p *RandomPriceProvider) GetPrice(stockSymbol string) (decimal.Decimal, error) {
+	return generateRandomPrice(stockSymbol), nil
+}
+
+func (p *RandomPriceProvider) Symbols() []string {
+	symbols := make(map[string]bool, len(stockPriceRanges))
+	for symbol := range stockPriceRanges {
+		symbols[symbol] = true
+	}
+	return sortedSymbols(symbols)
+}
+
+func generateRandomPrice(stockSymbol string) decimal.Decimal {
+	priceRange, exists := stockPriceRanges[stockSymbol]
+	if !exists {
+		priceRange = struct{ min, max float64 }{100.0, 5000.0}
+	}
+	price := priceRange.min + rand.Float64()*(priceRange.max-priceRange.min)
+	return decimal.NewFromFloat(price).RoundBank(2)
+}
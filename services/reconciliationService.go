@@ -0,0 +1,130 @@
+package services
+
+import (
+	"assignment/decimal"
+	"assignment/models"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+const reconciliationBatchSize = 500
+
+func RunReconciliation(db *gorm.DB, clock Clock) (*models.ReconciliationRun, error) {
+	run := &models.ReconciliationRun{
+		StartedAt: clock.Now(),
+		Findings:  []models.ReconciliationFinding{},
+	}
+
+	var rewards []models.StockReward
+	result := db.Order("id").FindInBatches(&rewards, reconciliationBatchSize, func(tx *gorm.DB, batch int) error {
+		rewardIDs := make([]string, len(rewards))
+		for i, reward := range rewards {
+			rewardIDs[i] = reward.ID
+		}
+
+		var entries []models.LedgerEntry
+		if err := db.Where("reward_id IN ?", rewardIDs).Find(&entries).Error; err != nil {
+			return err
+		}
+
+		entriesByReward := make(map[string][]models.LedgerEntry, len(rewards))
+		for _, entry := range entries {
+			entriesByReward[entry.RewardID] = append(entriesByReward[entry.RewardID], entry)
+		}
+
+		for i := range rewards {
+			run.Findings = append(run.Findings, checkRewardJournal(&rewards[i], entriesByReward[rewards[i].ID])...)
+		}
+
+		run.RewardsScanned += len(rewards)
+		return nil
+	})
+
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to scan rewards: %w", result.Error)
+	}
+
+	for _, finding := range run.Findings {
+		switch finding.Type {
+		case models.FindingUnbalancedJournal:
+			run.UnbalancedCount++
+		case models.FindingMissingEntries:
+			run.MissingEntriesCount++
+		case models.FindingQuantityMismatch:
+			run.QuantityMismatchCount++
+		}
+	}
+
+	run.Status = models.ReconciliationStatusClean
+	if len(run.Findings) > 0 {
+		run.Status = models.ReconciliationStatusIssuesFound
+	}
+	run.CompletedAt = clock.Now()
+
+	if err := db.Create(run).Error; err != nil {
+		return nil, fmt.Errorf("failed to record reconciliation run: %w", err)
+	}
+
+	return run, nil
+}
+
+func checkRewardJournal(reward *models.StockReward, entries []models.LedgerEntry) []models.ReconciliationFinding {
+	if len(entries) == 0 {
+		return []models.ReconciliationFinding{{
+			Type:     models.FindingMissingEntries,
+			RewardID: reward.ID,
+			Message:  "reward has no ledger entries",
+		}}
+	}
+
+	var findings []models.ReconciliationFinding
+
+	debits, credits := decimal.Zero, decimal.Zero
+	rewardQuantity, netQuantity := decimal.Zero, decimal.Zero
+	hasReversal := false
+	for _, entry := range entries {
+		debits = debits.Add(entry.DebitAmount)
+		credits = credits.Add(entry.CreditAmount)
+
+		if entry.EntryType == models.EntryTypeReversal {
+			hasReversal = true
+		}
+
+		if entry.AccountType == models.AccountTypeStockAsset && entry.Quantity != nil {
+			netQuantity = netQuantity.Add(*entry.Quantity)
+			if entry.EntryType == models.EntryTypeReward {
+				rewardQuantity = rewardQuantity.Add(*entry.Quantity)
+			}
+		}
+	}
+
+	if !debits.Equal(credits) {
+		findings = append(findings, models.ReconciliationFinding{
+			Type:     models.FindingUnbalancedJournal,
+			RewardID: reward.ID,
+			Message:  fmt.Sprintf("debits %s do not equal credits %s", debits, credits),
+		})
+	}
+
+	var mismatches []string
+	if !rewardQuantity.Equal(reward.Quantity) {
+		mismatches = append(mismatches, fmt.Sprintf("REWARD STOCK_ASSET quantity %s does not match reward quantity %s", rewardQuantity, reward.Quantity))
+	}
+	if reward.Status == models.RewardStatusReversed && !netQuantity.IsZero() {
+		mismatches = append(mismatches, fmt.Sprintf("REVERSED reward still holds STOCK_ASSET quantity %s", netQuantity))
+	}
+	if reward.Status != models.RewardStatusReversed && hasReversal {
+		mismatches = append(mismatches, fmt.Sprintf("unexpected REVERSAL entries on %s reward", reward.Status))
+	}
+
+	for _, message := range mismatches {
+		findings = append(findings, models.ReconciliationFinding{
+			Type:     models.FindingQuantityMismatch,
+			RewardID: reward.ID,
+			Message:  message,
+		})
+	}
+
+	return findings
+}
@@ -0,0 +1,277 @@
+package services
+
+import (
+	"assignment/decimal"
+	"assignment/models"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInsufficientHoldings = errors.New("insufficient holdings")
+	ErrRedemptionConflict   = errors.New("redemption ID already used with different values")
+)
+
+type lotPortion struct {
+	lot      rewardLot
+	quantity decimal.Decimal
+	cost     decimal.Decimal
+}
+
+// CreateRedemption sells quantity shares of the user's holding at the current
+// price, taking them from the oldest reward lots first and skipping shares
+// locked by a pending demat transfer. Each lot's share of the sale is
+// journaled under that reward, so every reward's entries stay balanced. A
+// retry with the same ID and values returns the original redemption.
+func CreateRedemption(db *gorm.DB, clock Clock, prices *PriceService, req models.RedemptionRequest) (response *models.RedemptionResponse, replayed bool, err error) {
+	req.StockSymbol = strings.ToUpper(req.StockSymbol)
+
+	if response, err := replayRedemption(db, req); response != nil || err != nil {
+		return response, response != nil, err
+	}
+
+	instrument, err := GetTradableInstrument(db, req.StockSymbol)
+	if err != nil {
+		return nil, false, err
+	}
+
+	redeemedAt := clock.Now()
+	schedule, err := GetEffectiveFeeSchedule(db, redeemedAt, instrument.Exchange, instrument.InstrumentType)
+	if err != nil {
+		return nil, false, err
+	}
+
+	price, err := prices.GetCurrentStockPrice(req.StockSymbol)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get stock price: %w", err)
+	}
+
+	var redemption models.Redemption
+	var entries []models.LedgerEntry
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var locked []models.StockReward
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			Where("user_id = ? AND status = ?", req.UserID, models.RewardStatusActive).
+			Find(&locked).Error
+		if err != nil {
+			return err
+		}
+
+		lots, err := loadFreeLots(tx, req.StockSymbol, req.UserID)
+		if err != nil {
+			return err
+		}
+
+		portions, err := allocateLots(lots, req.Quantity)
+		if err != nil {
+			return err
+		}
+
+		charges := CalculateSaleCharges(req.Quantity.Mul(price), schedule)
+
+		redemption = models.Redemption{
+			ID:              req.ID,
+			UserID:          req.UserID,
+			StockSymbol:     req.StockSymbol,
+			Quantity:        req.Quantity,
+			Price:           price,
+			GrossAmount:     charges.GrossAmount,
+			Brokerage:       charges.Brokerage,
+			STT:             charges.STT,
+			ExchangeCharges: charges.ExchangeCharges,
+			SEBIFees:        charges.SEBIFees,
+			GST:             charges.GST,
+			TotalCharges:    charges.TotalCharges,
+			NetAmount:       charges.NetAmount,
+			FeeScheduleID:   schedule.ID,
+			LotsRedeemed:    len(portions),
+			RedeemedAt:      redeemedAt,
+		}
+
+		entries = redemptionEntries(&redemption, charges, portions)
+		for _, portion := range portions {
+			redemption.CostBasis = redemption.CostBasis.Add(portion.cost)
+		}
+		redemption.RealizedGain = redemption.GrossAmount.Sub(redemption.CostBasis)
+
+		if err := tx.Create(&redemption).Error; err != nil {
+			return err
+		}
+		if err := tx.CreateInBatches(&entries, 500).Error; err != nil {
+			return fmt.Errorf("failed to record redemption entries: %w", err)
+		}
+		return nil
+	})
+
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		response, err := replayRedemption(db, req)
+		return response, response != nil, err
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	return &models.RedemptionResponse{
+		Success:       true,
+		Message:       "Shares redeemed successfully",
+		Redemption:    redemption,
+		LedgerEntries: entries,
+	}, false, nil
+}
+
+func replayRedemption(db *gorm.DB, req models.RedemptionRequest) (*models.RedemptionResponse, error) {
+	var existing models.Redemption
+	err := db.Where("id = ?", req.ID).Limit(1).Find(&existing).Error
+	if err != nil || existing.ID == "" {
+		return nil, err
+	}
+
+	if existing.UserID != req.UserID || existing.StockSymbol != req.StockSymbol || !existing.Quantity.Equal(req.Quantity) {
+		return nil, fmt.Errorf("%w: %s", ErrRedemptionConflict, req.ID)
+	}
+
+	var entries []models.LedgerEntry
+	err = db.Where("entry_type = ? AND reference_id = ?", models.EntryTypeRedemption, redemptionReference(existing.ID)).
+		Order("id").
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.RedemptionResponse{
+		Success:       true,
+		Message:       "Shares redeemed successfully",
+		Redemption:    existing,
+		LedgerEntries: entries,
+	}, nil
+}
+
+func allocateLots(lots []rewardLot, quantity decimal.Decimal) ([]lotPortion, error) {
+	available := decimal.Zero
+	for _, lot := range lots {
+		available = available.Add(lot.quantity)
+	}
+	if available.LessThan(quantity) {
+		return nil, fmt.Errorf("%w: requested %s, available %s", ErrInsufficientHoldings, trimZeros(quantity), trimZeros(available))
+	}
+
+	var portions []lotPortion
+	remaining := quantity
+	for _, lot := range lots {
+		if !remaining.IsPositive() {
+			break
+		}
+
+		portion := lotPortion{lot: lot, quantity: decimal.Min(lot.quantity, remaining), cost: lot.bookValue}
+		if portion.quantity.LessThan(lot.quantity) {
+			portion.cost = lot.bookValue.Mul(portion.quantity).Div(lot.quantity, 4)
+		}
+		portions = append(portions, portion)
+		remaining = remaining.Sub(portion.quantity)
+	}
+
+	return portions, nil
+}
+
+// allocate splits total across weights in proportion, rounding each share to
+// places and giving the rounding remainder to the last share.
+func allocate(total decimal.Decimal, weights []decimal.Decimal, places int32) []decimal.Decimal {
+	sum := decimal.Zero
+	for _, weight := range weights {
+		sum = sum.Add(weight)
+	}
+
+	shares := make([]decimal.Decimal, len(weights))
+	allocated := decimal.Zero
+	for i, weight := range weights {
+		if i == len(weights)-1 || !sum.IsPositive() {
+			shares[i] = total.Sub(allocated)
+			break
+		}
+		shares[i] = total.Mul(weight).Div(sum, places)
+		allocated = allocated.Add(shares[i])
+	}
+
+	return shares
+}
+
+func redemptionReference(redemptionID string) string {
+	return "RED-" + redemptionID
+}
+
+// redemptionEntries journals a sale whose charges the user bears: the whole
+// proceeds are owed to the user, and the charges are paid out of them, so
+// they reduce the payout and are not booked as company expenses.
+func redemptionEntries(redemption *models.Redemption, charges *models.SaleCharges, portions []lotPortion) []models.LedgerEntry {
+	weights := make([]decimal.Decimal, len(portions))
+	for i, portion := range portions {
+		weights[i] = portion.quantity
+	}
+
+	gross := allocate(charges.GrossAmount, weights, 2)
+	totalCharges := allocate(charges.TotalCharges, weights, 2)
+
+	symbol := redemption.StockSymbol
+	referenceID := redemptionReference(redemption.ID)
+
+	var entries []models.LedgerEntry
+	entry := func(rewardID, accountType string, debit, credit decimal.Decimal, quantity *decimal.Decimal, description string) {
+		entries = append(entries, models.LedgerEntry{
+			RewardID:     rewardID,
+			EntryType:    models.EntryTypeRedemption,
+			AccountType:  accountType,
+			StockSymbol:  &symbol,
+			DebitAmount:  debit,
+			CreditAmount: credit,
+			Quantity:     quantity,
+			Description:  description,
+			ReferenceID:  referenceID,
+			EffectiveAt:  redemption.RedeemedAt,
+		})
+	}
+
+	for i, portion := range portions {
+		rewardID := portion.lot.rewardID
+		sold := portion.quantity.Neg()
+
+		entry(rewardID, models.AccountTypeStockAsset, decimal.Zero, portion.cost, &sold,
+			fmt.Sprintf("Redeemed %s shares of %s", trimZeros(portion.quantity), symbol))
+		entry(rewardID, models.AccountTypeCashAccount, gross[i], decimal.Zero, nil,
+			fmt.Sprintf("Sale proceeds at %s per share", redemption.Price.StringFixed(2)))
+
+		gain := gross[i].Sub(portion.cost)
+		if gain.IsPositive() {
+			entry(rewardID, models.AccountTypeRealizedGainLoss, decimal.Zero, gain, nil, "Realized gain on redemption")
+		} else if gain.IsNegative() {
+			entry(rewardID, models.AccountTypeRealizedGainLoss, gain.Abs(), decimal.Zero, nil, "Realized loss on redemption")
+		}
+
+		entry(rewardID, models.AccountTypeRedemptionPayout, gross[i], decimal.Zero, nil,
+			fmt.Sprintf("Sale proceeds owed to user %s", redemption.UserID))
+		if totalCharges[i].IsPositive() {
+			entry(rewardID, models.AccountTypeCashAccount, decimal.Zero, totalCharges[i], nil,
+				fmt.Sprintf("Sell-side charges paid from the proceeds of user %s", redemption.UserID))
+		}
+		entry(rewardID, models.AccountTypeCashAccount, decimal.Zero, gross[i].Sub(totalCharges[i]), nil,
+			fmt.Sprintf("Net proceeds paid to user %s", redemption.UserID))
+	}
+
+	return entries
+}
+
+func ListUserRedemptions(db *gorm.DB, userID string) ([]models.Redemption, error) {
+	redemptions := []models.Redemption{}
+	err := db.Where("user_id = ?", userID).
+		Order("redeemed_at DESC, id").
+		Find(&redemptions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return redemptions, nil
+}
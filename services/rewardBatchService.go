@@ -0,0 +1,331 @@
+package services
+
+import (
+	"assignment/decimal"
+	"assignment/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	rewardBatchChunkSize  = 100
+	rewardBatchLookupSize = 500
+)
+
+// CreateRewardBatch records many rewards with the same rules as CreateReward
+// but looks up instruments, prices and fee schedules once per batch and
+// inserts in chunked transactions. A chunk that fails is retried item by item
+// so one bad reward does not fail its neighbours.
+func CreateRewardBatch(db *gorm.DB, prices *PriceService, limits *RewardLimiter, items []models.BatchRewardItem) []models.BatchItemResult {
+	results, prepared, preparedIndexes := planRewardBatch(db, items, prices.GetCurrentStockPrice)
+
+	for start := 0; start < len(prepared); start += rewardBatchChunkSize {
+		end := start + rewardBatchChunkSize
+		if end > len(prepared) {
+			end = len(prepared)
+		}
+		chunk := prepared[start:end]
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for _, reward := range chunk {
+				if err := reward.persist(tx, limits); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+		for offset, reward := range chunk {
+			i := preparedIndexes[start+offset]
+			if err == nil {
+				results[i].Status = models.BatchStatusCreated
+				results[i].Message = reward.response.Message
+				results[i].Reward = reward.reward
+				results[i].CompanyCharges = reward.charges
+				continue
+			}
+			results[i] = retryBatchItem(db, prices, limits, results[i], items[i].Request)
+		}
+	}
+
+	return results
+}
+
+// PreviewRewardBatch runs the same checks as CreateRewardBatch without writing
+// anything. Items that would be created are reported as valid with their
+// charges. Limits are checked against current usage plus the valid items
+// before each one in the batch.
+func PreviewRewardBatch(db *gorm.DB, prices *PriceService, limits *RewardLimiter, items []models.BatchRewardItem) []models.BatchItemResult {
+	results, prepared, preparedIndexes := planRewardBatch(db, items, prices.PeekCurrentStockPrice)
+	preview := limits.preview(db)
+
+	for offset, reward := range prepared {
+		i := preparedIndexes[offset]
+		reward.reward.CreatedAt = limits.clock.Now().UTC()
+		if err := preview.check(reward.reward, reward.campaign, reward.charges.StockCost); err != nil {
+			var limitErr *LimitExceededError
+			results[i].Status = models.BatchStatusFailed
+			if errors.As(err, &limitErr) {
+				results[i].Status = models.BatchStatusInvalid
+				results[i].Code = limitErr.Code
+			}
+			results[i].Message = err.Error()
+			continue
+		}
+
+		results[i].Status = models.BatchStatusValid
+		results[i].Message = "Reward would be recorded"
+		results[i].Reward = reward.reward
+		results[i].CompanyCharges = reward.charges
+	}
+
+	return results
+}
+
+func planRewardBatch(db *gorm.DB, items []models.BatchRewardItem, priceOf func(string) (decimal.Decimal, error)) ([]models.BatchItemResult, []*preparedReward, []int) {
+	results := make([]models.BatchItemResult, len(items))
+	pending := make([]int, 0, len(items))
+
+	type batchRequest struct {
+		canonical models.RewardRequest
+		hash      string
+	}
+	requests := make([]batchRequest, len(items))
+	firstByID := make(map[string]int)
+
+	for i, item := range items {
+		canonical, hash := fingerprintReward(item.Request)
+		requests[i] = batchRequest{canonical: canonical, hash: hash}
+		results[i] = models.BatchItemResult{Index: item.Index, ID: item.Request.ID}
+
+		if first, seen := firstByID[canonical.ID]; seen {
+			results[i].Status = models.BatchStatusDuplicate
+			results[i].Message = fmt.Sprintf("Reward with ID '%s' appears earlier in this batch at index %d", canonical.ID, items[first].Index)
+			if requests[first].hash != hash {
+				results[i].Diff = diffRewardRequests(requests[first].canonical, canonical)
+			}
+			continue
+		}
+
+		firstByID[canonical.ID] = i
+		pending = append(pending, i)
+	}
+
+	keys, rewardIDs, err := loadExistingRewards(db, pending, func(i int) string { return requests[i].canonical.ID })
+	if err != nil {
+		for _, i := range pending {
+			results[i].Status = models.BatchStatusFailed
+			results[i].Message = err.Error()
+		}
+		return results, nil, nil
+	}
+
+	instruments := make(map[string]*models.Instrument)
+	instrumentErrors := make(map[string]error)
+	prices := make(map[string]decimal.Decimal)
+	priceErrors := make(map[string]error)
+	exDates := make(map[string]time.Time)
+	exDateErrors := make(map[string]error)
+	campaigns := make(map[string]*models.Campaign)
+	campaignErrors := make(map[string]error)
+
+	schedules, scheduleErr := LoadFeeSchedules(db)
+
+	var prepared []*preparedReward
+	var preparedIndexes []int
+
+	for _, i := range pending {
+		req := requests[i].canonical
+
+		if key, exists := keys[req.ID]; exists {
+			results[i] = replayBatchItem(results[i], key, req, requests[i].hash)
+			continue
+		}
+		if rewardIDs[req.ID] {
+			results[i].Status = models.BatchStatusDuplicate
+			results[i].Message = (&RewardConflictError{RewardID: req.ID}).Error()
+			continue
+		}
+
+		if _, looked := instruments[req.StockSymbol]; !looked {
+			instrument, err := GetTradableInstrument(db, req.StockSymbol)
+			instruments[req.StockSymbol] = instrument
+			instrumentErrors[req.StockSymbol] = err
+		}
+		if err := instrumentErrors[req.StockSymbol]; err != nil {
+			results[i].Status = models.BatchStatusFailed
+			if errors.Is(err, ErrUnknownInstrument) || errors.Is(err, ErrInstrumentNotActive) {
+				results[i].Status = models.BatchStatusInvalid
+			}
+			results[i].Message = err.Error()
+			continue
+		}
+		instrument := instruments[req.StockSymbol]
+
+		if _, looked := exDates[req.StockSymbol]; !looked {
+			exDates[req.StockSymbol], exDateErrors[req.StockSymbol] = appliedExDate(db, req.StockSymbol)
+		}
+		if err := exDateErrors[req.StockSymbol]; err != nil {
+			results[i].Status = models.BatchStatusFailed
+			results[i].Message = err.Error()
+			continue
+		}
+		if err := checkRewardAfterExDate(req.StockSymbol, req.RewardTimestamp, exDates[req.StockSymbol]); err != nil {
+			results[i].Status = models.BatchStatusInvalid
+			results[i].Message = err.Error()
+			continue
+		}
+
+		if scheduleErr != nil {
+			results[i].Status = models.BatchStatusFailed
+			results[i].Message = fmt.Sprintf("failed to get fee schedule: %v", scheduleErr)
+			continue
+		}
+		feeSchedule, err := schedules.Effective(req.RewardTimestamp, instrument.Exchange, instrument.InstrumentType)
+		if err != nil {
+			results[i].Status = models.BatchStatusFailed
+			results[i].Message = fmt.Sprintf("failed to get fee schedule: %v", err)
+			continue
+		}
+
+		var campaign *models.Campaign
+		if req.CampaignID != "" {
+			if _, looked := campaigns[req.CampaignID]; !looked {
+				campaigns[req.CampaignID], campaignErrors[req.CampaignID] = GetCampaign(db, req.CampaignID)
+			}
+			campaign = campaigns[req.CampaignID]
+
+			err := campaignErrors[req.CampaignID]
+			if errors.Is(err, ErrCampaignNotFound) {
+				err = fmt.Errorf("%w: %s", ErrUnknownCampaign, req.CampaignID)
+			} else if err == nil {
+				err = checkCampaignEligibility(campaign, req.StockSymbol, req.RewardTimestamp)
+			}
+			if err != nil {
+				results[i].Status = models.BatchStatusFailed
+				if isCampaignError(err) {
+					results[i].Status = models.BatchStatusInvalid
+				}
+				results[i].Message = err.Error()
+				continue
+			}
+		}
+
+		if _, priced := prices[req.StockSymbol]; !priced {
+			price, err := priceOf(req.StockSymbol)
+			prices[req.StockSymbol] = price
+			priceErrors[req.StockSymbol] = err
+		}
+		if err := priceErrors[req.StockSymbol]; err != nil {
+			results[i].Status = models.BatchStatusFailed
+			results[i].Message = fmt.Sprintf("failed to get stock price: %v", err)
+			continue
+		}
+
+		prepared = append(prepared, prepareReward(req, requests[i].hash, prices[req.StockSymbol], feeSchedule, campaign))
+		preparedIndexes = append(preparedIndexes, i)
+	}
+
+	return results, prepared, preparedIndexes
+}
+
+func loadExistingRewards(db *gorm.DB, pending []int, idOf func(int) string) (map[string]models.RewardIdempotencyKey, map[string]bool, error) {
+	keys := make(map[string]models.RewardIdempotencyKey)
+	rewardIDs := make(map[string]bool)
+
+	for start := 0; start < len(pending); start += rewardBatchLookupSize {
+		end := start + rewardBatchLookupSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+
+		ids := make([]string, 0, end-start)
+		for _, i := range pending[start:end] {
+			ids = append(ids, idOf(i))
+		}
+
+		var existingKeys []models.RewardIdempotencyKey
+		if err := db.Where("reward_id IN ?", ids).Find(&existingKeys).Error; err != nil {
+			return nil, nil, fmt.Errorf("failed to check for duplicate rewards: %w", err)
+		}
+		for _, key := range existingKeys {
+			keys[key.RewardID] = key
+		}
+
+		var existingIDs []string
+		if err := db.Model(&models.StockReward{}).Where("id IN ?", ids).Pluck("id", &existingIDs).Error; err != nil {
+			return nil, nil, fmt.Errorf("failed to check for duplicate rewards: %w", err)
+		}
+		for _, id := range existingIDs {
+			rewardIDs[id] = true
+		}
+	}
+
+	return keys, rewardIDs, nil
+}
+
+func replayBatchItem(result models.BatchItemResult, key models.RewardIdempotencyKey, req models.RewardRequest, hash string) models.BatchItemResult {
+	result.Status = models.BatchStatusDuplicate
+
+	if key.RequestHash != hash {
+		var stored models.RewardRequest
+		if err := json.Unmarshal([]byte(key.RequestBody), &stored); err != nil {
+			result.Status = models.BatchStatusFailed
+			result.Message = fmt.Sprintf("failed to decode stored request: %v", err)
+			return result
+		}
+		conflict := &RewardConflictError{RewardID: req.ID, Diff: diffRewardRequests(stored, req)}
+		result.Message = conflict.Error()
+		result.Diff = conflict.Diff
+		return result
+	}
+
+	var response models.RewardResponse
+	if err := json.Unmarshal([]byte(key.ResponseBody), &response); err != nil {
+		result.Status = models.BatchStatusFailed
+		result.Message = fmt.Sprintf("failed to decode stored response: %v", err)
+		return result
+	}
+
+	result.Message = "Reward already recorded with an identical request"
+	result.Reward = response.Reward
+	return result
+}
+
+func retryBatchItem(db *gorm.DB, prices *PriceService, limits *RewardLimiter, result models.BatchItemResult, req models.RewardRequest) models.BatchItemResult {
+	response, replayed, err := CreateReward(db, prices, limits, req)
+
+	var conflict *RewardConflictError
+	var limitErr *LimitExceededError
+	switch {
+	case errors.As(err, &conflict):
+		result.Status = models.BatchStatusDuplicate
+		result.Message = conflict.Error()
+		result.Diff = conflict.Diff
+	case errors.As(err, &limitErr):
+		result.Status = models.BatchStatusInvalid
+		result.Message = limitErr.Error()
+		result.Code = limitErr.Code
+	case errors.Is(err, ErrUnknownInstrument) || errors.Is(err, ErrInstrumentNotActive) || errors.Is(err, ErrRewardBeforeExDate) || isCampaignError(err):
+		result.Status = models.BatchStatusInvalid
+		result.Message = err.Error()
+	case err != nil:
+		result.Status = models.BatchStatusFailed
+		result.Message = err.Error()
+	case replayed:
+		result.Status = models.BatchStatusDuplicate
+		result.Message = "Reward already recorded with an identical request"
+		result.Reward = response.Reward
+	default:
+		result.Status = models.BatchStatusCreated
+		result.Message = response.Message
+		result.Reward = response.Reward
+		result.CompanyCharges = response.CompanyCharges
+	}
+
+	return result
+}
@@ -0,0 +1,303 @@
+package services
+
+import (
+	"assignment/decimal"
+	"assignment/initializers"
+	"assignment/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LimitExceededError reports a reward that would take a limit past its cap.
+type LimitExceededError struct {
+	Code      string
+	Limit     decimal.Decimal
+	Used      decimal.Decimal
+	Requested decimal.Decimal
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("%s: reward of %s INR exceeds the limit of %s INR (already used %s INR)",
+		e.Code, e.Requested.StringFixed(2), e.Limit.StringFixed(2), e.Used.StringFixed(2))
+}
+
+// RewardLimiter enforces the reward caps. Day and month periods follow the
+// clock at the time a reward is recorded, not the reward's own timestamp.
+type RewardLimiter struct {
+	config   initializers.RewardLimitsConfig
+	clock    Clock
+	location *time.Location
+}
+
+// NewRewardLimiter returns a limiter for config whose days and months are
+// drawn in location. A zero config enforces no caps.
+func NewRewardLimiter(config initializers.RewardLimitsConfig, clock Clock, location *time.Location) *RewardLimiter {
+	return &RewardLimiter{config: config, clock: clock, location: location}
+}
+
+// Config returns the caps the limiter enforces.
+func (l *RewardLimiter) Config() initializers.RewardLimitsConfig {
+	return l.config
+}
+
+type limitBucket struct {
+	scope       string
+	key         string
+	periodStart time.Time
+	limit       decimal.Decimal
+	code        string
+}
+
+// rewardLimitBuckets lists the usage rows a reward counts against, always in
+// the same order so concurrent rewards lock them without deadlocking. A
+// campaign's own budgets take precedence over the configured ones.
+func (l *RewardLimiter) rewardLimitBuckets(reward *models.StockReward, campaign *models.Campaign) []limitBucket {
+	day, month := l.limitPeriods(reward.CreatedAt)
+
+	var buckets []limitBucket
+	if reward.CampaignID != nil {
+		campaignID := *reward.CampaignID
+		dailyBudget := l.config.CampaignBudget(campaignID)
+		totalBudget := decimal.Zero
+		if campaign != nil {
+			if campaign.DailyBudget.IsPositive() {
+				dailyBudget = campaign.DailyBudget
+			}
+			totalBudget = campaign.Budget
+		}
+		buckets = append(buckets,
+			limitBucket{models.LimitScopeCampaignTotal, campaignID, campaignPeriodStart, totalBudget, models.LimitCodeCampaignTotal},
+			limitBucket{models.LimitScopeCampaignDaily, campaignID, day.Start.UTC(), dailyBudget, models.LimitCodeCampaignBudget},
+		)
+	}
+	return append(buckets,
+		limitBucket{models.LimitScopeUserDaily, reward.UserID, day.Start.UTC(), l.config.UserDailyLimit, models.LimitCodeUserDaily},
+		limitBucket{models.LimitScopeUserMonthly, reward.UserID, month.Start.UTC(), l.config.UserMonthlyLimit, models.LimitCodeUserMonthly},
+	)
+}
+
+// campaignPeriodStart keys the usage row that counts a campaign's whole run.
+var campaignPeriodStart = time.Unix(0, 0).UTC()
+
+// limitPeriods returns the business day and month containing at.
+func (l *RewardLimiter) limitPeriods(at time.Time) (day, month DateRange) {
+	return DayRange(at, l.location), MonthRange(at, l.location)
+}
+
+// reserve checks the reward against every configured cap and adds its value
+// to the usage rows, recording each addition for release. Only capped buckets
+// get a row, so uncapped rewards don't contend for locks; their usage is
+// summed from the rewards when reported. It must run inside the transaction
+// that records the reward, after reward.CreatedAt has been set.
+func (l *RewardLimiter) reserve(tx *gorm.DB, reward *models.StockReward, campaign *models.Campaign, value decimal.Decimal) error {
+	if l.config.MaxRewardValue.IsPositive() && value.GreaterThan(l.config.MaxRewardValue) {
+		return &LimitExceededError{Code: models.LimitCodeRewardValue, Limit: l.config.MaxRewardValue, Requested: value}
+	}
+
+	for _, bucket := range l.rewardLimitBuckets(reward, campaign) {
+		if !bucket.limit.IsPositive() {
+			continue
+		}
+
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.RewardLimitUsage{
+			Scope:       bucket.scope,
+			ScopeKey:    bucket.key,
+			PeriodStart: bucket.periodStart,
+			Amount:      decimal.Zero,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to create limit usage: %w", err)
+		}
+
+		usage, err := lockLimitUsage(tx, bucket)
+		if err != nil {
+			return err
+		}
+
+		used := usage.Amount.Add(value)
+		if used.GreaterThan(bucket.limit) {
+			return &LimitExceededError{Code: bucket.code, Limit: bucket.limit, Used: usage.Amount, Requested: value}
+		}
+
+		err = tx.Model(usage).Updates(map[string]interface{}{
+			"amount":       used,
+			"reward_count": usage.RewardCount + 1,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Create(&models.RewardLimitReservation{RewardID: reward.ID, UsageID: usage.ID, Amount: value}).Error
+		if err != nil {
+			return fmt.Errorf("failed to record limit reservation: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// release gives a reversed reward's value back to the usage rows it was
+// reserved against, whatever the caps are now. The rows are locked in the
+// order reserve took them.
+func (l *RewardLimiter) release(tx *gorm.DB, reward *models.StockReward) error {
+	var reservations []models.RewardLimitReservation
+	if err := tx.Where("reward_id = ?", reward.ID).Order("id").Find(&reservations).Error; err != nil {
+		return fmt.Errorf("failed to fetch limit reservations: %w", err)
+	}
+
+	for _, reservation := range reservations {
+		var usage models.RewardLimitUsage
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", reservation.UsageID).First(&usage).Error
+		if err != nil {
+			return fmt.Errorf("failed to lock limit usage: %w", err)
+		}
+
+		err = tx.Model(&usage).Updates(map[string]interface{}{
+			"amount":       decimal.Max(usage.Amount.Sub(reservation.Amount), decimal.Zero),
+			"reward_count": max(usage.RewardCount-1, 0),
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(reservations) == 0 {
+		return nil
+	}
+	return tx.Where("reward_id = ?", reward.ID).Delete(&models.RewardLimitReservation{}).Error
+}
+
+// limitPreview checks rewards against the caps without writing anything. Each
+// reward that passes counts towards the ones checked after it, as it would
+// when the rewards are recorded in order. Usage rows are read without locks,
+// so a preview can pass rewards that concurrent writes later push over a cap.
+type limitPreview struct {
+	limiter *RewardLimiter
+	db      *gorm.DB
+	used    map[limitBucketKey]decimal.Decimal
+}
+
+type limitBucketKey struct {
+	scope       string
+	key         string
+	periodStart time.Time
+}
+
+func (l *RewardLimiter) preview(db *gorm.DB) *limitPreview {
+	return &limitPreview{limiter: l, db: db, used: make(map[limitBucketKey]decimal.Decimal)}
+}
+
+// check is reserve without the writes. reward.CreatedAt must be set.
+func (p *limitPreview) check(reward *models.StockReward, campaign *models.Campaign, value decimal.Decimal) error {
+	config := p.limiter.config
+	if config.MaxRewardValue.IsPositive() && value.GreaterThan(config.MaxRewardValue) {
+		return &LimitExceededError{Code: models.LimitCodeRewardValue, Limit: config.MaxRewardValue, Requested: value}
+	}
+
+	var counted []limitBucketKey
+	for _, bucket := range p.limiter.rewardLimitBuckets(reward, campaign) {
+		if !bucket.limit.IsPositive() {
+			continue
+		}
+
+		key := limitBucketKey{bucket.scope, bucket.key, bucket.periodStart}
+		used, read := p.used[key]
+		if !read {
+			var usage models.RewardLimitUsage
+			err := p.db.Where("scope = ? AND scope_key = ? AND period_start = ?", bucket.scope, bucket.key, bucket.periodStart).
+				First(&usage).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("failed to read limit usage: %w", err)
+			}
+			used = usage.Amount
+			p.used[key] = used
+		}
+
+		if used.Add(value).GreaterThan(bucket.limit) {
+			return &LimitExceededError{Code: bucket.code, Limit: bucket.limit, Used: used, Requested: value}
+		}
+		counted = append(counted, key)
+	}
+
+	for _, key := range counted {
+		p.used[key] = p.used[key].Add(value)
+	}
+	return nil
+}
+
+func lockLimitUsage(tx *gorm.DB, bucket limitBucket) (*models.RewardLimitUsage, error) {
+	var usage models.RewardLimitUsage
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("scope = ? AND scope_key = ? AND period_start = ?", bucket.scope, bucket.key, bucket.periodStart).
+		First(&usage).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock limit usage: %w", err)
+	}
+
+	return &usage, nil
+}
+
+// GetUserLimits reports the user's daily and monthly usage for the periods
+// containing at. Usage is summed from the user's active rewards, so it is
+// reported whether or not a cap is configured.
+func (l *RewardLimiter) GetUserLimits(db *gorm.DB, userID string, at time.Time) (*models.UserLimitsResponse, error) {
+	day, month := l.limitPeriods(at)
+
+	var rewards []models.StockReward
+	err := db.Select("quantity", "stock_price_at_reward", "created_at").
+		Where("user_id = ? AND status = ? AND created_at >= ? AND created_at < ?",
+			userID, models.RewardStatusActive, month.Start.UTC(), month.End.UTC()).
+		Find(&rewards).Error
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.UserLimitsResponse{
+		UserID:         userID,
+		MaxRewardValue: optionalLimit(l.config.MaxRewardValue),
+		AsOf:           at,
+	}
+
+	periods := []struct {
+		dates  DateRange
+		limit  decimal.Decimal
+		report *models.LimitUsage
+	}{
+		{day, l.config.UserDailyLimit, &response.Daily},
+		{month, l.config.UserMonthlyLimit, &response.Monthly},
+	}
+
+	for _, period := range periods {
+		usage := models.LimitUsage{
+			PeriodStart: period.dates.Start.UTC(),
+			PeriodEnd:   period.dates.End.UTC(),
+			Limit:       optionalLimit(period.limit),
+			Used:        decimal.Zero,
+		}
+		for _, reward := range rewards {
+			if !period.dates.Contains(reward.CreatedAt) {
+				continue
+			}
+			usage.Used = usage.Used.Add(reward.Quantity.Mul(reward.StockPriceAtReward).RoundBank(2))
+			usage.RewardCount++
+		}
+		if period.limit.IsPositive() {
+			remaining := decimal.Max(period.limit.Sub(usage.Used), decimal.Zero)
+			usage.Remaining = &remaining
+		}
+		*period.report = usage
+	}
+
+	return response, nil
+}
+
+func optionalLimit(limit decimal.Decimal) *decimal.Decimal {
+	if !limit.IsPositive() {
+		return nil
+	}
+	return &limit
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"assignment/models"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const defaultRewardPageSize = 50
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ListUserRewards pages through a user's rewards by (reward_timestamp, id),
+// newest first unless query.Sort is "asc". NextCursor is empty on the last
+// page.
+func ListUserRewards(db *gorm.DB, userID string, query models.RewardListQuery) (*models.RewardListResponse, error) {
+	if query.Limit == 0 {
+		query.Limit = defaultRewardPageSize
+	}
+	ascending := query.Sort == "asc"
+
+	scope := db.Where("user_id = ?", userID)
+	if query.Symbol != "" {
+		scope = scope.Where("stock_symbol = ?", strings.ToUpper(query.Symbol))
+	}
+	if query.Status != "" {
+		scope = scope.Where("status = ?", strings.ToUpper(query.Status))
+	}
+	if !query.From.IsZero() {
+		scope = scope.Where("reward_timestamp >= ?", query.From.UTC())
+	}
+	if !query.To.IsZero() {
+		scope = scope.Where("reward_timestamp < ?", query.To.UTC())
+	}
+
+	if query.Cursor != "" {
+		after, afterID, err := decodeRewardCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if ascending {
+			scope = scope.Where("reward_timestamp > ? OR (reward_timestamp = ? AND id > ?)", after, after, afterID)
+		} else {
+			scope = scope.Where("reward_timestamp < ? OR (reward_timestamp = ? AND id < ?)", after, after, afterID)
+		}
+	}
+
+	order := "reward_timestamp DESC, id DESC"
+	if ascending {
+		order = "reward_timestamp ASC, id ASC"
+	}
+
+	rewards := []models.StockReward{}
+	err := scope.Order(order).Limit(query.Limit + 1).Find(&rewards).Error
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.RewardListResponse{UserID: userID, Rewards: rewards}
+	if len(rewards) > query.Limit {
+		response.Rewards = rewards[:query.Limit]
+		last := response.Rewards[query.Limit-1]
+		response.NextCursor = encodeRewardCursor(last.RewardTimestamp, last.ID)
+	}
+
+	return response, nil
+}
+
+// GetRewardTotals returns the quantity of the user's active rewards per symbol
+// granted within dates.
+func GetRewardTotals(db *gorm.DB, userID string, dates DateRange) ([]models.Holding, error) {
+	var totals []models.Holding
+	err := db.Model(&models.StockReward{}).
+		Select("stock_symbol, SUM(quantity) AS quantity").
+		Where("user_id = ? AND status = ? AND reward_timestamp >= ? AND reward_timestamp < ?",
+			userID, models.RewardStatusActive, dates.Start.UTC(), dates.End.UTC()).
+		Group("stock_symbol").
+		Order("stock_symbol").
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range totals {
+		totals[i].Quantity = totals[i].Quantity.RoundBank(quantityScale)
+	}
+
+	return totals, nil
+}
+
+func encodeRewardCursor(at time.Time, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(at.UTC().Format(time.RFC3339Nano) + "|" + id))
+}
+
+func decodeRewardCursor(cursor string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	timestamp, id, found := strings.Cut(string(raw), "|")
+	if !found || id == "" {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+
+	at, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	return at.UTC(), id, nil
+}
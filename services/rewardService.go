@@ -0,0 +1,307 @@
+package services
+
+import (
+	"assignment/decimal"
+	"assignment/models"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrRewardNotFound        = errors.New("reward not found")
+	ErrRewardAlreadyReversed = errors.New("reward has already been reversed")
+	ErrRewardSharesDisposed  = errors.New("reward shares have already been redeemed or transferred")
+	ErrRewardSharesLocked    = errors.New("reward shares are locked by a pending transfer")
+)
+
+type RewardConflictError struct {
+	RewardID string
+	Diff     map[string]models.FieldDiff
+}
+
+func (e *RewardConflictError) Error() string {
+	if len(e.Diff) > 0 {
+		return fmt.Sprintf("Reward with ID '%s' has already been processed with a different payload", e.RewardID)
+	}
+	return fmt.Sprintf("Reward with ID '%s' has already been processed", e.RewardID)
+}
+
+// CreateReward records a reward and its ledger entries. A retry of an earlier
+// request with the same payload returns the stored response and replayed is
+// true; a different payload under the same ID returns a *RewardConflictError.
+func CreateReward(db *gorm.DB, prices *PriceService, limits *RewardLimiter, req models.RewardRequest) (response *models.RewardResponse, replayed bool, err error) {
+	canonical, hash := fingerprintReward(req)
+
+	response, err = replayReward(db, canonical, hash)
+	if err == nil {
+		return response, true, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+
+	instrument, err := GetTradableInstrument(db, req.StockSymbol)
+	if err != nil {
+		return nil, false, err
+	}
+
+	exDate, err := appliedExDate(db, instrument.Symbol)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := checkRewardAfterExDate(instrument.Symbol, canonical.RewardTimestamp, exDate); err != nil {
+		return nil, false, err
+	}
+
+	feeSchedule, err := GetEffectiveFeeSchedule(db, req.RewardTimestamp, instrument.Exchange, instrument.InstrumentType)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get fee schedule: %w", err)
+	}
+
+	var campaign *models.Campaign
+	if canonical.CampaignID != "" {
+		campaign, err = GetRewardCampaign(db, canonical.CampaignID, canonical.StockSymbol, canonical.RewardTimestamp)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	stockPrice, err := prices.GetCurrentStockPrice(req.StockSymbol)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get stock price: %w", err)
+	}
+
+	prepared := prepareReward(canonical, hash, stockPrice, feeSchedule, campaign)
+	err = db.Transaction(func(tx *gorm.DB) error {
+		return prepared.persist(tx, limits)
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		response, err = replayReward(db, canonical, hash)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, false, &RewardConflictError{RewardID: req.ID}
+		} else if err != nil {
+			return nil, false, err
+		}
+		return response, true, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	return prepared.response, false, nil
+}
+
+type preparedReward struct {
+	request  models.RewardRequest
+	hash     string
+	schedule *models.FeeSchedule
+	campaign *models.Campaign
+	reward   *models.StockReward
+	charges  *models.CompanyCharges
+	response *models.RewardResponse
+}
+
+func prepareReward(req models.RewardRequest, hash string, stockPrice decimal.Decimal, feeSchedule *models.FeeSchedule, campaign *models.Campaign) *preparedReward {
+	charges := CalculateCompanyCharges(stockPrice.Mul(req.Quantity), feeSchedule)
+
+	reward := &models.StockReward{
+		ID:                 req.ID,
+		UserID:             req.UserID,
+		StockSymbol:        req.StockSymbol,
+		Quantity:           req.Quantity,
+		RewardTimestamp:    req.RewardTimestamp,
+		StockPriceAtReward: stockPrice,
+		FeeScheduleID:      &feeSchedule.ID,
+	}
+	if req.CampaignID != "" {
+		reward.CampaignID = &req.CampaignID
+	}
+
+	return &preparedReward{
+		request:  req,
+		hash:     hash,
+		schedule: feeSchedule,
+		campaign: campaign,
+		reward:   reward,
+		charges:  charges,
+		response: &models.RewardResponse{
+			Success:        true,
+			Message:        "Stock reward recorded successfully",
+			Reward:         reward,
+			INRValue:       charges.StockCost,
+			CompanyCharges: charges,
+		},
+	}
+}
+
+// persist writes the reward, its ledger entries and its idempotency key, and
+// counts it against the reward limits. It must run inside a transaction.
+func (p *preparedReward) persist(tx *gorm.DB, limits *RewardLimiter) error {
+	p.reward.CreatedAt = limits.clock.Now().UTC()
+	if err := tx.Create(p.reward).Error; err != nil {
+		return fmt.Errorf("failed to record reward: %w", err)
+	}
+
+	if err := limits.reserve(tx, p.reward, p.campaign, p.charges.StockCost); err != nil {
+		return err
+	}
+
+	if err := RecordLedgerEntriesGORM(tx, p.reward, p.charges, p.schedule); err != nil {
+		return fmt.Errorf("failed to record ledger entries: %w", err)
+	}
+
+	requestBody, err := json.Marshal(p.request)
+	if err != nil {
+		return err
+	}
+	responseBody, err := json.Marshal(p.response)
+	if err != nil {
+		return err
+	}
+
+	key := models.RewardIdempotencyKey{
+		RewardID:     p.request.ID,
+		RequestHash:  p.hash,
+		RequestBody:  string(requestBody),
+		ResponseBody: string(responseBody),
+	}
+	if err := tx.Create(&key).Error; err != nil {
+		return fmt.Errorf("failed to record idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// replayReward returns the stored response for an earlier request with the
+// same fingerprint, or gorm.ErrRecordNotFound if the reward ID is unused.
+func replayReward(db *gorm.DB, req models.RewardRequest, hash string) (*models.RewardResponse, error) {
+	var key models.RewardIdempotencyKey
+	if err := db.Where("reward_id = ?", req.ID).First(&key).Error; err != nil {
+		return nil, err
+	}
+
+	if key.RequestHash != hash {
+		var stored models.RewardRequest
+		if err := json.Unmarshal([]byte(key.RequestBody), &stored); err != nil {
+			return nil, fmt.Errorf("failed to decode stored request: %w", err)
+		}
+		return nil, &RewardConflictError{RewardID: req.ID, Diff: diffRewardRequests(stored, req)}
+	}
+
+	var response models.RewardResponse
+	if err := json.Unmarshal([]byte(key.ResponseBody), &response); err != nil {
+		return nil, fmt.Errorf("failed to decode stored response: %w", err)
+	}
+
+	return &response, nil
+}
+
+func fingerprintReward(req models.RewardRequest) (models.RewardRequest, string) {
+	req.Quantity = decimal.RequireFromString(trimZeros(req.Quantity))
+	req.RewardTimestamp = req.RewardTimestamp.UTC()
+
+	body, _ := json.Marshal(req)
+	sum := sha256.Sum256(body)
+	return req, hex.EncodeToString(sum[:])
+}
+
+func diffRewardRequests(stored, received models.RewardRequest) map[string]models.FieldDiff {
+	diff := make(map[string]models.FieldDiff)
+
+	compare := func(field, storedValue, receivedValue string) {
+		if storedValue != receivedValue {
+			diff[field] = models.FieldDiff{Stored: storedValue, Received: receivedValue}
+		}
+	}
+
+	compare("user_id", stored.UserID, received.UserID)
+	compare("stock_symbol", stored.StockSymbol, received.StockSymbol)
+	compare("quantity", trimZeros(stored.Quantity), trimZeros(received.Quantity))
+	compare("campaign_id", stored.CampaignID, received.CampaignID)
+	compare("reward_timestamp", stored.RewardTimestamp.UTC().Format(time.RFC3339Nano), received.RewardTimestamp.UTC().Format(time.RFC3339Nano))
+
+	return diff
+}
+
+func ReverseReward(db *gorm.DB, clock Clock, limits *RewardLimiter, rewardID, reason string) (*models.StockReward, []models.LedgerEntry, error) {
+	var reward models.StockReward
+	var entries []models.LedgerEntry
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Redemptions and transfers lock the user's active rewards before
+		// taking shares from them, so locking the reward here keeps them from
+		// committing between the checks below and the status update.
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", rewardID).First(&reward).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: %s", ErrRewardNotFound, rewardID)
+			}
+			return err
+		}
+
+		var disposals int64
+		err := tx.Model(&models.LedgerEntry{}).
+			Where("reward_id = ? AND entry_type IN ?", rewardID, []string{models.EntryTypeRedemption, models.EntryTypeTransfer}).
+			Count(&disposals).Error
+		if err != nil {
+			return err
+		}
+		if disposals > 0 {
+			return fmt.Errorf("%w: %s", ErrRewardSharesDisposed, rewardID)
+		}
+
+		var locks int64
+		err = tx.Model(&models.DematTransferLot{}).
+			Joins("JOIN demat_transfers ON demat_transfers.id = demat_transfer_lots.transfer_id").
+			Where("demat_transfer_lots.reward_id = ? AND demat_transfers.status IN ?", rewardID, models.TransferPendingStatuses).
+			Count(&locks).Error
+		if err != nil {
+			return err
+		}
+		if locks > 0 {
+			return fmt.Errorf("%w: %s", ErrRewardSharesLocked, rewardID)
+		}
+
+		reversedAt := clock.Now()
+		result := tx.Model(&models.StockReward{}).
+			Where("id = ? AND status = ?", rewardID, models.RewardStatusActive).
+			Updates(map[string]interface{}{
+				"status":          models.RewardStatusReversed,
+				"reversal_reason": reason,
+				"reversed_at":     reversedAt,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to mark reward reversed: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: %s", ErrRewardAlreadyReversed, rewardID)
+		}
+
+		reward.Status = models.RewardStatusReversed
+		reward.ReversalReason = reason
+		reward.ReversedAt = &reversedAt
+
+		entries, err = RecordReversalEntriesGORM(tx, &reward, reason)
+		if err != nil {
+			return err
+		}
+
+		dividendEntries, err := cancelRewardDividends(tx, &reward, reason)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, dividendEntries...)
+
+		return limits.release(tx, &reward)
+	})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &reward, entries, nil
+}
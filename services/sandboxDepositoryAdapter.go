@@ -0,0 +1,17 @@
+package services
+
+// SandboxDepositoryAdapter accepts every instruction and reports it completed
+// on the first status check. It is meant for local development.
+type SandboxDepositoryAdapter struct{}
+
+func NewSandboxDepositoryAdapter() *SandboxDepositoryAdapter {
+	return &SandboxDepositoryAdapter{}
+}
+
+func (a *SandboxDepositoryAdapter) Submit(instruction DepositoryInstruction) (DepositoryStatus, error) {
+	return DepositoryStatus{Reference: "SBX-" + instruction.TransferID, State: DepositoryStatePending}, nil
+}
+
+func (a *SandboxDepositoryAdapter) Status(reference string) (DepositoryStatus, error) {
+	return DepositoryStatus{Reference: reference, State: DepositoryStateCompleted}, nil
+}
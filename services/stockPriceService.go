@@ -1,97 +1,213 @@
 package services
 
 import (
+	"assignment/decimal"
+	"assignment/models"
 	"fmt"
-	"math/rand"
+	"strings"
 	"sync"
 	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-var stockPriceRanges = map[string]struct{ min, max float64 }{
-	"RELIANCE":  {2200.0, 2800.0},
-	"TCS":       {3200.0, 4000.0},
-	"INFOSYS":   {1400.0, 1800.0},
-	"HDFC":      {1500.0, 2000.0},
-	"WIPRO":     {400.0, 600.0},
-	"ITC":       {380.0, 480.0},
-	"BHARTI":    {800.0, 1200.0},
-	"SBIN":      {500.0, 700.0},
-	"HDFCBANK":  {1400.0, 1700.0},
-	"ICICIBANK": {900.0, 1200.0},
+// PriceService caches the provider's latest prices and records every price
+// it fetches in stock_prices.
+type PriceService struct {
+	db       *gorm.DB
+	provider PriceProvider
+	clock    Clock
+	prices   map[string]decimal.Decimal
+	mutex    sync.RWMutex
 }
 
-var (
-	currentStockPrices = make(map[string]float64)
-	pricesMutex        sync.RWMutex
-)
+// NewPriceService returns a service with an empty cache. A nil db skips
+// recording prices.
+func NewPriceService(db *gorm.DB, provider PriceProvider, clock Clock) *PriceService {
+	return &PriceService{
+		db:       db,
+		provider: provider,
+		clock:    clock,
+		prices:   make(map[string]decimal.Decimal),
+	}
+}
 
-func GetCurrentStockPrice(stockSymbol string) (float64, error) {
-	pricesMutex.RLock()
-	price, exists := currentStockPrices[stockSymbol]
-	pricesMutex.RUnlock()
+func (s *PriceService) GetCurrentStockPrice(stockSymbol string) (decimal.Decimal, error) {
+	s.mutex.RLock()
+	price, exists := s.prices[stockSymbol]
+	s.mutex.RUnlock()
 
 	if !exists {
-		price = generateRandomPrice(stockSymbol)
-		pricesMutex.Lock()
-		currentStockPrices[stockSymbol] = price
-		pricesMutex.Unlock()
+		var err error
+		price, err = s.provider.GetPrice(stockSymbol)
+		if err != nil {
+			return decimal.Zero, err
+		}
+
+		s.mutex.Lock()
+		s.prices[stockSymbol] = price
+		s.mutex.Unlock()
+
+		if err := s.recordStockPrices(map[string]decimal.Decimal{stockSymbol: price}, s.clock.Now()); err != nil {
+			fmt.Printf("Error recording stock price for %s: %v\n", stockSymbol, err)
+		}
 	}
 
 	return price, nil
 }
 
-func generateRandomPrice(stockSymbol string) float64 {
-	priceRange, exists := stockPriceRanges[stockSymbol]
-	if !exists {
-		priceRange = struct{ min, max float64 }{100.0, 5000.0}
+// PeekCurrentStockPrice returns the cached price, or asks the provider
+// without caching or recording the result.
+func (s *PriceService) PeekCurrentStockPrice(stockSymbol string) (decimal.Decimal, error) {
+	s.mutex.RLock()
+	price, exists := s.prices[stockSymbol]
+	s.mutex.RUnlock()
+
+	if exists {
+		return price, nil
+	}
+
+	return s.provider.GetPrice(stockSymbol)
+}
+
+func (s *PriceService) GetCurrentPrices(stockSymbols []string) (map[string]decimal.Decimal, error) {
+	prices := make(map[string]decimal.Decimal)
+
+	for _, symbol := range stockSymbols {
+		price, err := s.GetCurrentStockPrice(symbol)
+		if err != nil {
+			return nil, err
+		}
+		prices[symbol] = price
 	}
-	price := priceRange.min + rand.Float64()*(priceRange.max-priceRange.min)
-	return float64(int(price*100)) / 100
+
+	return prices, nil
 }
 
-func UpdateStockPrices() error {
+func (s *PriceService) UpdateStockPrices() error {
+	return s.refreshStockPrices(s.clock.Now())
+}
+
+// RecordClosingPrices refreshes every price and records it at the session's
+// close, so the calendar's previous close finds it exactly.
+func (s *PriceService) RecordClosingPrices(closedAt time.Time) error {
+	return s.refreshStockPrices(closedAt)
+}
+
+func (s *PriceService) refreshStockPrices(timestamp time.Time) error {
+	symbols := s.provider.Symbols()
+	updatedPrices := make(map[string]decimal.Decimal, len(symbols))
 
-	pricesMutex.Lock()
-	defer pricesMutex.Unlock()
+	var failedSymbols []string
+	for _, stockSymbol := range symbols {
+		price, err := s.provider.GetPrice(stockSymbol)
+		if err != nil {
+			fmt.Printf("Error fetching price for %s: %v\n", stockSymbol, err)
+			failedSymbols = append(failedSymbols, stockSymbol)
+			continue
+		}
+		updatedPrices[stockSymbol] = price
+	}
 
-	for stockSymbol := range stockPriceRanges {
-		price := generateRandomPrice(stockSymbol)
-		currentStockPrices[stockSymbol] = price
+	s.mutex.Lock()
+	for stockSymbol, price := range updatedPrices {
+		s.prices[stockSymbol] = price
+	}
+	s.mutex.Unlock()
+
+	if err := s.recordStockPrices(updatedPrices, timestamp); err != nil {
+		return err
+	}
+
+	if len(failedSymbols) > 0 {
+		return fmt.Errorf("failed to fetch prices for %s", strings.Join(failedSymbols, ", "))
 	}
 
 	fmt.Printf("Stock prices updated\n")
 	return nil
 }
 
-func StartPriceUpdateScheduler() {
-	go func() {
-		if err := UpdateStockPrices(); err != nil {
-			fmt.Printf("Error updating stock prices: %v\n", err)
-		}
-	}()
-
-	ticker := time.NewTicker(1 * time.Hour)
-	go func() {
-		for range ticker.C {
-			if err := UpdateStockPrices(); err != nil {
-				fmt.Printf("Error updating stock prices: %v\n", err)
-			}
-		}
-	}()
+func (s *PriceService) recordStockPrices(prices map[string]decimal.Decimal, timestamp time.Time) error {
+	if s.db == nil || len(prices) == 0 {
+		return nil
+	}
 
-	fmt.Println("Stock price update scheduler started")
+	records := make([]models.StockPrice, 0, len(prices))
+	for stockSymbol, price := range prices {
+		records = append(records, models.StockPrice{
+			StockSymbol: stockSymbol,
+			Price:       price,
+			Timestamp:   timestamp.UTC(),
+		})
+	}
+
+	err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&records).Error
+	if err != nil {
+		return fmt.Errorf("failed to record stock prices: %v", err)
+	}
+
+	return nil
 }
 
-func GetCurrentPrices(stockSymbols []string) (map[string]float64, error) {
-	prices := make(map[string]float64)
+func GetStockPriceAsOf(db *gorm.DB, stockSymbol string, asOf time.Time) (*models.StockPrice, error) {
+	var stockPrice models.StockPrice
+	err := db.Where("stock_symbol = ? AND timestamp <= ?", stockSymbol, asOf.UTC()).
+		Order("timestamp DESC").
+		First(&stockPrice).Error
 
+	if err != nil {
+		return nil, err
+	}
+
+	return &stockPrice, nil
+}
+
+// GetPreviousCloses returns the last price recorded at or before closedAt for
+// each symbol that has one.
+func GetPreviousCloses(db *gorm.DB, stockSymbols []string, closedAt time.Time) (map[string]decimal.Decimal, error) {
+	closes := make(map[string]decimal.Decimal, len(stockSymbols))
 	for _, symbol := range stockSymbols {
-		price, err := GetCurrentStockPrice(symbol)
+		var stockPrice models.StockPrice
+		err := db.Where("stock_symbol = ? AND timestamp <= ?", symbol, closedAt.UTC()).
+			Order("timestamp DESC").
+			Limit(1).
+			Find(&stockPrice).Error
 		if err != nil {
 			return nil, err
 		}
-		prices[symbol] = price
+		if stockPrice.ID != 0 {
+			closes[symbol] = stockPrice.Price
+		}
 	}
 
-	return prices, nil
+	return closes, nil
+}
+
+func GetClosingPrices(db *gorm.DB, stockSymbol string, dayEnds []time.Time) ([]models.StockPrice, error) {
+	closingPrices := make([]models.StockPrice, len(dayEnds))
+	if len(dayEnds) == 0 {
+		return closingPrices, nil
+	}
+
+	var history []models.StockPrice
+	err := db.Where("stock_symbol = ? AND timestamp < ?", stockSymbol, dayEnds[len(dayEnds)-1].UTC()).
+		Order("timestamp ASC").
+		Find(&history).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	next := 0
+	var lastPrice models.StockPrice
+	for i, dayEnd := range dayEnds {
+		for next < len(history) && history[next].Timestamp.Before(dayEnd) {
+			lastPrice = history[next]
+			next++
+		}
+		closingPrices[i] = lastPrice
+	}
+
+	return closingPrices, nil
 }
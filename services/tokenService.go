@@ -0,0 +1,139 @@
+package services
+
+import (
+	"assignment/initializers"
+	"assignment/models"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token has expired")
+)
+
+var tokenAlgorithms = map[string]func() hash.Hash{
+	"HS256": sha256.New,
+	"HS384": sha512.New384,
+	"HS512": sha512.New,
+}
+
+type tokenHeader struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+	KeyID     string `json:"kid,omitempty"`
+}
+
+// ParseToken verifies an HMAC-signed JWT against the configured keys and
+// returns its claims. Tokens must carry an expiry, a subject and a known role.
+func ParseToken(config initializers.AuthConfig, token string, now time.Time) (*models.Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	var header tokenHeader
+	if err := decodeTokenSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: malformed header", ErrInvalidToken)
+	}
+
+	algorithm, ok := tokenAlgorithms[header.Algorithm]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, header.Algorithm)
+	}
+	key, ok := config.Keys[header.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, header.KeyID)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+	if !hmac.Equal(signature, signToken(algorithm, key, parts[0]+"."+parts[1])) {
+		return nil, fmt.Errorf("%w: signature mismatch", ErrInvalidToken)
+	}
+
+	var claims models.Claims
+	if err := decodeTokenSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims", ErrInvalidToken)
+	}
+
+	if claims.ExpiresAt == 0 {
+		return nil, fmt.Errorf("%w: missing exp", ErrInvalidToken)
+	}
+	if now.After(time.Unix(claims.ExpiresAt, 0).Add(config.Leeway)) {
+		return nil, ErrTokenExpired
+	}
+	if claims.NotBefore != 0 && now.Add(config.Leeway).Before(time.Unix(claims.NotBefore, 0)) {
+		return nil, fmt.Errorf("%w: token is not valid yet", ErrInvalidToken)
+	}
+	if config.Issuer != "" && claims.Issuer != config.Issuer {
+		return nil, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, claims.Issuer)
+	}
+	if config.Audience != "" && !claims.Audience.Contains(config.Audience) {
+		return nil, fmt.Errorf("%w: token is not for this audience", ErrInvalidToken)
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing sub", ErrInvalidToken)
+	}
+	switch claims.Role {
+	case models.RoleService, models.RoleUser, models.RoleAdmin:
+	default:
+		return nil, fmt.Errorf("%w: unknown role %q", ErrInvalidToken, claims.Role)
+	}
+
+	return &claims, nil
+}
+
+// SignToken issues an HS256 token with the configured signing key, filling in
+// the issuer and audience when they are configured.
+func SignToken(config initializers.AuthConfig, claims models.Claims) (string, error) {
+	key, ok := config.Keys[config.SigningKey]
+	if !ok {
+		return "", fmt.Errorf("no signing key with ID %q", config.SigningKey)
+	}
+
+	if claims.Issuer == "" {
+		claims.Issuer = config.Issuer
+	}
+	if len(claims.Audience) == 0 && config.Audience != "" {
+		claims.Audience = models.Audience{config.Audience}
+	}
+
+	header, err := json.Marshal(tokenHeader{Algorithm: "HS256", Type: "JWT", KeyID: config.SigningKey})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	signature := signToken(sha256.New, key, unsigned)
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func signToken(algorithm func() hash.Hash, key []byte, unsigned string) []byte {
+	mac := hmac.New(algorithm, key)
+	mac.Write([]byte(unsigned))
+	return mac.Sum(nil)
+}
+
+func decodeTokenSegment(segment string, target interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
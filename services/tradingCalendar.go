@@ -0,0 +1,203 @@
+package services
+
+import (
+	"assignment/models"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// maxCalendarScan bounds how many days a session search looks through, so a
+// calendar without trading days cannot loop forever.
+const maxCalendarScan = 400
+
+// TradingCalendar knows which days the exchange trades and when its session
+// opens and closes. Times are wall-clock offsets from midnight in location.
+type TradingCalendar struct {
+	location *time.Location
+	open     time.Duration
+	close    time.Duration
+	weekdays [7]bool
+	holidays map[string]string
+}
+
+// NSETradingDays are the weekdays the exchange is normally open.
+var NSETradingDays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+// NewTradingCalendar builds a calendar from session offsets, trading weekdays
+// and holidays keyed by YYYY-MM-DD. A close of 24h ends the session at the
+// following midnight.
+func NewTradingCalendar(location *time.Location, open, close time.Duration, weekdays []time.Weekday, holidays map[string]string) (*TradingCalendar, error) {
+	if open < 0 || close <= open || close > 24*time.Hour {
+		return nil, fmt.Errorf("invalid trading session %v to %v", open, close)
+	}
+	if len(weekdays) == 0 {
+		return nil, errors.New("trading calendar has no trading days")
+	}
+
+	calendar := &TradingCalendar{location: location, open: open, close: close, holidays: make(map[string]string)}
+	for _, weekday := range weekdays {
+		calendar.weekdays[weekday] = true
+	}
+	for date, name := range holidays {
+		calendar.holidays[date] = name
+	}
+
+	return calendar, nil
+}
+
+// LoadHolidays reads a CSV of date,name rows with dates as YYYY-MM-DD. A
+// header row, blank lines and lines starting with # are skipped.
+func LoadHolidays(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open holidays file: %v", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	holidays := make(map[string]string)
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read holidays file %s: %v", path, err)
+		}
+
+		date := strings.TrimSpace(record[0])
+		if line == 1 && strings.EqualFold(date, "date") {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, fmt.Errorf("invalid holiday date %q in %s", date, path)
+		}
+
+		name := "Holiday"
+		if len(record) > 1 && strings.TrimSpace(record[1]) != "" {
+			name = strings.TrimSpace(record[1])
+		}
+		holidays[date] = name
+	}
+
+	return holidays, nil
+}
+
+// NewContinuousCalendar treats every day in location as a single session
+// from midnight to midnight, so the previous close is the last price before
+// today.
+func NewContinuousCalendar(location *time.Location) *TradingCalendar {
+	calendar, _ := NewTradingCalendar(location, 0, 24*time.Hour, []time.Weekday{
+		time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday,
+	}, nil)
+	return calendar
+}
+
+// Holiday returns the holiday name when the calendar date of day is listed.
+func (c *TradingCalendar) Holiday(day time.Time) (string, bool) {
+	name, ok := c.holidays[day.In(c.location).Format("2006-01-02")]
+	return name, ok
+}
+
+// IsTradingDay reports whether the calendar date of day has a session.
+func (c *TradingCalendar) IsTradingDay(day time.Time) bool {
+	day = day.In(c.location)
+	if _, holiday := c.Holiday(day); holiday {
+		return false
+	}
+	return c.weekdays[day.Weekday()]
+}
+
+// Session returns the session on the calendar date of day, whether or not
+// that date trades.
+func (c *TradingCalendar) Session(day time.Time) DateRange {
+	day = day.In(c.location)
+	return DateRange{Start: c.clockTime(day, c.open), End: c.clockTime(day, c.close)}
+}
+
+func (c *TradingCalendar) clockTime(day time.Time, offset time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(),
+		int(offset/time.Hour), int(offset%time.Hour/time.Minute), 0, 0, c.location)
+}
+
+// NextSession is the session in progress at at, or the first one after it.
+func (c *TradingCalendar) NextSession(at time.Time) (DateRange, bool) {
+	day := at.In(c.location)
+	for i := 0; i < maxCalendarScan; i++ {
+		if c.IsTradingDay(day) {
+			if session := c.Session(day); session.End.After(at) {
+				return session, true
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return DateRange{}, false
+}
+
+// LastSession is the session in progress at at, or the last one that opened
+// before it.
+func (c *TradingCalendar) LastSession(at time.Time) (DateRange, bool) {
+	day := at.In(c.location)
+	for i := 0; i < maxCalendarScan; i++ {
+		if c.IsTradingDay(day) {
+			if session := c.Session(day); !session.Start.After(at) {
+				return session, true
+			}
+		}
+		day = day.AddDate(0, 0, -1)
+	}
+	return DateRange{}, false
+}
+
+// PreviousClose is the end of the session before the latest one to open, so
+// that day change on a weekend or before the open still describes the last
+// trading day.
+func (c *TradingCalendar) PreviousClose(at time.Time) time.Time {
+	latest, ok := c.LastSession(at)
+	if !ok {
+		return time.Time{}
+	}
+	previous, ok := c.LastSession(latest.Start.Add(-time.Nanosecond))
+	if !ok {
+		return time.Time{}
+	}
+	return previous.End
+}
+
+func (c *TradingCalendar) Status(at time.Time) models.MarketStatus {
+	status := models.MarketStatus{PreviousCloseAt: c.PreviousClose(at)}
+
+	session := c.Session(at)
+	nextFrom := at
+	switch {
+	case !c.IsTradingDay(at):
+		status.Status = models.MarketStatusNonTrading
+		if name, holiday := c.Holiday(at); holiday {
+			status.Status = models.MarketStatusHoliday
+			status.Holiday = name
+		}
+	case at.Before(session.Start):
+		status.Status = models.MarketStatusPreOpen
+	case session.Contains(at):
+		status.Status = models.MarketStatusOpen
+		status.ClosesAt = &session.End
+		nextFrom = session.End
+	default:
+		status.Status = models.MarketStatusClosed
+	}
+
+	if next, ok := c.NextSession(nextFrom); ok {
+		status.NextOpen = next.Start
+	}
+
+	return status
+}
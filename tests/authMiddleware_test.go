@@ -0,0 +1,212 @@
+package tests
+
+import (
+	"assignment/initializers"
+	"assignment/middleware"
+	"assignment/models"
+	"assignment/routes"
+	"assignment/services"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+const testAuthSecret = "test-secret-that-is-at-least-32-bytes"
+
+func setupAuthRouter(t *testing.T) (*gin.Engine, initializers.AuthConfig) {
+	return setupAuthRouterAt(t, services.SystemClock{})
+}
+
+func setupAuthRouterAt(t *testing.T, clock services.Clock) (*gin.Engine, initializers.AuthConfig) {
+	t.Setenv("AUTH_JWT_SECRET", testAuthSecret)
+	t.Setenv("AUTH_JWT_KEYS", "2026-10="+strings.Repeat("k", 32))
+	t.Setenv("AUTH_JWT_ISSUER", "rewards-api")
+	config, err := initializers.LoadAuthConfig()
+	assert.NoError(t, err)
+
+	ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"subject": middleware.Claims(c).Subject}) }
+
+	auth := middleware.NewAuth(config, clock, newTestLogger())
+	router := setupRouter()
+	router.Use(auth.Authenticate())
+	service := router.Group("", auth.RequireRoles(models.RoleService, models.RoleAdmin))
+	service.POST("/reward", ok)
+	user := router.Group("", auth.RequireSelfOrRoles("userId", models.RoleService, models.RoleAdmin))
+	user.GET("/portfolio/:userId", ok)
+	admin := router.Group("/admin", auth.RequireRoles(models.RoleAdmin))
+	admin.GET("/instruments", ok)
+
+	return router, config
+}
+
+func issueTestToken(t *testing.T, config initializers.AuthConfig, subject, role string, ttl time.Duration) string {
+	t.Helper()
+
+	token, err := services.SignToken(config, models.Claims{
+		Subject:   subject,
+		Role:      role,
+		ExpiresAt: time.Now().Add(ttl).Unix(),
+	})
+	assert.NoError(t, err)
+	return token
+}
+
+func sendAuthRequest(router *gin.Engine, method, path, token string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func assertAuthError(t *testing.T, w *httptest.ResponseRecorder, status int, message string) {
+	t.Helper()
+
+	assert.Equal(t, status, w.Code, w.Body.String())
+	var body map[string]string
+	err := json.Unmarshal(w.Body.Bytes(), &body)
+	assert.NoError(t, err)
+	assert.Equal(t, message, body["error"])
+	assert.NotEmpty(t, body["details"])
+}
+
+func TestAuthChecksExpiryAgainstClock(t *testing.T) {
+	later := services.FixedClock(time.Now().Add(2 * time.Hour))
+	router, config := setupAuthRouterAt(t, later)
+
+	token := issueTestToken(t, config, "user123", models.RoleUser, time.Hour)
+	w := sendAuthRequest(router, "GET", "/portfolio/user123", token)
+	assertAuthError(t, w, http.StatusUnauthorized, "Unauthorized")
+	assert.Contains(t, w.Body.String(), "expired")
+
+	token = issueTestToken(t, config, "user123", models.RoleUser, 3*time.Hour)
+	w = sendAuthRequest(router, "GET", "/portfolio/user123", token)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestAuthRejectsMissingAndInvalidTokens(t *testing.T) {
+	router, config := setupAuthRouter(t)
+
+	w := sendAuthRequest(router, "GET", "/portfolio/user123", "")
+	assertAuthError(t, w, http.StatusUnauthorized, "Unauthorized")
+	assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Bearer")
+
+	w = sendAuthRequest(router, "GET", "/portfolio/user123", "not-a-token")
+	assertAuthError(t, w, http.StatusUnauthorized, "Unauthorized")
+
+	expired := issueTestToken(t, config, "user123", models.RoleUser, -time.Hour)
+	w = sendAuthRequest(router, "GET", "/portfolio/user123", expired)
+	assertAuthError(t, w, http.StatusUnauthorized, "Unauthorized")
+	assert.Contains(t, w.Body.String(), "expired")
+
+	valid := issueTestToken(t, config, "user123", models.RoleUser, time.Hour)
+	parts := strings.Split(valid, ".")
+	forged, _ := json.Marshal(models.Claims{Subject: "user123", Role: models.RoleAdmin, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	tampered := parts[0] + "." + base64.RawURLEncoding.EncodeToString(forged) + "." + parts[2]
+	w = sendAuthRequest(router, "GET", "/admin/instruments", tampered)
+	assertAuthError(t, w, http.StatusUnauthorized, "Unauthorized")
+
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + parts[1] + "."
+	w = sendAuthRequest(router, "GET", "/portfolio/user123", unsigned)
+	assertAuthError(t, w, http.StatusUnauthorized, "Unauthorized")
+
+	otherIssuer := config
+	otherIssuer.Issuer = "someone-else"
+	w = sendAuthRequest(router, "GET", "/portfolio/user123", issueTestToken(t, otherIssuer, "user123", models.RoleUser, time.Hour))
+	assertAuthError(t, w, http.StatusUnauthorized, "Unauthorized")
+}
+
+func TestAuthEnforcesRoles(t *testing.T) {
+	router, config := setupAuthRouter(t)
+
+	user := issueTestToken(t, config, "user123", models.RoleUser, time.Hour)
+	service := issueTestToken(t, config, "rewards-engine", models.RoleService, time.Hour)
+	admin := issueTestToken(t, config, "ops", models.RoleAdmin, time.Hour)
+
+	w := sendAuthRequest(router, "GET", "/portfolio/user123", user)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = sendAuthRequest(router, "GET", "/portfolio/user456", user)
+	assertAuthError(t, w, http.StatusForbidden, "Forbidden")
+
+	w = sendAuthRequest(router, "POST", "/reward", user)
+	assertAuthError(t, w, http.StatusForbidden, "Forbidden")
+
+	w = sendAuthRequest(router, "POST", "/reward", service)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = sendAuthRequest(router, "GET", "/portfolio/user456", service)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = sendAuthRequest(router, "GET", "/admin/instruments", service)
+	assertAuthError(t, w, http.StatusForbidden, "Forbidden")
+
+	w = sendAuthRequest(router, "GET", "/admin/instruments", admin)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = sendAuthRequest(router, "GET", "/portfolio/user456", admin)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestReverseRewardRequiresAdmin(t *testing.T) {
+	t.Parallel()
+
+	config := initializers.AuthConfig{Keys: map[string][]byte{"": []byte(testAuthSecret)}}
+	router := routes.New(newTestApp(t, setupTestDB(t)), config)
+
+	reverse := func(token string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/reward/missing/reverse", strings.NewReader(`{"reason": "Issued in error"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := reverse(issueTestToken(t, config, "rewards-engine", models.RoleService, time.Hour))
+	assertAuthError(t, w, http.StatusForbidden, "Forbidden")
+
+	w = reverse(issueTestToken(t, config, "ops", models.RoleAdmin, time.Hour))
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+}
+
+func TestAuthAcceptsRotatedKeys(t *testing.T) {
+	router, config := setupAuthRouter(t)
+
+	rotated := config
+	rotated.SigningKey = "2026-10"
+	w := sendAuthRequest(router, "GET", "/portfolio/user123", issueTestToken(t, rotated, "user123", models.RoleUser, time.Hour))
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","kid":"retired"}`))
+	payload, _ := json.Marshal(models.Claims{Subject: "user123", Role: models.RoleUser, Issuer: "rewards-api", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, []byte(testAuthSecret))
+	mac.Write([]byte(unsigned))
+	w = sendAuthRequest(router, "GET", "/portfolio/user123", unsigned+"."+base64.RawURLEncoding.EncodeToString(mac.Sum(nil)))
+	assertAuthError(t, w, http.StatusUnauthorized, "Unauthorized")
+}
+
+func TestAuthConfigRequiresStrongKey(t *testing.T) {
+	t.Setenv("AUTH_JWT_SECRET", "")
+	t.Setenv("AUTH_JWT_KEYS", "")
+	_, err := initializers.LoadAuthConfig()
+	assert.Error(t, err)
+
+	t.Setenv("AUTH_JWT_SECRET", "short")
+	_, err = initializers.LoadAuthConfig()
+	assert.Error(t, err)
+
+	t.Setenv("AUTH_JWT_SECRET", testAuthSecret)
+	t.Setenv("AUTH_JWT_SIGNING_KID", "missing")
+	_, err = initializers.LoadAuthConfig()
+	assert.Error(t, err)
+}
@@ -0,0 +1,262 @@
+package tests
+
+import (
+	"assignment/controllers"
+	"assignment/models"
+	"assignment/services"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupCampaignRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	provider, err := services.NewFilePriceProvider(writePriceFile(t, "prices.csv", "TCS,1000\nRELIANCE,2500\n"))
+	assert.NoError(t, err)
+	usePriceProvider(a, provider)
+
+	campaignController := controllers.NewCampaignController(a)
+	rewardController := controllers.NewRewardController(a)
+	router := setupRouter()
+	router.POST("/reward", rewardController.RewardUser)
+	router.POST("/reward/:id/reverse", rewardController.ReverseReward)
+	router.GET("/admin/campaigns", campaignController.ListCampaigns)
+	router.GET("/admin/campaigns/spend", campaignController.ListCampaignSpend)
+	router.GET("/admin/campaigns/:id", campaignController.GetCampaign)
+	router.GET("/admin/campaigns/:id/spend", campaignController.GetCampaignSpend)
+	router.POST("/admin/campaigns", campaignController.CreateCampaign)
+	router.PUT("/admin/campaigns/:id", campaignController.UpdateCampaign)
+	router.DELETE("/admin/campaigns/:id", campaignController.DeleteCampaign)
+
+	return router, db
+}
+
+func sendCampaignRequest(router *gin.Engine, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func createTestCampaign(t *testing.T, router *gin.Engine, req models.CampaignRequest) {
+	t.Helper()
+
+	w := sendCampaignRequest(router, "POST", "/admin/campaigns", req)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+}
+
+func postCampaignReward(router *gin.Engine, id, symbol, quantity, campaignID string) (*httptest.ResponseRecorder, models.RewardResponse) {
+	w := sendCampaignRequest(router, "POST", "/reward", models.RewardRequest{
+		ID:              id,
+		UserID:          "user123",
+		StockSymbol:     symbol,
+		Quantity:        dec(quantity),
+		RewardTimestamp: time.Now(),
+		CampaignID:      campaignID,
+	})
+
+	var response models.RewardResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return w, response
+}
+
+func TestCampaignCRUD(t *testing.T) {
+	t.Parallel()
+
+	router, _ := setupCampaignRouter(t)
+
+	start := time.Now().AddDate(0, 0, -1).UTC().Truncate(time.Second)
+	req := models.CampaignRequest{
+		ID:              "DIWALI",
+		Name:            "Diwali 2026",
+		Budget:          dec("100000"),
+		StartDate:       start,
+		EligibleSymbols: []string{"tcs", "RELIANCE", "TCS"},
+	}
+
+	w := sendCampaignRequest(router, "POST", "/admin/campaigns", req)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var campaign models.Campaign
+	json.Unmarshal(w.Body.Bytes(), &campaign)
+	assert.Equal(t, []string{"RELIANCE", "TCS"}, campaign.EligibleSymbols)
+	assert.Equal(t, models.CampaignStatusActive, campaign.Status)
+
+	w = sendCampaignRequest(router, "POST", "/admin/campaigns", req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	invalid := req
+	invalid.ID = "UNKNOWN-SYMBOL"
+	invalid.EligibleSymbols = []string{"NOPE"}
+	w = sendCampaignRequest(router, "POST", "/admin/campaigns", invalid)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	invalid = req
+	invalid.ID = "BACKWARDS"
+	end := start.AddDate(0, 0, -2)
+	invalid.EndDate = &end
+	w = sendCampaignRequest(router, "POST", "/admin/campaigns", invalid)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	w = sendCampaignRequest(router, "GET", "/admin/campaigns/DIWALI", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = sendCampaignRequest(router, "GET", "/admin/campaigns/MISSING", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	update := req
+	update.ID = "OTHER"
+	w = sendCampaignRequest(router, "PUT", "/admin/campaigns/DIWALI", update)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	update = req
+	update.Status = models.CampaignStatusPaused
+	update.Budget = dec("50000")
+	w = sendCampaignRequest(router, "PUT", "/admin/campaigns/DIWALI", update)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	json.Unmarshal(w.Body.Bytes(), &campaign)
+	assertDecimal(t, "50000", campaign.Budget)
+	assert.Equal(t, models.CampaignStatusPaused, campaign.Status)
+
+	var campaigns []models.Campaign
+	w = sendCampaignRequest(router, "GET", "/admin/campaigns?status=active", nil)
+	json.Unmarshal(w.Body.Bytes(), &campaigns)
+	assert.Empty(t, campaigns)
+
+	w = sendCampaignRequest(router, "DELETE", "/admin/campaigns/DIWALI", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &campaign)
+	assert.Equal(t, models.CampaignStatusEnded, campaign.Status)
+}
+
+func TestRewardCampaignEligibility(t *testing.T) {
+	t.Parallel()
+
+	router, db := setupCampaignRouter(t)
+
+	createTestCampaign(t, router, models.CampaignRequest{
+		ID: "TECH", Name: "Tech", StartDate: time.Now().AddDate(0, 0, -1), EligibleSymbols: []string{"TCS"},
+	})
+	createTestCampaign(t, router, models.CampaignRequest{
+		ID: "PAUSED", Name: "Paused", StartDate: time.Now().AddDate(0, 0, -1), Status: models.CampaignStatusPaused,
+	})
+	createTestCampaign(t, router, models.CampaignRequest{
+		ID: "UPCOMING", Name: "Upcoming", StartDate: time.Now().AddDate(0, 0, 7),
+	})
+
+	w, response := postCampaignReward(router, "elig-1", "TCS", "1", "MISSING")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, response.Message, "unknown campaign")
+
+	w, response = postCampaignReward(router, "elig-2", "RELIANCE", "1", "TECH")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, response.Message, "not eligible")
+
+	w, _ = postCampaignReward(router, "elig-3", "TCS", "1", "PAUSED")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	w, _ = postCampaignReward(router, "elig-4", "TCS", "1", "UPCOMING")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	var count int64
+	db.Model(&models.StockReward{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	w, response = postCampaignReward(router, "elig-5", "TCS", "1", "TECH")
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, "TECH", *response.Reward.CampaignID)
+}
+
+func TestCampaignTotalBudget(t *testing.T) {
+	t.Parallel()
+
+	router, _ := setupCampaignRouter(t)
+
+	createTestCampaign(t, router, models.CampaignRequest{
+		ID: "LAUNCH", Name: "Launch", Budget: dec("5000"), StartDate: time.Now().AddDate(0, 0, -1),
+	})
+
+	w, _ := postCampaignReward(router, "budget-1", "TCS", "3", "LAUNCH")
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w, response := postCampaignReward(router, "budget-2", "TCS", "3", "LAUNCH")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, models.LimitCodeCampaignTotal, response.Code)
+
+	w = sendCampaignRequest(router, "POST", "/reward/budget-1/reverse", models.ReverseRewardRequest{Reason: "Issued in error"})
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w, _ = postCampaignReward(router, "budget-3", "TCS", "3", "LAUNCH")
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+}
+
+func TestCampaignSpendReport(t *testing.T) {
+	t.Parallel()
+
+	router, _ := setupCampaignRouter(t)
+
+	createTestCampaign(t, router, models.CampaignRequest{
+		ID: "DIWALI", Name: "Diwali", Budget: dec("10000"), StartDate: time.Now().AddDate(0, 0, -1),
+	})
+	createTestCampaign(t, router, models.CampaignRequest{
+		ID: "REFERRAL", Name: "Referral", StartDate: time.Now().AddDate(0, 0, -1),
+	})
+
+	w, kept := postCampaignReward(router, "spend-1", "TCS", "2", "DIWALI")
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w, _ = postCampaignReward(router, "spend-2", "TCS", "3", "DIWALI")
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w, _ = postCampaignReward(router, "spend-3", "TCS", "1", "")
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = sendCampaignRequest(router, "POST", "/reward/spend-2/reverse", models.ReverseRewardRequest{Reason: "Duplicate"})
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = sendCampaignRequest(router, "GET", "/admin/campaigns/DIWALI/spend", nil)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var spend models.CampaignSpend
+	err := json.Unmarshal(w.Body.Bytes(), &spend)
+	assert.NoError(t, err)
+
+	charges := kept.CompanyCharges
+	assert.Equal(t, int64(1), spend.ActiveRewards)
+	assert.Equal(t, int64(1), spend.ReversedRewards)
+	assertDecimal(t, "2000", spend.StockCost)
+	assertDecimal(t, charges.Brokerage.String(), spend.Brokerage)
+	assertDecimal(t, charges.STT.String(), spend.STT)
+	assertDecimal(t, charges.GST.String(), spend.GST)
+	assertDecimal(t, charges.TotalCost.Sub(charges.StockCost).String(), spend.TotalCharges)
+	assertDecimal(t, charges.TotalCost.String(), spend.TotalCost)
+	assertDecimal(t, "8000", *spend.RemainingBudget)
+	assert.True(t, spend.Brokerage.IsPositive())
+	assert.True(t, spend.GST.IsPositive())
+
+	w = sendCampaignRequest(router, "GET", "/admin/campaigns/spend", nil)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var spends []models.CampaignSpend
+	json.Unmarshal(w.Body.Bytes(), &spends)
+	assert.Len(t, spends, 2)
+	assert.Equal(t, "REFERRAL", spends[1].CampaignID)
+	assertDecimal(t, "0", spends[1].TotalCost)
+	assert.Nil(t, spends[1].RemainingBudget)
+
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	w = sendCampaignRequest(router, "GET", "/admin/campaigns/DIWALI/spend?from="+future, nil)
+	json.Unmarshal(w.Body.Bytes(), &spend)
+	assertDecimal(t, "0", spend.TotalCost)
+
+	w = sendCampaignRequest(router, "GET", "/admin/campaigns/MISSING/spend", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,557 @@
+package tests
+
+import (
+	"assignment/controllers"
+	"assignment/decimal"
+	"assignment/initializers"
+	"assignment/models"
+	"assignment/services"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupCorporateActionRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	corporateActionController := controllers.NewCorporateActionController(a)
+	router := setupRouter()
+	router.GET("/admin/corporate-actions", corporateActionController.ListCorporateActions)
+	router.GET("/admin/corporate-actions/:id", corporateActionController.GetCorporateAction)
+	router.POST("/admin/corporate-actions", corporateActionController.CreateCorporateAction)
+	router.POST("/admin/corporate-actions/:id/apply", corporateActionController.ApplyCorporateAction)
+	router.GET("/portfolio/:userId", controllers.NewPortfolioController(a).GetUserPortfolio)
+	router.GET("/stats/:userId", controllers.NewStatsController(a).GetUserStats)
+	router.GET("/historical-inr/:userId", controllers.NewHistoricalController(a).GetHistoricalINR)
+	router.POST("/reward", controllers.NewRewardController(a).RewardUser)
+
+	return router, db
+}
+
+func createCorporateAction(t *testing.T, router *gin.Engine, body map[string]interface{}) models.CorporateAction {
+	t.Helper()
+
+	payload, _ := json.Marshal(body)
+	req, _ := http.NewRequest("POST", "/admin/corporate-actions", bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var action models.CorporateAction
+	err := json.Unmarshal(w.Body.Bytes(), &action)
+	assert.NoError(t, err)
+	return action
+}
+
+func applyCorporateAction(router *gin.Engine, id uint) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/admin/corporate-actions/%d/apply", id), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func assertPortfolioQuantities(t *testing.T, router *gin.Engine, userID string, expected map[string]string) {
+	t.Helper()
+
+	req, _ := http.NewRequest("GET", "/portfolio/"+userID, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.PortfolioResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	assert.Equal(t, len(expected), len(response.Holdings))
+	for _, holding := range response.Holdings {
+		if assert.Contains(t, expected, holding.StockSymbol) {
+			assertDecimal(t, expected[holding.StockSymbol], holding.TotalQuantity)
+		}
+	}
+}
+
+func startOfDay(daysAgo int) time.Time {
+	now := time.Now().In(businessLocation())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return today.AddDate(0, 0, -daysAgo)
+}
+
+func TestApplySplitAdjustsPortfolioStatsAndHistory(t *testing.T) {
+	t.Parallel()
+
+	router, db := setupCorporateActionRouter(t)
+
+	createRewardWithLedger(t, db, models.StockReward{
+		ID:                 "split-1",
+		UserID:             "user123",
+		StockSymbol:        "RELIANCE",
+		Quantity:           dec("10"),
+		RewardTimestamp:    startOfDay(3).Add(10 * time.Hour),
+		StockPriceAtReward: dec("2500"),
+	})
+	createRewardWithLedger(t, db, models.StockReward{
+		ID:                 "split-2",
+		UserID:             "user123",
+		StockSymbol:        "RELIANCE",
+		Quantity:           dec("1"),
+		RewardTimestamp:    time.Now(),
+		StockPriceAtReward: dec("1300"),
+	})
+
+	err := db.Create(&models.StockPrice{StockSymbol: "RELIANCE", Price: dec("2600"), Timestamp: startOfDay(2).Add(12 * time.Hour)}).Error
+	assert.NoError(t, err)
+
+	action := createCorporateAction(t, router, map[string]interface{}{
+		"action_type":       "SPLIT",
+		"stock_symbol":      "reliance",
+		"ratio_numerator":   "2",
+		"ratio_denominator": "1",
+		"ex_date":           startOfDay(1).Format(time.RFC3339),
+	})
+	assert.Equal(t, models.CorporateActionStatusPending, action.Status)
+
+	w := applyCorporateAction(router, action.ID)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var applied models.CorporateAction
+	err = json.Unmarshal(w.Body.Bytes(), &applied)
+	assert.NoError(t, err)
+	assert.Equal(t, models.CorporateActionStatusApplied, applied.Status)
+	assert.Equal(t, 1, applied.LotsAdjusted)
+	assert.NotNil(t, applied.AppliedAt)
+
+	assertPortfolioQuantities(t, router, "user123", map[string]string{"RELIANCE": "21"})
+
+	var reward models.StockReward
+	err = db.Where("id = ?", "split-1").First(&reward).Error
+	assert.NoError(t, err)
+	assertDecimal(t, "10", reward.Quantity)
+
+	req, _ := http.NewRequest("GET", "/stats/user123", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var stats models.StatsResponse
+	err = json.Unmarshal(w.Body.Bytes(), &stats)
+	assert.NoError(t, err)
+	assertDecimal(t, "21", stats.TotalSharesRewarded)
+
+	req, _ = http.NewRequest("GET", "/historical-inr/user123", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var history models.HistoricalINRResponse
+	err = json.Unmarshal(w.Body.Bytes(), &history)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(history.DailyValues))
+	assertDecimal(t, "25000", history.DailyValues[startOfDay(3).Format("2006-01-02")])
+	assertDecimal(t, "26000", history.DailyValues[startOfDay(2).Format("2006-01-02")])
+	assertDecimal(t, "26000", history.DailyValues[startOfDay(1).Format("2006-01-02")])
+
+	run, err := services.RunReconciliation(db, services.SystemClock{})
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReconciliationStatusClean, run.Status)
+}
+
+func TestApplyBonusAddsSharesPerLot(t *testing.T) {
+	t.Parallel()
+
+	router, db := setupCorporateActionRouter(t)
+
+	createRewardWithLedger(t, db, models.StockReward{
+		ID:                 "bonus-1",
+		UserID:             "user123",
+		StockSymbol:        "ITC",
+		Quantity:           dec("10"),
+		RewardTimestamp:    startOfDay(5).Add(10 * time.Hour),
+		StockPriceAtReward: dec("450"),
+	})
+	createRewardWithLedger(t, db, models.StockReward{
+		ID:                 "bonus-2",
+		UserID:             "user456",
+		StockSymbol:        "ITC",
+		Quantity:           dec("3"),
+		RewardTimestamp:    startOfDay(4).Add(10 * time.Hour),
+		StockPriceAtReward: dec("450"),
+	})
+
+	action := createCorporateAction(t, router, map[string]interface{}{
+		"action_type":       "BONUS",
+		"stock_symbol":      "ITC",
+		"ratio_numerator":   "1",
+		"ratio_denominator": "2",
+		"ex_date":           startOfDay(2).Format(time.RFC3339),
+	})
+
+	w := applyCorporateAction(router, action.ID)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	assertPortfolioQuantities(t, router, "user123", map[string]string{"ITC": "15"})
+	assertPortfolioQuantities(t, router, "user456", map[string]string{"ITC": "4.5"})
+
+	var entries []models.LedgerEntry
+	err := db.Where("entry_type = ?", models.EntryTypeCorporateAction).Order("reward_id").Find(&entries).Error
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, fmt.Sprintf("CA-%d", action.ID), entries[0].ReferenceID)
+	assertDecimal(t, "5", *entries[0].Quantity)
+	assertDecimal(t, "0", entries[0].DebitAmount)
+}
+
+func TestApplyMergerMovesHoldingsToNewSymbol(t *testing.T) {
+	t.Parallel()
+
+	router, db := setupCorporateActionRouter(t)
+
+	createRewardWithLedger(t, db, models.StockReward{
+		ID:                 "merger-1",
+		UserID:             "user123",
+		StockSymbol:        "HDFC",
+		Quantity:           dec("10"),
+		RewardTimestamp:    startOfDay(3).Add(10 * time.Hour),
+		StockPriceAtReward: dec("2700"),
+	})
+	createRewardWithLedger(t, db, models.StockReward{
+		ID:                 "merger-2",
+		UserID:             "user123",
+		StockSymbol:        "HDFCBANK",
+		Quantity:           dec("5"),
+		RewardTimestamp:    startOfDay(3).Add(11 * time.Hour),
+		StockPriceAtReward: dec("1600"),
+	})
+
+	action := createCorporateAction(t, router, map[string]interface{}{
+		"action_type":       "MERGER",
+		"stock_symbol":      "HDFC",
+		"new_symbol":        "HDFCBANK",
+		"ratio_numerator":   "42",
+		"ratio_denominator": "25",
+		"ex_date":           startOfDay(1).Format(time.RFC3339),
+	})
+
+	w := applyCorporateAction(router, action.ID)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	assertPortfolioQuantities(t, router, "user123", map[string]string{"HDFCBANK": "21.8"})
+
+	var instrument models.Instrument
+	err := db.Where("symbol = ?", "HDFC").First(&instrument).Error
+	assert.NoError(t, err)
+	assert.Equal(t, models.InstrumentStatusDelisted, instrument.Status)
+
+	var entries []models.LedgerEntry
+	err = db.Where("entry_type = ?", models.EntryTypeCorporateAction).Order("id").Find(&entries).Error
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(entries))
+	assertDecimal(t, "-10", *entries[0].Quantity)
+	assertDecimal(t, "16.8", *entries[1].Quantity)
+	assertDecimal(t, "27000", entries[0].CreditAmount)
+	assertDecimal(t, "27000", entries[1].DebitAmount)
+
+	positions, err := services.GetUserPositions(db, "user123", startOfDay(0))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(positions))
+	assertDecimal(t, "35000", positions[0].BookValue)
+	assertDecimal(t, "21.8", positions[0].CarriedQuantity)
+
+	run, err := services.RunReconciliation(db, services.SystemClock{})
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReconciliationStatusClean, run.Status)
+
+	_, _, err = services.ReverseReward(db, services.SystemClock{}, noRewardLimits(), "merger-1", "Issued in error")
+	assert.NoError(t, err)
+	assertPortfolioQuantities(t, router, "user123", map[string]string{"HDFCBANK": "5"})
+
+	run, err = services.RunReconciliation(db, services.SystemClock{})
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReconciliationStatusClean, run.Status)
+}
+
+func TestMigrationsKeepReversedMergerBalanced(t *testing.T) {
+	t.Parallel()
+
+	router, db := setupCorporateActionRouter(t)
+
+	createRewardWithLedger(t, db, models.StockReward{
+		ID:                 "migrate-merger",
+		UserID:             "user123",
+		StockSymbol:        "HDFC",
+		Quantity:           dec("10"),
+		RewardTimestamp:    startOfDay(3).Add(10 * time.Hour),
+		StockPriceAtReward: dec("2700"),
+	})
+	action := createCorporateAction(t, router, map[string]interface{}{
+		"action_type":       "MERGER",
+		"stock_symbol":      "HDFC",
+		"new_symbol":        "HDFCBANK",
+		"ratio_numerator":   "1",
+		"ratio_denominator": "1",
+		"ex_date":           startOfDay(1).Format(time.RFC3339),
+	})
+	w := applyCorporateAction(router, action.ID)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	_, _, err := services.ReverseReward(db, services.SystemClock{}, noRewardLimits(), "migrate-merger", "Issued in error")
+	assert.NoError(t, err)
+
+	createRewardWithLedger(t, db, models.StockReward{
+		ID:                 "migrate-legacy",
+		UserID:             "user456",
+		StockSymbol:        "TCS",
+		Quantity:           dec("2"),
+		RewardTimestamp:    startOfDay(2),
+		StockPriceAtReward: dec("3500"),
+	})
+	err = db.Exec(`INSERT INTO ledger_entries (reward_id, entry_type, account_type, stock_symbol, debit_amount, credit_amount, quantity, created_at)
+		VALUES (?, ?, ?, ?, 0, 7000, 2, ?)`,
+		"migrate-legacy", models.EntryTypeReversal, models.AccountTypeStockAsset, "TCS", startOfDay(1).UTC()).Error
+	assert.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, initializers.RunMigrations(db, newTestLogger()))
+	}
+
+	netQuantity := func(symbol string) decimal.Decimal {
+		var entries []models.LedgerEntry
+		assert.NoError(t, db.Where("account_type = ? AND stock_symbol = ?", models.AccountTypeStockAsset, symbol).Find(&entries).Error)
+		total := decimal.Zero
+		for _, entry := range entries {
+			total = total.Add(*entry.Quantity)
+		}
+		return total
+	}
+	assertDecimal(t, "0", netQuantity("HDFC"))
+	assertDecimal(t, "0", netQuantity("HDFCBANK"))
+	assertDecimal(t, "0", netQuantity("TCS"))
+
+	var legacy models.LedgerEntry
+	assert.NoError(t, db.Where("reward_id = ? AND entry_type = ?", "migrate-legacy", models.EntryTypeReversal).First(&legacy).Error)
+	assert.True(t, startOfDay(1).Equal(legacy.EffectiveAt))
+}
+
+func TestApplySymbolChangeSkipsRewardsOnOrAfterExDate(t *testing.T) {
+	t.Parallel()
+
+	router, db := setupCorporateActionRouter(t)
+
+	err := db.Create(&models.Instrument{
+		Symbol:   "BHARTIAIR",
+		ISIN:     "INE397D01025",
+		Exchange: "NSE",
+		Name:     "Bharti Airtel Limited",
+		LotSize:  1,
+		Status:   models.InstrumentStatusActive,
+	}).Error
+	assert.NoError(t, err)
+
+	createRewardWithLedger(t, db, models.StockReward{
+		ID:                 "rename-1",
+		UserID:             "user123",
+		StockSymbol:        "BHARTI",
+		Quantity:           dec("4"),
+		RewardTimestamp:    startOfDay(3).Add(10 * time.Hour),
+		StockPriceAtReward: dec("900"),
+	})
+	createRewardWithLedger(t, db, models.StockReward{
+		ID:                 "rename-2",
+		UserID:             "user123",
+		StockSymbol:        "BHARTI",
+		Quantity:           dec("2"),
+		RewardTimestamp:    startOfDay(1).Add(10 * time.Hour),
+		StockPriceAtReward: dec("900"),
+	})
+
+	action := createCorporateAction(t, router, map[string]interface{}{
+		"action_type":  "SYMBOL_CHANGE",
+		"stock_symbol": "BHARTI",
+		"new_symbol":   "BHARTIAIR",
+		"ex_date":      startOfDay(2).Format(time.RFC3339),
+	})
+	assertDecimal(t, "1", action.RatioNumerator)
+
+	w := applyCorporateAction(router, action.ID)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	assertPortfolioQuantities(t, router, "user123", map[string]string{"BHARTI": "2", "BHARTIAIR": "4"})
+}
+
+func TestApplyCorporateActionTwiceConflicts(t *testing.T) {
+	t.Parallel()
+
+	router, db := setupCorporateActionRouter(t)
+
+	createRewardWithLedger(t, db, models.StockReward{
+		ID:                 "twice-1",
+		UserID:             "user123",
+		StockSymbol:        "TCS",
+		Quantity:           dec("3"),
+		RewardTimestamp:    startOfDay(3).Add(10 * time.Hour),
+		StockPriceAtReward: dec("3500"),
+	})
+
+	action := createCorporateAction(t, router, map[string]interface{}{
+		"action_type":       "SPLIT",
+		"stock_symbol":      "TCS",
+		"ratio_numerator":   "5",
+		"ratio_denominator": "1",
+		"ex_date":           startOfDay(1).Format(time.RFC3339),
+	})
+
+	w := applyCorporateAction(router, action.ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = applyCorporateAction(router, action.ID)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	assertPortfolioQuantities(t, router, "user123", map[string]string{"TCS": "15"})
+
+	w = applyCorporateAction(router, 999)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestCreateCorporateActionValidation(t *testing.T) {
+	t.Parallel()
+
+	router, _ := setupCorporateActionRouter(t)
+
+	tests := []struct {
+		name         string
+		body         map[string]interface{}
+		expectedCode int
+	}{
+		{
+			name:         "unknown action type",
+			body:         map[string]interface{}{"action_type": "DIVIDEND", "stock_symbol": "TCS", "ex_date": time.Now().Format(time.RFC3339)},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "split without ratio",
+			body:         map[string]interface{}{"action_type": "SPLIT", "stock_symbol": "TCS", "ex_date": time.Now().Format(time.RFC3339)},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "unknown symbol",
+			body:         map[string]interface{}{"action_type": "SPLIT", "stock_symbol": "NOPE", "ratio_numerator": "2", "ratio_denominator": "1", "ex_date": time.Now().Format(time.RFC3339)},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "merger into unknown symbol",
+			body:         map[string]interface{}{"action_type": "MERGER", "stock_symbol": "HDFC", "new_symbol": "NOPE", "ratio_numerator": "1", "ratio_denominator": "1", "ex_date": time.Now().Format(time.RFC3339)},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "symbol change without new symbol",
+			body:         map[string]interface{}{"action_type": "SYMBOL_CHANGE", "stock_symbol": "HDFC", "ex_date": time.Now().Format(time.RFC3339)},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, _ := json.Marshal(tt.body)
+			req, _ := http.NewRequest("POST", "/admin/corporate-actions", bytes.NewBuffer(payload))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestApplyCorporateActionTiming(t *testing.T) {
+	t.Parallel()
+
+	router, db := setupCorporateActionRouter(t)
+
+	createRewardWithLedger(t, db, models.StockReward{
+		ID: "timing-1", UserID: "user123", StockSymbol: "HDFC", Quantity: dec("10"),
+		RewardTimestamp: startOfDay(5).Add(10 * time.Hour), StockPriceAtReward: dec("2700"),
+	})
+
+	future := createCorporateAction(t, router, map[string]interface{}{
+		"action_type":       "SPLIT",
+		"stock_symbol":      "HDFC",
+		"ratio_numerator":   "2",
+		"ratio_denominator": "1",
+		"ex_date":           startOfDay(-2).Format(time.RFC3339),
+	})
+	w := applyCorporateAction(router, future.ID)
+	assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	assertPortfolioQuantities(t, router, "user123", map[string]string{"HDFC": "10"})
+
+	merger := createCorporateAction(t, router, map[string]interface{}{
+		"action_type":       "MERGER",
+		"stock_symbol":      "HDFC",
+		"new_symbol":        "HDFCBANK",
+		"ratio_numerator":   "1",
+		"ratio_denominator": "1",
+		"ex_date":           startOfDay(2).Format(time.RFC3339),
+	})
+
+	prices := services.NewPriceService(nil, services.NewRandomPriceProvider(), services.SystemClock{})
+	_, _, err := services.CreateRedemption(db, services.SystemClock{}, prices, models.RedemptionRequest{
+		ID: "timing-sale", UserID: "user123", StockSymbol: "HDFC", Quantity: dec("4"),
+	})
+	assert.NoError(t, err)
+
+	w = applyCorporateAction(router, merger.ID)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assertPortfolioQuantities(t, router, "user123", map[string]string{"HDFCBANK": "6"})
+
+	var remaining decimal.Decimal
+	err = db.Model(&models.LedgerEntry{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("account_type = ? AND stock_symbol = ?", models.AccountTypeStockAsset, "HDFC").
+		Scan(&remaining).Error
+	assert.NoError(t, err)
+	assertDecimal(t, "0", remaining)
+}
+
+func TestRewardBeforeAppliedExDateIsRejected(t *testing.T) {
+	t.Parallel()
+
+	router, _ := setupCorporateActionRouter(t)
+
+	split := createCorporateAction(t, router, map[string]interface{}{
+		"action_type":       "SPLIT",
+		"stock_symbol":      "TCS",
+		"ratio_numerator":   "2",
+		"ratio_denominator": "1",
+		"ex_date":           startOfDay(2).Format(time.RFC3339),
+	})
+	w := applyCorporateAction(router, split.ID)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	postReward := func(id string, at time.Time) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(models.RewardRequest{
+			ID: id, UserID: "user123", StockSymbol: "TCS", Quantity: dec("1"), RewardTimestamp: at,
+		})
+		req, _ := http.NewRequest("POST", "/reward", bytes.NewBuffer(payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w = postReward("before-split", startOfDay(3))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "before an applied corporate action")
+
+	w = postReward("after-split", startOfDay(1))
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assertPortfolioQuantities(t, router, "user123", map[string]string{"TCS": "1"})
+}
@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"assignment/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mustLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+
+	location, err := time.LoadLocation(name)
+	assert.NoError(t, err)
+	return location
+}
+
+func TestDayRangeAroundISTMidnight(t *testing.T) {
+	t.Parallel()
+
+	ist := mustLocation(t, "Asia/Kolkata")
+
+	beforeMidnight := time.Date(2025, 11, 16, 18, 29, 59, 0, time.UTC)
+	day := services.DayRange(beforeMidnight, ist)
+	assert.Equal(t, time.Date(2025, 11, 15, 18, 30, 0, 0, time.UTC), day.Start.UTC())
+	assert.Equal(t, time.Date(2025, 11, 16, 18, 30, 0, 0, time.UTC), day.End.UTC())
+	assert.Equal(t, "2025-11-16", day.Start.Format("2006-01-02"))
+	assert.True(t, day.Contains(beforeMidnight))
+
+	midnight := time.Date(2025, 11, 16, 18, 30, 0, 0, time.UTC)
+	assert.False(t, day.Contains(midnight))
+	day = services.DayRange(midnight, ist)
+	assert.Equal(t, midnight, day.Start.UTC())
+	assert.Equal(t, "2025-11-17", day.Start.Format("2006-01-02"))
+
+	utcDay := services.DayRange(midnight, time.UTC)
+	assert.Equal(t, time.Date(2025, 11, 16, 0, 0, 0, 0, time.UTC), utcDay.Start)
+	assert.Equal(t, "2025-11-16", utcDay.Start.Format("2006-01-02"))
+
+	earlyUTC := time.Date(2025, 11, 17, 0, 30, 0, 0, time.UTC)
+	assert.Equal(t, "2025-11-17", services.DayRange(earlyUTC, ist).Start.Format("2006-01-02"))
+	assert.Equal(t, "2025-11-17", services.DayRange(earlyUTC, time.UTC).Start.Format("2006-01-02"))
+	lateUTC := time.Date(2025, 11, 16, 23, 0, 0, 0, time.UTC)
+	assert.Equal(t, "2025-11-17", services.DayRange(lateUTC, ist).Start.Format("2006-01-02"))
+	assert.Equal(t, "2025-11-16", services.DayRange(lateUTC, time.UTC).Start.Format("2006-01-02"))
+}
+
+func TestDayRangeHandlesShortDays(t *testing.T) {
+	t.Parallel()
+
+	newYork := mustLocation(t, "America/New_York")
+
+	day := services.DayRange(time.Date(2025, 3, 9, 12, 0, 0, 0, newYork), newYork)
+	assert.Equal(t, 23*time.Hour, day.End.Sub(day.Start))
+}
+
+func TestMonthRangeAroundISTMidnight(t *testing.T) {
+	t.Parallel()
+
+	ist := mustLocation(t, "Asia/Kolkata")
+
+	month := services.MonthRange(time.Date(2025, 11, 30, 18, 30, 0, 0, time.UTC), ist)
+	assert.Equal(t, time.Date(2025, 11, 30, 18, 30, 0, 0, time.UTC), month.Start.UTC())
+	assert.Equal(t, time.Date(2025, 12, 31, 18, 30, 0, 0, time.UTC), month.End.UTC())
+
+	month = services.MonthRange(time.Date(2025, 11, 30, 18, 29, 59, 0, time.UTC), ist)
+	assert.Equal(t, time.November, month.Start.Month())
+}
+
+func TestResolveLocation(t *testing.T) {
+	t.Parallel()
+
+	location, err := services.ResolveLocation("", businessLocation())
+	assert.NoError(t, err)
+	assert.Equal(t, "Asia/Kolkata", location.String())
+
+	location, err = services.ResolveLocation("UTC", businessLocation())
+	assert.NoError(t, err)
+	assert.Equal(t, "UTC", location.String())
+
+	_, err = services.ResolveLocation("Mars/Olympus_Mons", businessLocation())
+	assert.ErrorIs(t, err, services.ErrInvalidTimezone)
+	_, err = services.ResolveLocation("Local", businessLocation())
+	assert.ErrorIs(t, err, services.ErrInvalidTimezone)
+}
@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"assignment/decimal"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecimalParseAndString(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"0", "0"},
+		{"10.50", "10.50"},
+		{"-0.005", "-0.005"},
+		{"+42", "42"},
+		{".5", "0.5"},
+		{"1.5e3", "1500"},
+		{"25e-4", "0.0025"},
+		{"1e28", "10000000000000000000000000000"},
+		{"1e-28", "0.0000000000000000000000000001"},
+	}
+
+	for _, tc := range testCases {
+		d, err := decimal.NewFromString(tc.input)
+		assert.NoError(t, err, tc.input)
+		assert.Equal(t, tc.expected, d.String(), tc.input)
+	}
+
+	for _, invalid := range []string{"", "abc", "1.2.3", "1,000", "--1", "1e-2147483648", "1e30000000", "1e29", "1e-29"} {
+		_, err := decimal.NewFromString(invalid)
+		assert.Error(t, err, invalid)
+	}
+
+	assert.Equal(t, "0", decimal.Zero.String())
+	assert.Equal(t, "0.1", decimal.NewFromFloat(0.1).String())
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	t.Parallel()
+
+	a := dec("0.1")
+	b := dec("0.2")
+
+	assertDecimal(t, "0.3", a.Add(b))
+	assertDecimal(t, "-0.1", a.Sub(b))
+	assertDecimal(t, "0.02", a.Mul(b))
+	assertDecimal(t, "0.33", dec("1").Div(dec("3"), 2))
+	assertDecimal(t, "-0.67", dec("-2").Div(dec("3"), 2))
+	assertDecimal(t, "0.1", a.Abs())
+	assertDecimal(t, "-0.1", a.Neg())
+
+	assert.True(t, a.LessThan(b))
+	assert.True(t, dec("1.50").Equal(dec("1.5")))
+	assert.Equal(t, 0, decimal.Zero.Sign())
+	assertDecimal(t, "0.2", decimal.Max(a, b))
+	assertDecimal(t, "0.1", decimal.Min(a, b))
+
+	assert.Panics(t, func() { a.Div(decimal.Zero, 2) })
+}
+
+func TestDecimalRoundBank(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		input    string
+		places   int32
+		expected string
+	}{
+		{"2.345", 2, "2.34"},
+		{"2.355", 2, "2.36"},
+		{"2.3451", 2, "2.35"},
+		{"-2.345", 2, "-2.34"},
+		{"-2.355", 2, "-2.36"},
+		{"0.5", 0, "0"},
+		{"1.5", 0, "2"},
+		{"7.1", 3, "7.100"},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, dec(tc.input).RoundBank(tc.places).String(), tc.input)
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	t.Parallel()
+
+	payload := struct {
+		Amount decimal.Decimal `json:"amount"`
+	}{Amount: dec("1234.50")}
+
+	body, err := json.Marshal(payload)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"amount":"1234.50"}`, string(body))
+
+	var decoded struct {
+		Amount  decimal.Decimal `json:"amount"`
+		Numeric decimal.Decimal `json:"numeric"`
+	}
+	err = json.Unmarshal([]byte(`{"amount":"1234.50","numeric":10.25}`), &decoded)
+	assert.NoError(t, err)
+	assertDecimal(t, "1234.5", decoded.Amount)
+	assertDecimal(t, "10.25", decoded.Numeric)
+
+	err = json.Unmarshal([]byte(`{"amount":"ten"}`), &decoded)
+	assert.Error(t, err)
+}
+
+func TestDecimalScan(t *testing.T) {
+	t.Parallel()
+
+	var d decimal.Decimal
+
+	assert.NoError(t, d.Scan("12.3400"))
+	assertDecimal(t, "12.34", d)
+
+	assert.NoError(t, d.Scan([]byte("-1.5")))
+	assertDecimal(t, "-1.5", d)
+
+	assert.NoError(t, d.Scan(10.25))
+	assertDecimal(t, "10.25", d)
+
+	assert.NoError(t, d.Scan(int64(7)))
+	assertDecimal(t, "7", d)
+
+	assert.NoError(t, d.Scan(nil))
+	assert.True(t, d.IsZero())
+
+	assert.Error(t, d.Scan(true))
+
+	value, err := dec("99.99").Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "99.99", value)
+}
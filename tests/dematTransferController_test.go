@@ -0,0 +1,421 @@
+package tests
+
+import (
+	"assignment/controllers"
+	"assignment/models"
+	"assignment/services"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type fakeDepository struct {
+	mu           sync.Mutex
+	instructions []services.DepositoryInstruction
+	states       map[string]services.DepositoryStatus
+	submitState  string
+	submitErr    error
+}
+
+func newFakeDepository() *fakeDepository {
+	return &fakeDepository{states: make(map[string]services.DepositoryStatus)}
+}
+
+func (f *fakeDepository) Submit(instruction services.DepositoryInstruction) (services.DepositoryStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.submitErr != nil {
+		return services.DepositoryStatus{}, f.submitErr
+	}
+
+	f.instructions = append(f.instructions, instruction)
+	status := services.DepositoryStatus{Reference: "FAKE-" + instruction.TransferID, State: services.DepositoryStatePending}
+	if f.submitState != "" {
+		status.State = f.submitState
+		status.Reason = "Beneficiary account closed"
+	}
+	f.states[status.Reference] = status
+	return status, nil
+}
+
+func (f *fakeDepository) Status(reference string) (services.DepositoryStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	status, ok := f.states[reference]
+	if !ok {
+		return services.DepositoryStatus{}, errors.New("unknown reference")
+	}
+	return status, nil
+}
+
+func (f *fakeDepository) settle(reference, state string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.states[reference] = services.DepositoryStatus{Reference: reference, State: state}
+}
+
+func setupTransferRouter(t *testing.T) (*gin.Engine, *gorm.DB, *fakeDepository) {
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	provider, err := services.NewFilePriceProvider(writePriceFile(t, "prices.csv", "TCS,4000\n"))
+	assert.NoError(t, err)
+	usePriceProvider(a, provider)
+
+	depository := newFakeDepository()
+	a.Depository = depository
+
+	dematTransferController := controllers.NewDematTransferController(a)
+	router := setupRouter()
+	router.POST("/transfers", dematTransferController.RequestDematTransfer)
+	router.GET("/transfers/:userId", dematTransferController.GetUserDematTransfers)
+	router.GET("/admin/transfers", dematTransferController.ListDematTransfers)
+	router.GET("/admin/transfers/:id", dematTransferController.GetDematTransfer)
+	router.POST("/admin/transfers/:id/approve", dematTransferController.ApproveDematTransfer)
+	router.POST("/admin/transfers/:id/submit", dematTransferController.SubmitDematTransfer)
+	router.POST("/admin/transfers/:id/refresh", dematTransferController.RefreshDematTransfer)
+	router.POST("/admin/transfers/:id/reject", dematTransferController.RejectDematTransfer)
+	router.POST("/redemptions", controllers.NewRedemptionController(a).RedeemShares)
+	router.GET("/portfolio/:userId", controllers.NewPortfolioController(a).GetUserPortfolio)
+
+	return router, db, depository
+}
+
+func postTransfer(router *gin.Engine, body map[string]interface{}) (*httptest.ResponseRecorder, models.DematTransferResponse) {
+	payload, _ := json.Marshal(body)
+	req, _ := http.NewRequest("POST", "/transfers", bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response models.DematTransferResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return w, response
+}
+
+func runTransferStep(router *gin.Engine, id, step string, body string) (*httptest.ResponseRecorder, models.DematTransfer) {
+	req, _ := http.NewRequest("POST", "/admin/transfers/"+id+"/"+step, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var transfer models.DematTransfer
+	json.Unmarshal(w.Body.Bytes(), &transfer)
+	return w, transfer
+}
+
+func getPortfolioHolding(t *testing.T, router *gin.Engine, userID, symbol string) models.UserStockHolding {
+	t.Helper()
+
+	req, _ := http.NewRequest("GET", "/portfolio/"+userID, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.PortfolioResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	for _, holding := range response.Holdings {
+		if holding.StockSymbol == symbol {
+			return holding
+		}
+	}
+	t.Fatalf("no %s holding for %s", symbol, userID)
+	return models.UserStockHolding{}
+}
+
+func createTransferLots(t *testing.T, db *gorm.DB) {
+	createRewardWithLedger(t, db, models.StockReward{
+		ID: "trf-1", UserID: "user123", StockSymbol: "TCS", Quantity: dec("3"),
+		RewardTimestamp: startOfDay(5).Add(10 * time.Hour), StockPriceAtReward: dec("3500"),
+	})
+	createRewardWithLedger(t, db, models.StockReward{
+		ID: "trf-2", UserID: "user123", StockSymbol: "TCS", Quantity: dec("2"),
+		RewardTimestamp: startOfDay(2).Add(10 * time.Hour), StockPriceAtReward: dec("3600"),
+	})
+}
+
+func transferBody(id, quantity string) map[string]interface{} {
+	return map[string]interface{}{
+		"id":                        id,
+		"user_id":                   "user123",
+		"stock_symbol":              "tcs",
+		"quantity":                  quantity,
+		"depository_participant_id": "in300214",
+		"beneficiary_account":       "10203040",
+	}
+}
+
+func TestDematTransferLifecycle(t *testing.T) {
+	t.Parallel()
+
+	router, db, depository := setupTransferRouter(t)
+	createTransferLots(t, db)
+
+	w, response := postTransfer(router, transferBody("transfer-1", "4"))
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	transfer := response.Transfer
+	assert.Equal(t, models.TransferStatusRequested, transfer.Status)
+	assert.Equal(t, "TCS", transfer.StockSymbol)
+	assert.Equal(t, "IN300214", transfer.DepositoryParticipantID)
+	assertDecimal(t, "14100", transfer.CostBasis)
+	if assert.Equal(t, 2, len(transfer.Lots)) {
+		assert.Equal(t, "trf-1", transfer.Lots[0].RewardID)
+		assertDecimal(t, "3", transfer.Lots[0].Quantity)
+		assertDecimal(t, "1", transfer.Lots[1].Quantity)
+		assertDecimal(t, "3600", transfer.Lots[1].CostBasis)
+	}
+
+	holding := getPortfolioHolding(t, router, "user123", "TCS")
+	assertDecimal(t, "5", holding.TotalQuantity)
+	assertDecimal(t, "4", holding.LockedQuantity)
+	assertDecimal(t, "1", holding.FreeQuantity)
+
+	w, redemption := postRedemption(router, map[string]interface{}{
+		"id": "redeem-locked", "user_id": "user123", "stock_symbol": "TCS", "quantity": "2",
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, redemption.Message, "available 1")
+
+	_, _, err := services.ReverseReward(db, services.SystemClock{}, noRewardLimits(), "trf-2", "Issued in error")
+	assert.ErrorIs(t, err, services.ErrRewardSharesLocked)
+
+	w, _ = runTransferStep(router, "transfer-1", "submit", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w, transfer = runTransferStep(router, "transfer-1", "approve", "")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, models.TransferStatusApproved, transfer.Status)
+	assert.NotNil(t, transfer.ApprovedAt)
+
+	w, _ = runTransferStep(router, "transfer-1", "approve", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w, transfer = runTransferStep(router, "transfer-1", "submit", "")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, models.TransferStatusSubmitted, transfer.Status)
+	assert.Equal(t, "FAKE-transfer-1", transfer.DepositoryReference)
+	if assert.Equal(t, 1, len(depository.instructions)) {
+		assert.Equal(t, "10203040", depository.instructions[0].BeneficiaryAccount)
+		assert.NotEmpty(t, depository.instructions[0].ISIN)
+		assertDecimal(t, "4", depository.instructions[0].Quantity)
+	}
+
+	w, transfer = runTransferStep(router, "transfer-1", "refresh", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.TransferStatusSubmitted, transfer.Status)
+
+	depository.settle("FAKE-transfer-1", services.DepositoryStateCompleted)
+	w, transfer = runTransferStep(router, "transfer-1", "refresh", "")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, models.TransferStatusCompleted, transfer.Status)
+	assert.NotNil(t, transfer.CompletedAt)
+
+	var entries []models.LedgerEntry
+	err = db.Where("entry_type = ?", models.EntryTypeTransfer).Order("id").Find(&entries).Error
+	assert.NoError(t, err)
+	if assert.Equal(t, 4, len(entries)) {
+		assert.Equal(t, models.AccountTypeStockAsset, entries[0].AccountType)
+		assertDecimal(t, "-3", *entries[0].Quantity)
+		assertDecimal(t, "10500", entries[0].CreditAmount)
+		assert.Equal(t, models.AccountTypeTransferredOut, entries[1].AccountType)
+		assertDecimal(t, "10500", entries[1].DebitAmount)
+		assert.Equal(t, "TRF-transfer-1", entries[3].ReferenceID)
+	}
+
+	holding = getPortfolioHolding(t, router, "user123", "TCS")
+	assertDecimal(t, "1", holding.TotalQuantity)
+	assertDecimal(t, "0", holding.LockedQuantity)
+	assertDecimal(t, "1", holding.FreeQuantity)
+	assertDecimal(t, "3600", holding.TotalInvested)
+
+	trialBalance, err := services.GetTrialBalance(db, time.Now())
+	assert.NoError(t, err)
+	assert.True(t, trialBalance.Balanced)
+
+	run, err := services.RunReconciliation(db, services.SystemClock{})
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReconciliationStatusClean, run.Status)
+
+	_, _, err = services.ReverseReward(db, services.SystemClock{}, noRewardLimits(), "trf-1", "Issued in error")
+	assert.ErrorIs(t, err, services.ErrRewardSharesDisposed)
+
+	w, redemption = postRedemption(router, map[string]interface{}{
+		"id": "redeem-free", "user_id": "user123", "stock_symbol": "TCS", "quantity": "1",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assertDecimal(t, "3600", redemption.Redemption.CostBasis)
+}
+
+func TestDematTransferFailuresReleaseLock(t *testing.T) {
+	t.Parallel()
+
+	router, db, depository := setupTransferRouter(t)
+	createTransferLots(t, db)
+
+	w, _ := postTransfer(router, transferBody("transfer-2", "5"))
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w, _ = postTransfer(router, transferBody("transfer-3", "0.5"))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	w, _ = runTransferStep(router, "transfer-2", "reject", `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w, transfer := runTransferStep(router, "transfer-2", "reject", `{"reason": "KYC mismatch"}`)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, models.TransferStatusFailed, transfer.Status)
+	assert.Equal(t, "KYC mismatch", transfer.FailureReason)
+
+	w, _ = runTransferStep(router, "transfer-2", "reject", `{"reason": "again"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	holding := getPortfolioHolding(t, router, "user123", "TCS")
+	assertDecimal(t, "0", holding.LockedQuantity)
+	assertDecimal(t, "5", holding.FreeQuantity)
+
+	w, _ = postTransfer(router, transferBody("transfer-3", "2.5"))
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	runTransferStep(router, "transfer-3", "approve", "")
+
+	depository.submitErr = errors.New("connection refused")
+	w, _ = runTransferStep(router, "transfer-3", "submit", "")
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+
+	var stored models.DematTransfer
+	db.Where("id = ?", "transfer-3").First(&stored)
+	assert.Equal(t, models.TransferStatusApproved, stored.Status)
+
+	depository.submitErr = nil
+	depository.submitState = services.DepositoryStateFailed
+	w, transfer = runTransferStep(router, "transfer-3", "submit", "")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, models.TransferStatusFailed, transfer.Status)
+	assert.Equal(t, "Beneficiary account closed", transfer.FailureReason)
+
+	var count int64
+	db.Model(&models.LedgerEntry{}).Where("entry_type = ?", models.EntryTypeTransfer).Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	holding = getPortfolioHolding(t, router, "user123", "TCS")
+	assertDecimal(t, "5", holding.FreeQuantity)
+
+	_, _, err := services.ReverseReward(db, services.SystemClock{}, noRewardLimits(), "trf-2", "Issued in error")
+	assert.NoError(t, err)
+}
+
+func TestDematTransferIdempotencyAndValidation(t *testing.T) {
+	t.Parallel()
+
+	router, db, _ := setupTransferRouter(t)
+	createTransferLots(t, db)
+
+	body := transferBody("transfer-4", "1.5")
+	w, first := postTransfer(router, body)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w, replay := postTransfer(router, body)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, first.Transfer.ID, replay.Transfer.ID)
+	assert.Equal(t, len(first.Transfer.Lots), len(replay.Transfer.Lots))
+
+	body["beneficiary_account"] = "99999999"
+	w, _ = postTransfer(router, body)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	missing := transferBody("transfer-5", "1")
+	delete(missing, "depository_participant_id")
+	w, _ = postTransfer(router, missing)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w, _ = runTransferStep(router, "missing", "approve", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	req, _ := http.NewRequest("GET", "/transfers/user123", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var transfers []models.DematTransfer
+	err := json.Unmarshal(w.Body.Bytes(), &transfers)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(transfers))
+
+	req, _ = http.NewRequest("GET", "/admin/transfers?status=requested", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	err = json.Unmarshal(w.Body.Bytes(), &transfers)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(transfers))
+}
+
+func TestCorporateActionWaitsForPendingTransfer(t *testing.T) {
+	t.Parallel()
+
+	router, db, _ := setupTransferRouter(t)
+	createTransferLots(t, db)
+
+	w, _ := postTransfer(router, transferBody("transfer-ca", "4"))
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	split := models.CorporateAction{
+		ActionType: models.CorporateActionSplit, StockSymbol: "TCS",
+		RatioNumerator: dec("2"), RatioDenominator: dec("1"),
+		ExDate: startOfDay(1), Status: models.CorporateActionStatusPending,
+	}
+	assert.NoError(t, db.Create(&split).Error)
+
+	_, err := services.ApplyCorporateAction(db, services.SystemClock{}, split.ID)
+	assert.ErrorIs(t, err, services.ErrCorporateActionBlocked)
+
+	var stored models.CorporateAction
+	assert.NoError(t, db.First(&stored, split.ID).Error)
+	assert.Equal(t, models.CorporateActionStatusPending, stored.Status)
+
+	w, _ = runTransferStep(router, "transfer-ca", "reject", `{"reason": "Client request"}`)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	action, err := services.ApplyCorporateAction(db, services.SystemClock{}, split.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, action.LotsAdjusted)
+
+	holding := getPortfolioHolding(t, router, "user123", "TCS")
+	assertDecimal(t, "10", holding.FreeQuantity)
+}
+
+func TestHTTPDepositoryAdapterRequiresReference(t *testing.T) {
+	t.Parallel()
+
+	reference := ""
+	depository := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"reference": reference, "state": "PENDING"})
+	}))
+	t.Cleanup(depository.Close)
+
+	adapter := services.NewHTTPDepositoryAdapter(depository.URL, "", time.Second)
+	instruction := services.DepositoryInstruction{TransferID: "transfer-1", StockSymbol: "TCS", Quantity: dec("1")}
+
+	_, err := adapter.Submit(instruction)
+	assert.ErrorContains(t, err, "no reference")
+
+	reference = "NSDL-1"
+	status, err := adapter.Submit(instruction)
+	assert.NoError(t, err)
+	assert.Equal(t, "NSDL-1", status.Reference)
+	assert.Equal(t, services.DepositoryStatePending, status.State)
+}
@@ -0,0 +1,374 @@
+package tests
+
+import (
+	"assignment/app"
+	"assignment/commands"
+	"assignment/controllers"
+	"assignment/models"
+	"assignment/services"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupDividendRouter(t *testing.T) (*gin.Engine, *app.App) {
+	a := newTestApp(t, setupTestDB(t))
+
+	dividendController := controllers.NewDividendController(a)
+	router := setupRouter()
+	router.GET("/dividends/:userId", dividendController.GetUserDividends)
+	router.GET("/admin/dividends", dividendController.ListDividendDeclarations)
+	router.GET("/admin/dividends/:id", dividendController.GetDividendDeclaration)
+	router.POST("/admin/dividends", dividendController.CreateDividendDeclaration)
+	router.POST("/admin/dividends/:id/accrue", dividendController.AccrueDividend)
+	router.POST("/admin/dividends/:id/pay", dividendController.PayDividend)
+	router.GET("/ledger/trial-balance", controllers.NewLedgerController(a).GetTrialBalance)
+
+	return router, a
+}
+
+func declareDividend(t *testing.T, router *gin.Engine, body map[string]interface{}) models.DividendDeclaration {
+	t.Helper()
+
+	payload, _ := json.Marshal(body)
+	req, _ := http.NewRequest("POST", "/admin/dividends", bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var declaration models.DividendDeclaration
+	err := json.Unmarshal(w.Body.Bytes(), &declaration)
+	assert.NoError(t, err)
+	return declaration
+}
+
+func postDividendStep(router *gin.Engine, id uint, step string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/admin/dividends/%d/%s", id, step), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func getUserDividends(t *testing.T, router *gin.Engine, userID string) models.UserDividendsResponse {
+	t.Helper()
+
+	req, _ := http.NewRequest("GET", "/dividends/"+userID, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.UserDividendsResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	return response
+}
+
+func TestDividendAccrualAndPayout(t *testing.T) {
+	t.Parallel()
+
+	router, a := setupDividendRouter(t)
+	db := a.DB
+
+	recordDate := startOfDay(2)
+	createRewardWithLedger(t, db, models.StockReward{
+		ID: "div-1", UserID: "user123", StockSymbol: "ITC", Quantity: dec("10"),
+		RewardTimestamp: recordDate.AddDate(0, 0, -5), StockPriceAtReward: dec("450"),
+	})
+	createRewardWithLedger(t, db, models.StockReward{
+		ID: "div-2", UserID: "user123", StockSymbol: "ITC", Quantity: dec("2.5"),
+		RewardTimestamp: recordDate.AddDate(0, 0, -3), StockPriceAtReward: dec("455"),
+	})
+	createRewardWithLedger(t, db, models.StockReward{
+		ID: "div-3", UserID: "user456", StockSymbol: "ITC", Quantity: dec("4"),
+		RewardTimestamp: recordDate.AddDate(0, 0, -3), StockPriceAtReward: dec("455"),
+	})
+	createRewardWithLedger(t, db, models.StockReward{
+		ID: "div-late", UserID: "user123", StockSymbol: "ITC", Quantity: dec("100"),
+		RewardTimestamp: recordDate.Add(time.Hour), StockPriceAtReward: dec("460"),
+	})
+	createRewardWithLedger(t, db, models.StockReward{
+		ID: "div-other", UserID: "user123", StockSymbol: "TCS", Quantity: dec("1"),
+		RewardTimestamp: recordDate.AddDate(0, 0, -5), StockPriceAtReward: dec("3500"),
+	})
+
+	declaration := declareDividend(t, router, map[string]interface{}{
+		"stock_symbol":     "itc",
+		"ex_date":          recordDate.Format(time.RFC3339),
+		"record_date":      recordDate.Format(time.RFC3339),
+		"payment_date":     recordDate.AddDate(0, 0, 1).Format(time.RFC3339),
+		"amount_per_share": "6.25",
+	})
+	assert.Equal(t, models.DividendStatusDeclared, declaration.Status)
+
+	w := postDividendStep(router, declaration.ID, "pay")
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = postDividendStep(router, declaration.ID, "accrue")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var accrued models.DividendDeclaration
+	err := json.Unmarshal(w.Body.Bytes(), &accrued)
+	assert.NoError(t, err)
+	assert.Equal(t, models.DividendStatusAccrued, accrued.Status)
+	assert.Equal(t, 3, accrued.EntitlementsCount)
+	assertDecimal(t, "103.12", accrued.TotalAmount)
+	assert.NotNil(t, accrued.AccruedAt)
+
+	response := getUserDividends(t, router, "user123")
+	assert.Equal(t, 1, len(response.Dividends))
+	assertDecimal(t, "12.5", response.Dividends[0].Quantity)
+	assertDecimal(t, "78.12", response.Dividends[0].Amount)
+	assert.Equal(t, models.DividendStatusAccrued, response.Dividends[0].Status)
+	assertDecimal(t, "78.12", response.TotalAccrued)
+	assertDecimal(t, "0", response.TotalPaid)
+
+	w = postDividendStep(router, declaration.ID, "accrue")
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = postDividendStep(router, declaration.ID, "pay")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	response = getUserDividends(t, router, "user123")
+	assert.Equal(t, models.DividendStatusPaid, response.Dividends[0].Status)
+	assert.NotNil(t, response.Dividends[0].PaidAt)
+	assertDecimal(t, "0", response.TotalAccrued)
+	assertDecimal(t, "78.12", response.TotalPaid)
+
+	balances, err := services.GetAccountBalances(db, time.Now())
+	assert.NoError(t, err)
+	for _, balance := range balances {
+		if balance.AccountType == models.AccountTypeDividendReceivable || balance.AccountType == models.AccountTypeDividendPayable {
+			assertDecimal(t, "0", balance.Balance)
+			assertDecimal(t, "103.12", balance.TotalDebit)
+		}
+	}
+
+	trialBalance, err := services.GetTrialBalance(db, time.Now())
+	assert.NoError(t, err)
+	assert.True(t, trialBalance.Balanced)
+	assert.Empty(t, trialBalance.UnbalancedRewards)
+
+	run, err := services.RunReconciliation(db, services.SystemClock{})
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReconciliationStatusClean, run.Status)
+}
+
+func TestReversedRewardForfeitsAccruedDividend(t *testing.T) {
+	t.Parallel()
+
+	router, a := setupDividendRouter(t)
+	db := a.DB
+
+	recordDate := startOfDay(2)
+	createRewardWithLedger(t, db, models.StockReward{
+		ID: "div-kept", UserID: "user123", StockSymbol: "ITC", Quantity: dec("10"),
+		RewardTimestamp: recordDate.AddDate(0, 0, -5), StockPriceAtReward: dec("450"),
+	})
+	createRewardWithLedger(t, db, models.StockReward{
+		ID: "div-revoked", UserID: "user123", StockSymbol: "ITC", Quantity: dec("4"),
+		RewardTimestamp: recordDate.AddDate(0, 0, -5), StockPriceAtReward: dec("450"),
+	})
+
+	declaration := declareDividend(t, router, map[string]interface{}{
+		"stock_symbol":     "ITC",
+		"ex_date":          recordDate.Format(time.RFC3339),
+		"record_date":      recordDate.Format(time.RFC3339),
+		"amount_per_share": "5",
+	})
+	w := postDividendStep(router, declaration.ID, "accrue")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	_, entries, err := services.ReverseReward(db, a.Clock, a.Limits, "div-revoked", "Issued in error")
+	assert.NoError(t, err)
+
+	var cancelled models.DividendEntitlement
+	assert.NoError(t, db.Where("reward_id = ?", "div-revoked").First(&cancelled).Error)
+	assert.Equal(t, models.DividendStatusCancelled, cancelled.Status)
+	dividendReversals := 0
+	for _, entry := range entries {
+		if entry.ReferenceID == fmt.Sprintf("DIV-%d", declaration.ID) {
+			dividendReversals++
+		}
+	}
+	assert.Equal(t, 2, dividendReversals)
+
+	w = postDividendStep(router, declaration.ID, "pay")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var paid models.DividendDeclaration
+	err = json.Unmarshal(w.Body.Bytes(), &paid)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, paid.EntitlementsCount)
+	assertDecimal(t, "50", paid.TotalAmount)
+
+	assert.NoError(t, db.First(&cancelled, cancelled.ID).Error)
+	assert.Equal(t, models.DividendStatusCancelled, cancelled.Status)
+	assert.Nil(t, cancelled.PaidAt)
+
+	var paidOut int64
+	db.Model(&models.LedgerEntry{}).
+		Where("reward_id = ? AND entry_type = ? AND account_type = ?", "div-revoked", models.EntryTypeDividend, models.AccountTypeCashAccount).
+		Count(&paidOut)
+	assert.Equal(t, int64(0), paidOut)
+
+	response := getUserDividends(t, router, "user123")
+	assert.Equal(t, 1, len(response.Dividends))
+	assertDecimal(t, "10", response.Dividends[0].Quantity)
+	assertDecimal(t, "50", response.TotalPaid)
+	assertDecimal(t, "0", response.TotalAccrued)
+
+	balances, err := services.GetAccountBalances(db, time.Now())
+	assert.NoError(t, err)
+	for _, balance := range balances {
+		if balance.AccountType == models.AccountTypeDividendReceivable || balance.AccountType == models.AccountTypeDividendPayable {
+			assertDecimal(t, "0", balance.Balance)
+		}
+	}
+
+	trialBalance, err := services.GetTrialBalance(db, time.Now())
+	assert.NoError(t, err)
+	assert.True(t, trialBalance.Balanced)
+	assert.Empty(t, trialBalance.UnbalancedRewards)
+}
+
+func TestDividendEntitlementUsesAdjustedHoldings(t *testing.T) {
+	t.Parallel()
+
+	router, a := setupDividendRouter(t)
+	db := a.DB
+
+	createRewardWithLedger(t, db, models.StockReward{
+		ID: "div-split", UserID: "user123", StockSymbol: "WIPRO", Quantity: dec("3"),
+		RewardTimestamp: startOfDay(10), StockPriceAtReward: dec("500"),
+	})
+	createRewardWithLedger(t, db, models.StockReward{
+		ID: "div-reversed", UserID: "user123", StockSymbol: "WIPRO", Quantity: dec("7"),
+		RewardTimestamp: startOfDay(10), StockPriceAtReward: dec("500"),
+	})
+	_, _, err := services.ReverseReward(db, a.Clock, a.Limits, "div-reversed", "Issued in error")
+	assert.NoError(t, err)
+
+	split := models.CorporateAction{
+		ActionType: models.CorporateActionSplit, StockSymbol: "WIPRO",
+		RatioNumerator: dec("2"), RatioDenominator: dec("1"),
+		ExDate: startOfDay(5), Status: models.CorporateActionStatusPending,
+	}
+	assert.NoError(t, db.Create(&split).Error)
+	_, err = services.ApplyCorporateAction(db, a.Clock, split.ID)
+	assert.NoError(t, err)
+
+	declaration := declareDividend(t, router, map[string]interface{}{
+		"stock_symbol":     "WIPRO",
+		"ex_date":          startOfDay(3).Format(time.RFC3339),
+		"record_date":      startOfDay(3).Format(time.RFC3339),
+		"amount_per_share": "1.5",
+	})
+
+	code := commands.Run(a, []string{"dividends"})
+	assert.Equal(t, 0, code)
+
+	response := getUserDividends(t, router, "user123")
+	assert.Equal(t, 1, len(response.Dividends))
+	assert.Equal(t, declaration.ID, response.Dividends[0].DividendID)
+	assertDecimal(t, "6", response.Dividends[0].Quantity)
+	assertDecimal(t, "9", response.Dividends[0].Amount)
+	assert.Equal(t, models.DividendStatusAccrued, response.Dividends[0].Status)
+}
+
+func TestProcessDueDividendsSkipsFutureDates(t *testing.T) {
+	t.Parallel()
+
+	router, a := setupDividendRouter(t)
+	db := a.DB
+
+	createRewardWithLedger(t, db, models.StockReward{
+		ID: "due-1", UserID: "user123", StockSymbol: "SBIN", Quantity: dec("2"),
+		RewardTimestamp: startOfDay(10), StockPriceAtReward: dec("600"),
+	})
+
+	past := declareDividend(t, router, map[string]interface{}{
+		"stock_symbol":     "SBIN",
+		"ex_date":          startOfDay(4).Format(time.RFC3339),
+		"record_date":      startOfDay(4).Format(time.RFC3339),
+		"payment_date":     startOfDay(2).Format(time.RFC3339),
+		"amount_per_share": "3",
+	})
+	future := declareDividend(t, router, map[string]interface{}{
+		"stock_symbol":     "SBIN",
+		"ex_date":          time.Now().AddDate(0, 0, 7).Format(time.RFC3339),
+		"record_date":      time.Now().AddDate(0, 0, 7).Format(time.RFC3339),
+		"amount_per_share": "4",
+	})
+
+	now := time.Now().UTC().Truncate(time.Second)
+	result, err := services.ProcessDueDividends(db, services.FixedClock(now))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(result.Accrued))
+	assert.Equal(t, 1, len(result.Paid))
+	assert.Equal(t, past.ID, result.Paid[0].ID)
+	assert.True(t, now.Equal(*result.Paid[0].PaidAt), "paid at %s, want %s", result.Paid[0].PaidAt, now)
+
+	var stored models.DividendDeclaration
+	assert.NoError(t, db.Where("id = ?", future.ID).First(&stored).Error)
+	assert.Equal(t, models.DividendStatusDeclared, stored.Status)
+
+	response := getUserDividends(t, router, "user123")
+	assertDecimal(t, "6", response.TotalPaid)
+}
+
+func TestCreateDividendDeclarationValidation(t *testing.T) {
+	t.Parallel()
+
+	router, _ := setupDividendRouter(t)
+
+	tests := []struct {
+		name         string
+		body         map[string]interface{}
+		expectedCode int
+	}{
+		{
+			name:         "missing amount",
+			body:         map[string]interface{}{"stock_symbol": "TCS", "ex_date": "2025-06-01T00:00:00Z", "record_date": "2025-06-01T00:00:00Z"},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "unknown symbol",
+			body:         map[string]interface{}{"stock_symbol": "NOPE", "ex_date": "2025-06-01T00:00:00Z", "record_date": "2025-06-01T00:00:00Z", "amount_per_share": "1"},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "record date before ex date",
+			body:         map[string]interface{}{"stock_symbol": "TCS", "ex_date": "2025-06-02T00:00:00Z", "record_date": "2025-06-01T00:00:00Z", "amount_per_share": "1"},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "payment date before record date",
+			body:         map[string]interface{}{"stock_symbol": "TCS", "ex_date": "2025-06-01T00:00:00Z", "record_date": "2025-06-01T00:00:00Z", "payment_date": "2025-05-01T00:00:00Z", "amount_per_share": "1"},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, _ := json.Marshal(tt.body)
+			req, _ := http.NewRequest("POST", "/admin/dividends", bytes.NewBuffer(payload))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code, w.Body.String())
+		})
+	}
+
+	w := postDividendStep(router, 999, "accrue")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
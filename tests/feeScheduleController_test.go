@@ -0,0 +1,196 @@
+package tests
+
+import (
+	"assignment/app"
+	"assignment/controllers"
+	"assignment/models"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupFeeScheduleRouter(a *app.App) *gin.Engine {
+	feeScheduleController := controllers.NewFeeScheduleController(a)
+	router := setupRouter()
+	router.GET("/api/admin/fee-schedules", feeScheduleController.ListFeeSchedules)
+	router.GET("/api/admin/fee-schedules/effective", feeScheduleController.GetEffectiveFeeSchedule)
+	router.GET("/api/admin/fee-schedules/:id", feeScheduleController.GetFeeSchedule)
+	router.POST("/api/admin/fee-schedules", feeScheduleController.CreateFeeSchedule)
+	router.POST("/api/reward", controllers.NewRewardController(a).RewardUser)
+	return router
+}
+
+func postFeeSchedule(router *gin.Engine, req models.FeeScheduleRequest) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(req)
+	httpReq, _ := http.NewRequest("POST", "/api/admin/fee-schedules", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httpReq)
+	return w
+}
+
+func TestCreateFeeSchedule(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	router := setupFeeScheduleRouter(a)
+
+	w := postFeeSchedule(router, models.FeeScheduleRequest{
+		Name:          "STT revision",
+		EffectiveFrom: time.Now().AddDate(0, 0, -1),
+		BrokerageRate: dec("0.0003"),
+		STTRate:       dec("0.00125"),
+		GSTRate:       dec("0.18"),
+		StampDutyRate: dec("0.00015"),
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var schedules []models.FeeSchedule
+	err := db.Order("id").Find(&schedules).Error
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(schedules))
+	assertDecimal(t, "0.00125", schedules[1].STTRate)
+
+	req, _ := http.NewRequest("GET", "/api/admin/fee-schedules", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var listed []models.FeeSchedule
+	err = json.Unmarshal(w.Body.Bytes(), &listed)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(listed))
+	assert.Equal(t, "STT revision", listed[0].Name)
+}
+
+func TestCreateFeeScheduleInvalidRequest(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	router := setupFeeScheduleRouter(a)
+
+	w := postFeeSchedule(router, models.FeeScheduleRequest{
+		Name:          "Negative STT",
+		EffectiveFrom: time.Now(),
+		STTRate:       dec("-0.001"),
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = postFeeSchedule(router, models.FeeScheduleRequest{
+		Name:          "Inverted caps",
+		EffectiveFrom: time.Now(),
+		BrokerageMin:  dec("100"),
+		BrokerageMax:  dec("20"),
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetEffectiveFeeSchedulePrefersLatestAndSpecific(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	router := setupFeeScheduleRouter(a)
+
+	effectiveFrom := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	w := postFeeSchedule(router, models.FeeScheduleRequest{
+		Name:          "BSE equity",
+		EffectiveFrom: effectiveFrom,
+		Exchange:      "BSE",
+		BrokerageRate: dec("0.0002"),
+		STTRate:       dec("0.001"),
+		GSTRate:       dec("0.18"),
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	testCases := []struct {
+		query    string
+		expected string
+	}{
+		{"?at=2025-06-01T00:00:00Z&exchange=BSE", "BSE equity"},
+		{"?at=2025-06-01T00:00:00Z&exchange=NSE", "Default schedule"},
+		{"?at=2025-03-31T23:59:59Z&exchange=BSE", "Default schedule"},
+	}
+
+	for _, tc := range testCases {
+		req, _ := http.NewRequest("GET", "/api/admin/fee-schedules/effective"+tc.query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, tc.query)
+
+		var schedule models.FeeSchedule
+		err := json.Unmarshal(w.Body.Bytes(), &schedule)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, schedule.Name, tc.query)
+	}
+
+	req, _ := http.NewRequest("GET", "/api/admin/fee-schedules/effective?at=1999-01-01T00:00:00Z", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRewardUserAppliesScheduleInEffectAtRewardTime(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	router := setupFeeScheduleRouter(a)
+
+	now := time.Now()
+	w := postFeeSchedule(router, models.FeeScheduleRequest{
+		Name:          "Stamp duty introduced",
+		EffectiveFrom: now.AddDate(0, 0, -1),
+		BrokerageRate: dec("0.0003"),
+		STTRate:       dec("0.001"),
+		GSTRate:       dec("0.18"),
+		StampDutyRate: dec("0.00015"),
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	rewards := []models.RewardRequest{
+		{ID: "reward-old", UserID: "user123", StockSymbol: "TCS", Quantity: dec("2"), RewardTimestamp: now.AddDate(0, 0, -2)},
+		{ID: "reward-new", UserID: "user123", StockSymbol: "TCS", Quantity: dec("2"), RewardTimestamp: now},
+	}
+
+	for _, rewardReq := range rewards {
+		body, _ := json.Marshal(rewardReq)
+		req, _ := http.NewRequest("POST", "/api/reward", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	var oldReward, newReward models.StockReward
+	assert.NoError(t, db.Where("id = ?", "reward-old").First(&oldReward).Error)
+	assert.NoError(t, db.Where("id = ?", "reward-new").First(&newReward).Error)
+	assert.Equal(t, uint(1), *oldReward.FeeScheduleID)
+	assert.Equal(t, uint(2), *newReward.FeeScheduleID)
+
+	var oldEntries, newEntries int64
+	db.Model(&models.LedgerEntry{}).Where("reward_id = ?", "reward-old").Count(&oldEntries)
+	db.Model(&models.LedgerEntry{}).Where("reward_id = ?", "reward-new").Count(&newEntries)
+	assert.Equal(t, int64(5), oldEntries)
+	assert.Equal(t, int64(6), newEntries)
+
+	var stampDuty models.LedgerEntry
+	err := db.Where("reward_id = ? AND account_type = ?", "reward-new", models.AccountTypeStampDutyExp).First(&stampDuty).Error
+	assert.NoError(t, err)
+	assert.Contains(t, stampDuty.Description, "0.015%")
+}
@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"assignment/controllers"
+	"assignment/lifecycle"
+	"assignment/models"
+	"assignment/services"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type shutdownResult struct {
+	status int
+	err    error
+}
+
+// startBlockedReward serves POST /reward and sends one request that stops
+// inside its transaction, just before the reward row is inserted, until
+// release is closed or the request is cancelled.
+func startBlockedReward(t *testing.T, drainTimeout time.Duration) (*gorm.DB, context.CancelFunc, chan struct{}, <-chan error, <-chan shutdownResult) {
+	db := setupTestDBAt(t, filepath.Join(t.TempDir(), "shutdown.db"))
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	a := newTestApp(t, db)
+
+	provider, err := services.NewFilePriceProvider(writePriceFile(t, "prices.csv", "TCS,1000\n"))
+	assert.NoError(t, err)
+	usePriceProvider(a, provider)
+
+	blocked := make(chan struct{})
+	release := make(chan struct{})
+	err = db.Callback().Create().Before("gorm:create").Register("test:block_reward", func(tx *gorm.DB) {
+		if tx.Statement.Table != "stock_rewards" {
+			return
+		}
+		close(blocked)
+		select {
+		case <-release:
+		case <-tx.Statement.Context.Done():
+		}
+	})
+	assert.NoError(t, err)
+
+	router := setupRouter()
+	router.POST("/reward", controllers.NewRewardController(a).RewardUser)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	ctx, shutdown := context.WithCancel(context.Background())
+	t.Cleanup(shutdown)
+	served := make(chan error, 1)
+	go func() {
+		served <- lifecycle.Serve(ctx, &http.Server{Handler: router}, listener, drainTimeout)
+	}()
+
+	body, _ := json.Marshal(models.RewardRequest{
+		ID:              "shutdown-1",
+		UserID:          "user123",
+		StockSymbol:     "TCS",
+		Quantity:        dec("2"),
+		RewardTimestamp: time.Now(),
+	})
+	responses := make(chan shutdownResult, 1)
+	go func() {
+		resp, err := http.Post("http://"+listener.Addr().String()+"/reward", "application/json", bytes.NewReader(body))
+		if err != nil {
+			responses <- shutdownResult{err: err}
+			return
+		}
+		resp.Body.Close()
+		responses <- shutdownResult{status: resp.StatusCode}
+	}()
+
+	select {
+	case <-blocked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("reward request never reached its transaction")
+	}
+
+	return db, shutdown, release, served, responses
+}
+
+func countShutdownRows(t *testing.T, db *gorm.DB) (rewards, entries, keys int64) {
+	t.Helper()
+
+	assert.NoError(t, db.Model(&models.StockReward{}).Count(&rewards).Error)
+	assert.NoError(t, db.Model(&models.LedgerEntry{}).Count(&entries).Error)
+	assert.NoError(t, db.Model(&models.RewardIdempotencyKey{}).Count(&keys).Error)
+	return rewards, entries, keys
+}
+
+func TestShutdownDrainsInFlightReward(t *testing.T) {
+	t.Parallel()
+
+	db, shutdown, release, served, responses := startBlockedReward(t, 5*time.Second)
+
+	shutdown()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	result := <-responses
+	assert.NoError(t, result.err)
+	assert.Equal(t, http.StatusCreated, result.status)
+	assert.NoError(t, <-served)
+
+	rewards, entries, keys := countShutdownRows(t, db)
+	assert.Equal(t, int64(1), rewards)
+	assert.Positive(t, entries)
+	assert.Equal(t, int64(1), keys)
+}
+
+func TestShutdownRollsBackRewardAfterDrainTimeout(t *testing.T) {
+	t.Parallel()
+
+	db, shutdown, _, served, responses := startBlockedReward(t, 100*time.Millisecond)
+
+	shutdown()
+
+	select {
+	case err := <-served:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop after the drain timeout")
+	}
+
+	result := <-responses
+	assert.NotEqual(t, http.StatusCreated, result.status)
+
+	rewards, entries, keys := countShutdownRows(t, db)
+	assert.Zero(t, rewards)
+	assert.Zero(t, entries)
+	assert.Zero(t, keys)
+}
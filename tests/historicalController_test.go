@@ -2,7 +2,6 @@ package tests
 
 import (
 	"assignment/controllers"
-	"assignment/initializers"
 	"assignment/models"
 	"encoding/json"
 	"net/http"
@@ -14,12 +13,13 @@ import (
 )
 
 func TestGetHistoricalINRSuccess(t *testing.T) {
+	t.Parallel()
+
 	db := setupTestDB(t)
-	initializers.DB = db
-	setupTestLogger()
+	a := newTestApp(t, db)
 
 	router := setupRouter()
-	router.GET("/api/historical/:userId/inr", controllers.GetHistoricalINR)
+	router.GET("/api/historical/:userId/inr", controllers.NewHistoricalController(a).GetHistoricalINR)
 
 	yesterday := time.Now().AddDate(0, 0, -1)
 	twoDaysAgo := time.Now().AddDate(0, 0, -2)
@@ -29,23 +29,22 @@ func TestGetHistoricalINRSuccess(t *testing.T) {
 			ID:                 "hist-1",
 			UserID:             "user123",
 			StockSymbol:        "RELIANCE",
-			Quantity:           10.0,
+			Quantity:           dec("10.0"),
 			RewardTimestamp:    yesterday,
-			StockPriceAtReward: 2500.0,
+			StockPriceAtReward: dec("2500.0"),
 		},
 		{
 			ID:                 "hist-2",
 			UserID:             "user123",
 			StockSymbol:        "TCS",
-			Quantity:           5.0,
+			Quantity:           dec("5.0"),
 			RewardTimestamp:    twoDaysAgo,
-			StockPriceAtReward: 3500.0,
+			StockPriceAtReward: dec("3500.0"),
 		},
 	}
 
 	for _, reward := range rewards {
-		err := db.Create(&reward).Error
-		assert.NoError(t, err)
+		createRewardWithLedger(t, db, reward)
 	}
 
 	req, _ := http.NewRequest("GET", "/api/historical/user123/inr", nil)
@@ -62,12 +61,13 @@ func TestGetHistoricalINRSuccess(t *testing.T) {
 }
 
 func TestGetHistoricalINRNoData(t *testing.T) {
+	t.Parallel()
+
 	db := setupTestDB(t)
-	initializers.DB = db
-	setupTestLogger()
+	a := newTestApp(t, db)
 
 	router := setupRouter()
-	router.GET("/api/historical/:userId/inr", controllers.GetHistoricalINR)
+	router.GET("/api/historical/:userId/inr", controllers.NewHistoricalController(a).GetHistoricalINR)
 
 	req, _ := http.NewRequest("GET", "/api/historical/user999/inr", nil)
 	w := httptest.NewRecorder()
@@ -84,12 +84,13 @@ func TestGetHistoricalINRNoData(t *testing.T) {
 }
 
 func TestGetHistoricalINRExcludesTodayRewards(t *testing.T) {
+	t.Parallel()
+
 	db := setupTestDB(t)
-	initializers.DB = db
-	setupTestLogger()
+	a := newTestApp(t, db)
 
 	router := setupRouter()
-	router.GET("/api/historical/:userId/inr", controllers.GetHistoricalINR)
+	router.GET("/api/historical/:userId/inr", controllers.NewHistoricalController(a).GetHistoricalINR)
 
 	now := time.Now()
 	yesterday := now.AddDate(0, 0, -1)
@@ -99,23 +100,22 @@ func TestGetHistoricalINRExcludesTodayRewards(t *testing.T) {
 			ID:                 "today-1",
 			UserID:             "user123",
 			StockSymbol:        "RELIANCE",
-			Quantity:           10.0,
+			Quantity:           dec("10.0"),
 			RewardTimestamp:    now,
-			StockPriceAtReward: 2500.0,
+			StockPriceAtReward: dec("2500.0"),
 		},
 		{
 			ID:                 "hist-1",
 			UserID:             "user123",
 			StockSymbol:        "TCS",
-			Quantity:           5.0,
+			Quantity:           dec("5.0"),
 			RewardTimestamp:    yesterday,
-			StockPriceAtReward: 3500.0,
+			StockPriceAtReward: dec("3500.0"),
 		},
 	}
 
 	for _, reward := range rewards {
-		err := db.Create(&reward).Error
-		assert.NoError(t, err)
+		createRewardWithLedger(t, db, reward)
 	}
 
 	req, _ := http.NewRequest("GET", "/api/historical/user123/inr", nil)
@@ -134,3 +134,109 @@ func TestGetHistoricalINRExcludesTodayRewards(t *testing.T) {
 	assert.Contains(t, response.DailyValues, yesterdayStr)
 	assert.NotContains(t, response.DailyValues, todayStr)
 }
+
+func TestGetHistoricalINRUsesEndOfDayPrices(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	router := setupRouter()
+	router.GET("/api/historical/:userId/inr", controllers.NewHistoricalController(a).GetHistoricalINR)
+
+	now := time.Now().In(businessLocation())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	twoDaysAgo := today.AddDate(0, 0, -2)
+	yesterday := today.AddDate(0, 0, -1)
+
+	rewards := []models.StockReward{
+		{
+			ID:                 "hist-1",
+			UserID:             "user123",
+			StockSymbol:        "RELIANCE",
+			Quantity:           dec("10.0"),
+			RewardTimestamp:    twoDaysAgo.Add(10 * time.Hour),
+			StockPriceAtReward: dec("2400.0"),
+		},
+		{
+			ID:                 "hist-2",
+			UserID:             "user123",
+			StockSymbol:        "TCS",
+			Quantity:           dec("5.0"),
+			RewardTimestamp:    yesterday.Add(10 * time.Hour),
+			StockPriceAtReward: dec("3400.0"),
+		},
+	}
+
+	for _, reward := range rewards {
+		createRewardWithLedger(t, db, reward)
+	}
+
+	prices := []models.StockPrice{
+		{StockSymbol: "RELIANCE", Price: dec("2450.0"), Timestamp: twoDaysAgo.Add(11 * time.Hour)},
+		{StockSymbol: "RELIANCE", Price: dec("2500.0"), Timestamp: twoDaysAgo.Add(15 * time.Hour)},
+		{StockSymbol: "RELIANCE", Price: dec("2600.0"), Timestamp: yesterday.Add(15 * time.Hour)},
+		{StockSymbol: "TCS", Price: dec("3500.0"), Timestamp: yesterday.Add(15 * time.Hour)},
+		{StockSymbol: "RELIANCE", Price: dec("9999.0"), Timestamp: today.Add(1 * time.Minute)},
+	}
+	err := db.Create(&prices).Error
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest("GET", "/api/historical/user123/inr", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.HistoricalINRResponse
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, len(response.DailyValues))
+	assertDecimal(t, "25000", response.DailyValues[twoDaysAgo.Format("2006-01-02")])
+	assertDecimal(t, "43500", response.DailyValues[yesterday.Format("2006-01-02")])
+}
+
+func TestGetHistoricalINRFillsDaysWithoutRewards(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	router := setupRouter()
+	router.GET("/api/historical/:userId/inr", controllers.NewHistoricalController(a).GetHistoricalINR)
+
+	now := time.Now().In(businessLocation())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	fourDaysAgo := today.AddDate(0, 0, -4)
+
+	reward := models.StockReward{
+		ID:                 "hist-1",
+		UserID:             "user123",
+		StockSymbol:        "WIPRO",
+		Quantity:           dec("4.0"),
+		RewardTimestamp:    fourDaysAgo.Add(10 * time.Hour),
+		StockPriceAtReward: dec("500.0"),
+	}
+	createRewardWithLedger(t, db, reward)
+
+	price := models.StockPrice{StockSymbol: "WIPRO", Price: dec("510.0"), Timestamp: today.AddDate(0, 0, -2).Add(12 * time.Hour)}
+	err := db.Create(&price).Error
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest("GET", "/api/historical/user123/inr", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.HistoricalINRResponse
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 4, len(response.DailyValues))
+	assertDecimal(t, "2000", response.DailyValues[today.AddDate(0, 0, -4).Format("2006-01-02")])
+	assertDecimal(t, "2000", response.DailyValues[today.AddDate(0, 0, -3).Format("2006-01-02")])
+	assertDecimal(t, "2040", response.DailyValues[today.AddDate(0, 0, -2).Format("2006-01-02")])
+	assertDecimal(t, "2040", response.DailyValues[today.AddDate(0, 0, -1).Format("2006-01-02")])
+}
@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"assignment/commands"
+	"assignment/initializers"
+	"assignment/models"
+	"assignment/services"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const importCSV = `id,user_id,stock_symbol,quantity,reward_timestamp
+csv-1,user1,TCS,2,2025-11-17T10:30:00Z
+csv-2,user2,INFOSYS,0.5,2025-11-17T10:30:00+05:30
+csv-3,user3,TCS,-1,2025-11-17T10:30:00Z
+csv-4,user4,NOPE,1,2025-11-17T10:30:00Z
+csv-5,user5,TCS,1,yesterday
+csv-6,user6,TCS
+`
+
+func writeImportFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "rewards.csv")
+	err := os.WriteFile(path, []byte(content), 0o600)
+	assert.NoError(t, err)
+	return path
+}
+
+func readRejectedRows(t *testing.T, path string) [][]string {
+	file, err := os.Open(path)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	assert.NoError(t, err)
+	return records
+}
+
+func TestImportRewardsCommand(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	path := writeImportFile(t, importCSV)
+	rejectsPath := filepath.Join(t.TempDir(), "rejected.csv")
+
+	code := commands.Run(a, []string{"import-rewards", "--file", path, "--rejects", rejectsPath, "--batch-size", "1"})
+	assert.Equal(t, 1, code)
+
+	var rewardCount, entryCount int64
+	db.Model(&models.StockReward{}).Count(&rewardCount)
+	db.Model(&models.LedgerEntry{}).Count(&entryCount)
+	assert.Equal(t, int64(2), rewardCount)
+	assert.Equal(t, int64(10), entryCount)
+
+	records := readRejectedRows(t, rejectsPath)
+	assert.Equal(t, []string{"line", "id", "user_id", "stock_symbol", "quantity", "reward_timestamp", "reason"}, records[0])
+	assert.Equal(t, 5, len(records))
+
+	rejectedLines := make(map[string]string)
+	for _, record := range records[1:] {
+		rejectedLines[record[0]] = record[len(record)-1]
+	}
+	assert.Contains(t, rejectedLines["4"], "Quantity")
+	assert.Contains(t, rejectedLines["5"], "unknown stock symbol")
+	assert.Contains(t, rejectedLines["6"], "reward_timestamp")
+	assert.Contains(t, rejectedLines["7"], "columns")
+
+	code = commands.Run(a, []string{"import-rewards", "--file", path, "--rejects", rejectsPath})
+	assert.Equal(t, 1, code)
+
+	db.Model(&models.StockReward{}).Count(&rewardCount)
+	assert.Equal(t, int64(2), rewardCount)
+	assert.Equal(t, 5, len(readRejectedRows(t, rejectsPath)))
+}
+
+func TestImportRewardsCommandDryRun(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	path := writeImportFile(t, "csv-1,user1,TCS,2,2025-11-17T10:30:00Z\ncsv-2,user2,WIPRO,1,2025-11-17T10:30:00Z\ncsv-1,user9,TCS,2,2025-11-17T10:30:00Z\n")
+	rejectsPath := filepath.Join(t.TempDir(), "rejected.csv")
+
+	code := commands.Run(a, []string{"import-rewards", "--file", path, "--dry-run", "--rejects", rejectsPath})
+	assert.Equal(t, 1, code)
+
+	var rewardCount, priceCount int64
+	db.Model(&models.StockReward{}).Count(&rewardCount)
+	db.Model(&models.StockPrice{}).Count(&priceCount)
+	assert.Equal(t, int64(0), rewardCount)
+	assert.Equal(t, int64(0), priceCount)
+
+	records := readRejectedRows(t, rejectsPath)
+	assert.Equal(t, 2, len(records))
+	assert.Equal(t, "3", records[1][0])
+	assert.Contains(t, records[1][len(records[1])-1], "appears earlier in this batch")
+
+	path = writeImportFile(t, "csv-1,user1,TCS,2,2025-11-17T10:30:00Z\n")
+	code = commands.Run(a, []string{"import-rewards", "--file", path, "--dry-run", "--rejects", rejectsPath})
+	assert.Equal(t, 0, code)
+}
+
+func TestImportRewardsCommandDryRunChecksLimits(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+	provider, err := services.NewFilePriceProvider(writePriceFile(t, "prices.csv", "TCS,1000\n"))
+	assert.NoError(t, err)
+	usePriceProvider(a, provider)
+	useRewardLimits(a, initializers.RewardLimitsConfig{MaxRewardValue: dec("3000"), UserDailyLimit: dec("5000")})
+
+	_, _, err = services.CreateReward(db, a.Prices, a.Limits, models.RewardRequest{
+		ID: "recorded-1", UserID: "user1", StockSymbol: "TCS", Quantity: dec("2"), RewardTimestamp: time.Now(),
+	})
+	assert.NoError(t, err)
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	path := writeImportFile(t, "dry-1,user1,TCS,2,"+now+"\ndry-2,user1,TCS,2,"+now+"\ndry-3,user2,TCS,2,"+now+"\ndry-4,user3,TCS,4,"+now+"\n")
+	rejectsPath := filepath.Join(t.TempDir(), "rejected.csv")
+
+	code := commands.Run(a, []string{"import-rewards", "--file", path, "--dry-run", "--rejects", rejectsPath})
+	assert.Equal(t, 1, code)
+
+	records := readRejectedRows(t, rejectsPath)
+	if assert.Equal(t, 3, len(records)) {
+		assert.Equal(t, "2", records[1][0])
+		assert.Contains(t, records[1][len(records[1])-1], models.LimitCodeUserDaily)
+		assert.Equal(t, "4", records[2][0])
+		assert.Contains(t, records[2][len(records[2])-1], models.LimitCodeRewardValue)
+	}
+
+	var rewardCount int64
+	db.Model(&models.StockReward{}).Count(&rewardCount)
+	assert.Equal(t, int64(1), rewardCount)
+	var usage models.RewardLimitUsage
+	assert.NoError(t, db.Where("scope = ? AND scope_key = ?", models.LimitScopeUserDaily, "user1").First(&usage).Error)
+	assertDecimal(t, "2000", usage.Amount)
+}
+
+func TestImportRewardsCommandUsage(t *testing.T) {
+	t.Parallel()
+
+	a := newTestApp(t, setupTestDB(t))
+
+	assert.Equal(t, 2, commands.Run(a, []string{"import-rewards"}))
+	assert.Equal(t, 2, commands.Run(a, []string{"import-rewards", "--file", filepath.Join(t.TempDir(), "missing.csv")}))
+	assert.Equal(t, 2, commands.Run(a, []string{"no-such-command"}))
+}
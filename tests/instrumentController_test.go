@@ -0,0 +1,172 @@
+package tests
+
+import (
+	"assignment/app"
+	"assignment/controllers"
+	"assignment/models"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupInstrumentRouter(a *app.App) *gin.Engine {
+	instrumentController := controllers.NewInstrumentController(a)
+	router := setupRouter()
+	router.GET("/api/admin/instruments", instrumentController.ListInstruments)
+	router.GET("/api/admin/instruments/:symbol", instrumentController.GetInstrument)
+	router.POST("/api/admin/instruments", instrumentController.CreateInstrument)
+	router.PUT("/api/admin/instruments/:symbol", instrumentController.UpdateInstrument)
+	router.DELETE("/api/admin/instruments/:symbol", instrumentController.DeleteInstrument)
+	return router
+}
+
+func TestListInstrumentsReturnsSeedData(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	router := setupInstrumentRouter(a)
+
+	req, _ := http.NewRequest("GET", "/api/admin/instruments", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var instruments []models.Instrument
+	err := json.Unmarshal(w.Body.Bytes(), &instruments)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, len(instruments))
+
+	for _, instrument := range instruments {
+		assert.Equal(t, models.InstrumentStatusActive, instrument.Status)
+		assert.Equal(t, 12, len(instrument.ISIN))
+	}
+}
+
+func TestCreateInstrument(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	router := setupInstrumentRouter(a)
+
+	body, _ := json.Marshal(models.InstrumentRequest{
+		Symbol:   "asianpaint",
+		ISIN:     "INE021A01026",
+		Exchange: "NSE",
+		Name:     "Asian Paints Ltd",
+	})
+	req, _ := http.NewRequest("POST", "/api/admin/instruments", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var saved models.Instrument
+	err := db.Where("symbol = ?", "ASIANPAINT").First(&saved).Error
+	assert.NoError(t, err)
+	assert.Equal(t, 1, saved.LotSize)
+	assert.Equal(t, models.InstrumentStatusActive, saved.Status)
+
+	req, _ = http.NewRequest("POST", "/api/admin/instruments", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestCreateInstrumentInvalidRequest(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	router := setupInstrumentRouter(a)
+
+	body, _ := json.Marshal(models.InstrumentRequest{
+		Symbol:   "ASIANPAINT",
+		ISIN:     "SHORT",
+		Exchange: "NYSE",
+		Name:     "Asian Paints Ltd",
+	})
+	req, _ := http.NewRequest("POST", "/api/admin/instruments", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestUpdateInstrument(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	router := setupInstrumentRouter(a)
+
+	body, _ := json.Marshal(models.InstrumentRequest{
+		Symbol:   "TCS",
+		ISIN:     "INE467B01029",
+		Exchange: "BSE",
+		Name:     "Tata Consultancy Services Ltd",
+		LotSize:  5,
+		Status:   models.InstrumentStatusDelisted,
+	})
+	req, _ := http.NewRequest("PUT", "/api/admin/instruments/TCS", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var saved models.Instrument
+	err := db.Where("symbol = ?", "TCS").First(&saved).Error
+	assert.NoError(t, err)
+	assert.Equal(t, "BSE", saved.Exchange)
+	assert.Equal(t, 5, saved.LotSize)
+	assert.Equal(t, models.InstrumentStatusDelisted, saved.Status)
+
+	req, _ = http.NewRequest("PUT", "/api/admin/instruments/UNKNOWN", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDeleteInstrumentDelists(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	router := setupInstrumentRouter(a)
+
+	req, _ := http.NewRequest("DELETE", "/api/admin/instruments/HDFC", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest("GET", "/api/admin/instruments/HDFC", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var instrument models.Instrument
+	err := json.Unmarshal(w.Body.Bytes(), &instrument)
+	assert.NoError(t, err)
+	assert.Equal(t, models.InstrumentStatusDelisted, instrument.Status)
+}
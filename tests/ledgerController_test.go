@@ -0,0 +1,180 @@
+package tests
+
+import (
+	"assignment/app"
+	"assignment/controllers"
+	"assignment/models"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupLedgerRouter(t *testing.T, a *app.App) *gin.Engine {
+	ledgerController := controllers.NewLedgerController(a)
+	router := setupRouter()
+	router.POST("/api/reward", controllers.NewRewardController(a).RewardUser)
+	router.GET("/api/ledger/entries", ledgerController.ListLedgerEntries)
+	router.GET("/api/ledger/balances", ledgerController.GetAccountBalances)
+	router.GET("/api/ledger/trial-balance", ledgerController.GetTrialBalance)
+
+	rewards := []models.RewardRequest{
+		{ID: "reward-1", UserID: "user123", StockSymbol: "TCS", Quantity: dec("2"), RewardTimestamp: time.Now()},
+		{ID: "reward-2", UserID: "user123", StockSymbol: "INFOSYS", Quantity: dec("1.5"), RewardTimestamp: time.Now()},
+		{ID: "reward-3", UserID: "user456", StockSymbol: "TCS", Quantity: dec("0.25"), RewardTimestamp: time.Now()},
+	}
+
+	for _, rewardReq := range rewards {
+		body, _ := json.Marshal(rewardReq)
+		req, _ := http.NewRequest("POST", "/api/reward", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	return router
+}
+
+func TestListLedgerEntries(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	router := setupLedgerRouter(t, a)
+
+	testCases := []struct {
+		query         string
+		expectedCount int
+		expectedTotal int64
+	}{
+		{"", 15, 15},
+		{"?reward_id=reward-2", 5, 5},
+		{"?account_type=stock_asset", 3, 3},
+		{"?stock_symbol=TCS&account_type=CASH_ACCOUNT", 2, 2},
+		{"?page=2&page_size=10", 5, 15},
+		{"?to=2000-01-01T00:00:00Z", 0, 0},
+	}
+
+	for _, tc := range testCases {
+		req, _ := http.NewRequest("GET", "/api/ledger/entries"+tc.query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, tc.query)
+
+		var response models.LedgerEntriesResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expectedCount, len(response.Entries), tc.query)
+		assert.Equal(t, tc.expectedTotal, response.TotalCount, tc.query)
+	}
+
+	for _, query := range []string{"?page=-1", "?page_size=1000", "?from=yesterday"} {
+		req, _ := http.NewRequest("GET", "/api/ledger/entries"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestGetAccountBalances(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	router := setupLedgerRouter(t, a)
+
+	var stockEntries []models.LedgerEntry
+	db.Where("account_type = ?", models.AccountTypeStockAsset).Find(&stockEntries)
+	expectedStock := dec("0")
+	for _, entry := range stockEntries {
+		expectedStock = expectedStock.Add(entry.DebitAmount)
+	}
+
+	req, _ := http.NewRequest("GET", "/api/ledger/balances", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.AccountBalancesResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, len(response.Balances))
+
+	total := dec("0")
+	for _, balance := range response.Balances {
+		total = total.Add(balance.Balance)
+		if balance.AccountType == models.AccountTypeStockAsset {
+			assertDecimal(t, expectedStock.String(), balance.TotalDebit)
+			assertDecimal(t, "0", balance.TotalCredit)
+		}
+		if balance.AccountType == models.AccountTypeCashAccount {
+			assert.True(t, balance.Balance.IsNegative())
+		}
+	}
+	assertDecimal(t, "0", total)
+
+	req, _ = http.NewRequest("GET", "/api/ledger/balances?as_of=2000-01-01T00:00:00Z", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	response = models.AccountBalancesResponse{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(response.Balances))
+}
+
+func TestGetTrialBalance(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	router := setupLedgerRouter(t, a)
+
+	req, _ := http.NewRequest("GET", "/api/ledger/trial-balance", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.TrialBalanceResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.True(t, response.Balanced)
+	assert.True(t, response.TotalDebit.IsPositive())
+	assert.True(t, response.TotalDebit.Equal(response.TotalCredit))
+	assert.Equal(t, 0, len(response.UnbalancedRewards))
+
+	symbol := "TCS"
+	err = db.Create(&models.LedgerEntry{
+		RewardID:    "reward-3",
+		EntryType:   models.EntryTypeReward,
+		AccountType: models.AccountTypeBrokerageExp,
+		StockSymbol: &symbol,
+		DebitAmount: dec("0.01"),
+		Description: "Stray entry",
+	}).Error
+	assert.NoError(t, err)
+
+	req, _ = http.NewRequest("GET", "/api/ledger/trial-balance", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	response = models.TrialBalanceResponse{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.False(t, response.Balanced)
+	assert.Equal(t, 1, len(response.UnbalancedRewards))
+	assert.Equal(t, "reward-3", response.UnbalancedRewards[0].RewardID)
+	assertDecimal(t, "0.01", response.UnbalancedRewards[0].Difference)
+}
@@ -8,42 +8,130 @@ import (
 )
 
 func TestCalculateCompanyCharges(t *testing.T) {
-	stockCost := 10000.0
+	t.Parallel()
 
-	charges := services.CalculateCompanyCharges(stockCost)
+	stockCost := dec("10000")
+
+	schedule := services.DefaultFeeSchedule()
+	charges := services.CalculateCompanyCharges(stockCost, &schedule)
 
 	assert.NotNil(t, charges)
-	assert.Equal(t, stockCost, charges.StockCost)
+	assertDecimal(t, "10000", charges.StockCost)
 
-	assert.Greater(t, charges.Brokerage, 0.0)
-	assert.Greater(t, charges.STT, 0.0)
-	assert.Greater(t, charges.GST, 0.0)
-	assert.Greater(t, charges.TotalCost, stockCost)
+	assert.True(t, charges.Brokerage.IsPositive())
+	assert.True(t, charges.STT.IsPositive())
+	assert.True(t, charges.GST.IsPositive())
+	assert.True(t, charges.TotalCost.GreaterThan(stockCost))
 }
 
 func TestCalculateCompanyChargesZeroAmount(t *testing.T) {
-	stockCost := 1000.0
+	t.Parallel()
+
+	stockCost := dec("1000")
 
-	charges := services.CalculateCompanyCharges(stockCost)
+	schedule := services.DefaultFeeSchedule()
+	charges := services.CalculateCompanyCharges(stockCost, &schedule)
 
 	assert.NotNil(t, charges)
-	assert.Equal(t, stockCost, charges.StockCost)
-	assert.Greater(t, charges.Brokerage, 0.0)
-	assert.Greater(t, charges.STT, 0.0)
-	assert.Greater(t, charges.GST, 0.0)
-	assert.Greater(t, charges.TotalCost, stockCost)
+	assertDecimal(t, "1000", charges.StockCost)
+	assert.True(t, charges.Brokerage.IsPositive())
+	assert.True(t, charges.STT.IsPositive())
+	assert.True(t, charges.GST.IsPositive())
+	assert.True(t, charges.TotalCost.GreaterThan(stockCost))
 }
 
 func TestCalculateCompanyChargesLargeAmount(t *testing.T) {
-	stockCost := 1000000.0
+	t.Parallel()
 
-	charges := services.CalculateCompanyCharges(stockCost)
+	stockCost := dec("1000000")
+
+	schedule := services.DefaultFeeSchedule()
+	charges := services.CalculateCompanyCharges(stockCost, &schedule)
 
 	assert.NotNil(t, charges)
-	assert.Equal(t, stockCost, charges.StockCost)
+	assertDecimal(t, "1000000", charges.StockCost)
+
+	assertDecimal(t, "300", charges.Brokerage)
+	assertDecimal(t, "1000", charges.STT)
+	assertDecimal(t, "54", charges.GST)
+	assertDecimal(t, "1001354", charges.TotalCost)
+}
+
+func TestCalculateCompanyChargesBankersRounding(t *testing.T) {
+	t.Parallel()
+
+	schedule := services.DefaultFeeSchedule()
+	charges := services.CalculateCompanyCharges(dec("25732.875"), &schedule)
+
+	assertDecimal(t, "25732.88", charges.StockCost)
+	assertDecimal(t, "7.72", charges.Brokerage)
+	assertDecimal(t, "25.73", charges.STT)
+	assertDecimal(t, "1.39", charges.GST)
+	assertDecimal(t, "25767.72", charges.TotalCost)
+
+	charges = services.CalculateCompanyCharges(dec("50"), &schedule)
+
+	assertDecimal(t, "0.02", charges.Brokerage)
+	assertDecimal(t, "0.05", charges.STT)
+	assertDecimal(t, "0", charges.GST)
+}
+
+func TestCalculateCompanyChargesBalancesToThePaisa(t *testing.T) {
+	t.Parallel()
+
+	costs := []string{"0.01", "99.99", "1234.565", "25732.875", "333333.333333", "7777777.7777"}
+
+	schedule := services.DefaultFeeSchedule()
+	schedule.ExchangeTxnRate = dec("0.0000325")
+	schedule.SEBIFeeRate = dec("0.000001")
+	schedule.StampDutyRate = dec("0.00015")
+
+	for _, cost := range costs {
+		charges := services.CalculateCompanyCharges(dec(cost), &schedule)
+
+		debits := charges.StockCost.Add(charges.Brokerage).Add(charges.STT).Add(charges.GST).
+			Add(charges.ExchangeCharges).Add(charges.SEBIFees).Add(charges.StampDuty)
+		assert.True(t, debits.Equal(charges.TotalCost), "charges for %s do not balance", cost)
+		assert.Equal(t, charges.TotalCost.StringFixed(2), charges.TotalCost.String())
+	}
+}
+
+func TestCalculateCompanyChargesBrokerageCaps(t *testing.T) {
+	t.Parallel()
+
+	schedule := services.DefaultFeeSchedule()
+	schedule.BrokerageMin = dec("20")
+	schedule.BrokerageMax = dec("100")
+
+	charges := services.CalculateCompanyCharges(dec("1000"), &schedule)
+	assertDecimal(t, "20", charges.Brokerage)
+	assertDecimal(t, "3.6", charges.GST)
+
+	charges = services.CalculateCompanyCharges(dec("1000000"), &schedule)
+	assertDecimal(t, "100", charges.Brokerage)
+	assertDecimal(t, "18", charges.GST)
+
+	charges = services.CalculateCompanyCharges(dec("200000"), &schedule)
+	assertDecimal(t, "60", charges.Brokerage)
+}
+
+func TestCalculateCompanyChargesStatutoryCharges(t *testing.T) {
+	t.Parallel()
+
+	schedule := services.DefaultFeeSchedule()
+	schedule.ID = 7
+	schedule.ExchangeTxnRate = dec("0.0000325")
+	schedule.SEBIFeeRate = dec("0.000001")
+	schedule.StampDutyRate = dec("0.00015")
+
+	charges := services.CalculateCompanyCharges(dec("1000000"), &schedule)
 
-	assert.Equal(t, 300.0, charges.Brokerage)
-	assert.Equal(t, 1000.0, charges.STT)
-	assert.Equal(t, 54.0, charges.GST)
-	assert.Equal(t, 1001354.0, charges.TotalCost)
+	assert.Equal(t, uint(7), charges.FeeScheduleID)
+	assertDecimal(t, "300", charges.Brokerage)
+	assertDecimal(t, "1000", charges.STT)
+	assertDecimal(t, "32.5", charges.ExchangeCharges)
+	assertDecimal(t, "1", charges.SEBIFees)
+	assertDecimal(t, "150", charges.StampDuty)
+	assertDecimal(t, "60.03", charges.GST)
+	assertDecimal(t, "1001543.53", charges.TotalCost)
 }
@@ -0,0 +1,268 @@
+package tests
+
+import (
+	"assignment/controllers"
+	"assignment/decimal"
+	"assignment/initializers"
+	"assignment/models"
+	"assignment/services"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupLimitRouter(t *testing.T, limits initializers.RewardLimitsConfig) (*gin.Engine, *gorm.DB) {
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+	useRewardLimits(a, limits)
+
+	provider, err := services.NewFilePriceProvider(writePriceFile(t, "prices.csv", "TCS,1000\n"))
+	assert.NoError(t, err)
+	usePriceProvider(a, provider)
+
+	rewardController := controllers.NewRewardController(a)
+	router := setupRouter()
+	router.POST("/reward", rewardController.RewardUser)
+	router.POST("/reward/:id/reverse", rewardController.ReverseReward)
+	router.POST("/rewards/batch", controllers.NewRewardBatchController(a).RewardUsersBatch)
+	router.GET("/limits/:userId", controllers.NewLimitController(a).GetUserLimits)
+
+	return router, db
+}
+
+func postLimitedReward(router *gin.Engine, id, userID, quantity, campaignID string) (*httptest.ResponseRecorder, models.RewardResponse) {
+	return postLimitedRewardAt(router, id, userID, quantity, campaignID, time.Now())
+}
+
+func postLimitedRewardAt(router *gin.Engine, id, userID, quantity, campaignID string, at time.Time) (*httptest.ResponseRecorder, models.RewardResponse) {
+	body, _ := json.Marshal(models.RewardRequest{
+		ID:              id,
+		UserID:          userID,
+		StockSymbol:     "TCS",
+		Quantity:        dec(quantity),
+		RewardTimestamp: at,
+		CampaignID:      campaignID,
+	})
+	req, _ := http.NewRequest("POST", "/reward", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response models.RewardResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return w, response
+}
+
+func getUserLimits(t *testing.T, router *gin.Engine, userID string) models.UserLimitsResponse {
+	t.Helper()
+
+	req, _ := http.NewRequest("GET", "/limits/"+userID, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response models.UserLimitsResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	return response
+}
+
+func TestRewardLimitsPerRewardAndDaily(t *testing.T) {
+	t.Parallel()
+	router, db := setupLimitRouter(t, initializers.RewardLimitsConfig{
+		MaxRewardValue:   dec("5000"),
+		UserDailyLimit:   dec("8000"),
+		UserMonthlyLimit: dec("12000"),
+	})
+
+	w, response := postLimitedReward(router, "limit-1", "user123", "6", "")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, models.LimitCodeRewardValue, response.Code)
+	assert.False(t, response.Success)
+
+	w, _ = postLimitedReward(router, "limit-2", "user123", "4", "")
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w, _ = postLimitedReward(router, "limit-3", "user123", "4", "")
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w, response = postLimitedReward(router, "limit-4", "user123", "0.5", "")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, models.LimitCodeUserDaily, response.Code)
+	assert.Contains(t, response.Message, "8000.00")
+
+	var count int64
+	db.Model(&models.StockReward{}).Where("id = ?", "limit-4").Count(&count)
+	assert.Equal(t, int64(0), count)
+	db.Model(&models.LedgerEntry{}).Where("reward_id = ?", "limit-4").Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	w, _ = postLimitedReward(router, "limit-5", "user456", "4", "")
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	limits := getUserLimits(t, router, "user123")
+	assertDecimal(t, "5000", *limits.MaxRewardValue)
+	assertDecimal(t, "8000", *limits.Daily.Limit)
+	assertDecimal(t, "8000", limits.Daily.Used)
+	assertDecimal(t, "0", *limits.Daily.Remaining)
+	assert.Equal(t, 2, limits.Daily.RewardCount)
+	assertDecimal(t, "4000", *limits.Monthly.Remaining)
+	assert.True(t, limits.Daily.PeriodEnd.Sub(limits.Daily.PeriodStart) >= 23*time.Hour)
+
+	req, _ := http.NewRequest("POST", "/reward/limit-2/reverse", bytes.NewBufferString(`{"reason": "Issued in error"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rw := httptest.NewRecorder()
+	router.ServeHTTP(rw, req)
+	assert.Equal(t, http.StatusOK, rw.Code, rw.Body.String())
+
+	limits = getUserLimits(t, router, "user123")
+	assertDecimal(t, "4000", limits.Daily.Used)
+	assert.Equal(t, 1, limits.Daily.RewardCount)
+
+	w, _ = postLimitedReward(router, "limit-6", "user123", "4", "")
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+}
+
+func TestRewardLimitsMonthlyAndCampaignBudget(t *testing.T) {
+	t.Parallel()
+	router, db := setupLimitRouter(t, initializers.RewardLimitsConfig{
+		UserMonthlyLimit:     dec("5000"),
+		CampaignDailyBudgets: map[string]decimal.Decimal{"DIWALI": dec("5000")},
+	})
+	db.Create(&models.Campaign{ID: "DIWALI", Name: "Diwali", StartDate: time.Now().AddDate(0, 0, -1), Status: models.CampaignStatusActive})
+
+	w, response := postLimitedReward(router, "campaign-1", "user123", "3", "DIWALI")
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, "DIWALI", *response.Reward.CampaignID)
+
+	w, response = postLimitedReward(router, "campaign-2", "user123", "3", "")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, models.LimitCodeUserMonthly, response.Code)
+
+	w, response = postLimitedReward(router, "campaign-3", "user456", "3", "DIWALI")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, models.LimitCodeCampaignBudget, response.Code)
+
+	w, _ = postLimitedReward(router, "campaign-4", "user456", "3", "")
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	limits := getUserLimits(t, router, "user456")
+	assert.Nil(t, limits.MaxRewardValue)
+	assert.Nil(t, limits.Daily.Limit)
+	assert.Nil(t, limits.Daily.Remaining)
+	assertDecimal(t, "3000", limits.Daily.Used)
+	assertDecimal(t, "2000", *limits.Monthly.Remaining)
+
+	var buckets []models.RewardLimitUsage
+	db.Where("scope = ?", models.LimitScopeUserDaily).Find(&buckets)
+	assert.Empty(t, buckets)
+	db.Where("scope = ? AND scope_key = ?", models.LimitScopeCampaignDaily, "").Find(&buckets)
+	assert.Empty(t, buckets)
+}
+
+func TestRewardLimitsFollowServerTime(t *testing.T) {
+	t.Parallel()
+	router, _ := setupLimitRouter(t, initializers.RewardLimitsConfig{
+		UserDailyLimit:   dec("5000"),
+		UserMonthlyLimit: dec("5000"),
+	})
+
+	w, _ := postLimitedRewardAt(router, "dated-1", "user123", "3", "", time.Now().AddDate(0, -2, 0))
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w, response := postLimitedRewardAt(router, "dated-2", "user123", "3", "", time.Now().AddDate(0, 0, 3))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	assert.Equal(t, models.LimitCodeUserDaily, response.Code)
+
+	limits := getUserLimits(t, router, "user123")
+	assertDecimal(t, "3000", limits.Daily.Used)
+	assertDecimal(t, "3000", limits.Monthly.Used)
+}
+
+func TestRewardLimitsApplyWithinBatch(t *testing.T) {
+	t.Parallel()
+	router, _ := setupLimitRouter(t, initializers.RewardLimitsConfig{UserDailyLimit: dec("8000")})
+
+	now := time.Now().Format(time.RFC3339)
+	body := `[
+		{"id": "batch-limit-1", "user_id": "user123", "stock_symbol": "TCS", "quantity": "3", "reward_timestamp": "` + now + `"},
+		{"id": "batch-limit-2", "user_id": "user123", "stock_symbol": "TCS", "quantity": "3", "reward_timestamp": "` + now + `"},
+		{"id": "batch-limit-3", "user_id": "user123", "stock_symbol": "TCS", "quantity": "3", "reward_timestamp": "` + now + `"}
+	]`
+	req, _ := http.NewRequest("POST", "/rewards/batch", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response models.BatchRewardResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, response.Created)
+	assert.Equal(t, 1, response.Invalid)
+	assert.Equal(t, models.BatchStatusInvalid, response.Results[2].Status)
+	assert.Equal(t, models.LimitCodeUserDaily, response.Results[2].Code)
+
+	limits := getUserLimits(t, router, "user123")
+	assertDecimal(t, "6000", limits.Daily.Used)
+}
+
+func TestRewardLimitsReleaseOnlyWhatWasReserved(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+	provider, err := services.NewFilePriceProvider(writePriceFile(t, "prices.csv", "TCS,1000\n"))
+	assert.NoError(t, err)
+	usePriceProvider(a, provider)
+
+	reward := func(id, quantity string) error {
+		_, _, err := services.CreateReward(db, a.Prices, a.Limits, models.RewardRequest{
+			ID: id, UserID: "user123", StockSymbol: "TCS", Quantity: dec(quantity), RewardTimestamp: time.Now(),
+		})
+		return err
+	}
+
+	assert.NoError(t, reward("uncapped-1", "4"))
+
+	useRewardLimits(a, initializers.RewardLimitsConfig{UserDailyLimit: dec("8000")})
+	assert.NoError(t, reward("capped-1", "4"))
+
+	_, _, err = services.ReverseReward(db, a.Clock, a.Limits, "uncapped-1", "Issued in error")
+	assert.NoError(t, err)
+
+	var usage models.RewardLimitUsage
+	assert.NoError(t, db.Where("scope = ?", models.LimitScopeUserDaily).First(&usage).Error)
+	assertDecimal(t, "4000", usage.Amount)
+	assert.Equal(t, 1, usage.RewardCount)
+
+	var exceeded *services.LimitExceededError
+	assert.ErrorAs(t, reward("capped-2", "5"), &exceeded)
+
+	useRewardLimits(a, initializers.RewardLimitsConfig{})
+	_, _, err = services.ReverseReward(db, a.Clock, a.Limits, "capped-1", "Issued in error")
+	assert.NoError(t, err)
+
+	assert.NoError(t, db.First(&usage, usage.ID).Error)
+	assertDecimal(t, "0", usage.Amount)
+	assert.Equal(t, 0, usage.RewardCount)
+
+	var reservations int64
+	db.Model(&models.RewardLimitReservation{}).Count(&reservations)
+	assert.Zero(t, reservations)
+}
+
+func TestRewardLimitsRejectInvalidConfig(t *testing.T) {
+	t.Setenv("REWARD_USER_DAILY_LIMIT_INR", "lots")
+	_, err := initializers.LoadRewardLimitsConfig()
+	assert.Error(t, err)
+
+	t.Setenv("REWARD_USER_DAILY_LIMIT_INR", "")
+	t.Setenv("REWARD_CAMPAIGN_DAILY_BUDGETS", "DIWALI")
+	_, err = initializers.LoadRewardLimitsConfig()
+	assert.Error(t, err)
+}
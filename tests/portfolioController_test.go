@@ -2,8 +2,9 @@ package tests
 
 import (
 	"assignment/controllers"
-	"assignment/initializers"
+	"assignment/decimal"
 	"assignment/models"
+	"assignment/services"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -14,12 +15,13 @@ import (
 )
 
 func TestGetUserPortfolioSuccess(t *testing.T) {
+	t.Parallel()
+
 	db := setupTestDB(t)
-	initializers.DB = db
-	setupTestLogger()
+	a := newTestApp(t, db)
 
 	router := setupRouter()
-	router.GET("/api/portfolio/:userId", controllers.GetUserPortfolio)
+	router.GET("/api/portfolio/:userId", controllers.NewPortfolioController(a).GetUserPortfolio)
 
 	now := time.Now()
 	rewards := []models.StockReward{
@@ -27,31 +29,30 @@ func TestGetUserPortfolioSuccess(t *testing.T) {
 			ID:                 "port-1",
 			UserID:             "user123",
 			StockSymbol:        "RELIANCE",
-			Quantity:           10.5,
+			Quantity:           dec("10.5"),
 			RewardTimestamp:    now,
-			StockPriceAtReward: 2500.0,
+			StockPriceAtReward: dec("2500.0"),
 		},
 		{
 			ID:                 "port-2",
 			UserID:             "user123",
 			StockSymbol:        "TCS",
-			Quantity:           5.25,
+			Quantity:           dec("5.25"),
 			RewardTimestamp:    now,
-			StockPriceAtReward: 3500.0,
+			StockPriceAtReward: dec("3500.0"),
 		},
 		{
 			ID:                 "port-3",
 			UserID:             "user123",
 			StockSymbol:        "RELIANCE",
-			Quantity:           2.5,
+			Quantity:           dec("2.5"),
 			RewardTimestamp:    now,
-			StockPriceAtReward: 2500.0,
+			StockPriceAtReward: dec("2500.0"),
 		},
 	}
 
 	for _, reward := range rewards {
-		err := db.Create(&reward).Error
-		assert.NoError(t, err)
+		createRewardWithLedger(t, db, reward)
 	}
 
 	req, _ := http.NewRequest("GET", "/api/portfolio/user123", nil)
@@ -65,7 +66,7 @@ func TestGetUserPortfolioSuccess(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "user123", response.UserID)
 	assert.NotNil(t, response.Holdings)
-	assert.Greater(t, response.TotalValue, 0.0)
+	assert.True(t, response.TotalValue.IsPositive())
 	assert.NotZero(t, response.LastUpdated)
 
 	var relianceHolding *models.UserStockHolding
@@ -76,16 +77,17 @@ func TestGetUserPortfolioSuccess(t *testing.T) {
 		}
 	}
 	assert.NotNil(t, relianceHolding)
-	assert.Equal(t, 13.0, relianceHolding.TotalQuantity)
+	assertDecimal(t, "13", relianceHolding.TotalQuantity)
 }
 
 func TestGetUserPortfolioNoData(t *testing.T) {
+	t.Parallel()
+
 	db := setupTestDB(t)
-	initializers.DB = db
-	setupTestLogger()
+	a := newTestApp(t, db)
 
 	router := setupRouter()
-	router.GET("/api/portfolio/:userId", controllers.GetUserPortfolio)
+	router.GET("/api/portfolio/:userId", controllers.NewPortfolioController(a).GetUserPortfolio)
 
 	req, _ := http.NewRequest("GET", "/api/portfolio/user999", nil)
 	w := httptest.NewRecorder()
@@ -99,16 +101,17 @@ func TestGetUserPortfolioNoData(t *testing.T) {
 	assert.Equal(t, "user999", response.UserID)
 	assert.NotNil(t, response.Holdings)
 	assert.Equal(t, 0, len(response.Holdings))
-	assert.Equal(t, 0.0, response.TotalValue)
+	assertDecimal(t, "0", response.TotalValue)
 }
 
 func TestGetUserPortfolioMultipleStocks(t *testing.T) {
+	t.Parallel()
+
 	db := setupTestDB(t)
-	initializers.DB = db
-	setupTestLogger()
+	a := newTestApp(t, db)
 
 	router := setupRouter()
-	router.GET("/api/portfolio/:userId", controllers.GetUserPortfolio)
+	router.GET("/api/portfolio/:userId", controllers.NewPortfolioController(a).GetUserPortfolio)
 
 	now := time.Now()
 	rewards := []models.StockReward{
@@ -116,31 +119,30 @@ func TestGetUserPortfolioMultipleStocks(t *testing.T) {
 			ID:                 "port-1",
 			UserID:             "user123",
 			StockSymbol:        "RELIANCE",
-			Quantity:           10.0,
+			Quantity:           dec("10.0"),
 			RewardTimestamp:    now,
-			StockPriceAtReward: 2500.0,
+			StockPriceAtReward: dec("2500.0"),
 		},
 		{
 			ID:                 "port-2",
 			UserID:             "user123",
 			StockSymbol:        "TCS",
-			Quantity:           5.0,
+			Quantity:           dec("5.0"),
 			RewardTimestamp:    now,
-			StockPriceAtReward: 3500.0,
+			StockPriceAtReward: dec("3500.0"),
 		},
 		{
 			ID:                 "port-3",
 			UserID:             "user123",
 			StockSymbol:        "INFOSYS",
-			Quantity:           8.0,
+			Quantity:           dec("8.0"),
 			RewardTimestamp:    now,
-			StockPriceAtReward: 1600.0,
+			StockPriceAtReward: dec("1600.0"),
 		},
 	}
 
 	for _, reward := range rewards {
-		err := db.Create(&reward).Error
-		assert.NoError(t, err)
+		createRewardWithLedger(t, db, reward)
 	}
 
 	req, _ := http.NewRequest("GET", "/api/portfolio/user123", nil)
@@ -157,11 +159,118 @@ func TestGetUserPortfolioMultipleStocks(t *testing.T) {
 	stockSymbols := make(map[string]bool)
 	for _, holding := range response.Holdings {
 		stockSymbols[holding.StockSymbol] = true
-		assert.Greater(t, holding.CurrentValue, 0.0)
-		assert.Greater(t, holding.TotalQuantity, 0.0)
-		assert.Greater(t, holding.CurrentPrice, 0.0)
+		assert.True(t, holding.CurrentValue.IsPositive())
+		assert.True(t, holding.TotalQuantity.IsPositive())
+		assert.True(t, holding.CurrentPrice.IsPositive())
 	}
 	assert.True(t, stockSymbols["RELIANCE"])
 	assert.True(t, stockSymbols["TCS"])
 	assert.True(t, stockSymbols["INFOSYS"])
 }
+
+func TestGetUserPortfolioCostBasisAndDayChange(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	provider, err := services.NewFilePriceProvider(writePriceFile(t, "prices.csv", "RELIANCE,2700\n"))
+	assert.NoError(t, err)
+	usePriceProvider(a, provider)
+
+	router := setupRouter()
+	router.GET("/api/portfolio/:userId", controllers.NewPortfolioController(a).GetUserPortfolio)
+
+	now := time.Now().In(businessLocation())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	rewards := []models.StockReward{
+		{
+			ID:                 "cost-1",
+			UserID:             "user123",
+			StockSymbol:        "RELIANCE",
+			Quantity:           dec("10"),
+			RewardTimestamp:    today.AddDate(0, 0, -3).Add(10 * time.Hour),
+			StockPriceAtReward: dec("2500"),
+		},
+		{
+			ID:                 "cost-2",
+			UserID:             "user123",
+			StockSymbol:        "RELIANCE",
+			Quantity:           dec("2"),
+			RewardTimestamp:    now,
+			StockPriceAtReward: dec("2650"),
+		},
+	}
+
+	schedule := services.DefaultFeeSchedule()
+	charges := decimal.Zero
+	for _, reward := range rewards {
+		createRewardWithLedger(t, db, reward)
+		rewardCharges := services.CalculateCompanyCharges(reward.Quantity.Mul(reward.StockPriceAtReward), &schedule)
+		charges = charges.Add(rewardCharges.TotalCost.Sub(rewardCharges.StockCost))
+	}
+
+	err = db.Create(&models.StockPrice{StockSymbol: "RELIANCE", Price: dec("2600"), Timestamp: today.AddDate(0, 0, -1).Add(15 * time.Hour)}).Error
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest("GET", "/api/portfolio/user123", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.PortfolioResponse
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.False(t, response.CostIncludesFees)
+	assert.Equal(t, 1, len(response.Holdings))
+
+	holding := response.Holdings[0]
+	assertDecimal(t, "12", holding.TotalQuantity)
+	assertDecimal(t, "2700", holding.CurrentPrice)
+	assertDecimal(t, "32400", holding.CurrentValue)
+	assertDecimal(t, "30300", holding.TotalInvested)
+	assertDecimal(t, "2525", holding.AverageCost)
+	assertDecimal(t, "2100", holding.UnrealizedGain)
+	assertDecimal(t, "6.93", holding.UnrealizedGainPercent)
+	assertDecimal(t, "2600", holding.PreviousClose)
+	assertDecimal(t, "1000", holding.DayChange)
+	assertDecimal(t, "3.85", holding.DayChangePercent)
+	assertDecimal(t, "30300", response.TotalInvested)
+	assertDecimal(t, "2100", response.UnrealizedGain)
+	assertDecimal(t, "1000", response.DayChange)
+
+	req, _ = http.NewRequest("GET", "/api/portfolio/user123?include_fees=true", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.True(t, response.CostIncludesFees)
+	assert.True(t, charges.IsPositive())
+	assertDecimal(t, dec("30300").Add(charges).String(), response.Holdings[0].TotalInvested)
+	assertDecimal(t, dec("2100").Sub(charges).String(), response.Holdings[0].UnrealizedGain)
+
+	req, _ = http.NewRequest("GET", "/api/portfolio/user123?include_fees=maybe", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	a.Portfolio.IncludeFees = true
+	req, _ = http.NewRequest("GET", "/api/portfolio/user123", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.True(t, response.CostIncludesFees)
+
+	req, _ = http.NewRequest("GET", "/api/portfolio/user123?include_fees=false", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.False(t, response.CostIncludesFees)
+}
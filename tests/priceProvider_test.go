@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"assignment/initializers"
+	"assignment/services"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writePriceFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	err := os.WriteFile(path, []byte(content), 0644)
+	assert.NoError(t, err)
+	return path
+}
+
+func TestFilePriceProviderReplaysCSV(t *testing.T) {
+	t.Parallel()
+
+	path := writePriceFile(t, "prices.csv", "symbol,price\nRELIANCE,2500.50\nTCS,3500\nRELIANCE,2510.25\n")
+
+	provider, err := services.NewFilePriceProvider(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"RELIANCE", "TCS"}, provider.Symbols())
+
+	expected := []string{"2500.50", "2510.25", "2500.50"}
+	for _, want := range expected {
+		price, err := provider.GetPrice("RELIANCE")
+		assert.NoError(t, err)
+		assert.Equal(t, want, price.String())
+	}
+
+	_, err = provider.GetPrice("INFOSYS")
+	assert.Error(t, err)
+}
+
+func TestFilePriceProviderReplaysJSON(t *testing.T) {
+	t.Parallel()
+
+	path := writePriceFile(t, "prices.json", `[{"symbol":"tcs","price":3500},{"symbol":"TCS","price":3600}]`)
+
+	provider, err := services.NewFilePriceProvider(path)
+	assert.NoError(t, err)
+
+	first, _ := provider.GetPrice("TCS")
+	second, _ := provider.GetPrice("TCS")
+	assertDecimal(t, "3500", first)
+	assertDecimal(t, "3600", second)
+}
+
+func TestFilePriceProviderRejectsInvalidFiles(t *testing.T) {
+	t.Parallel()
+
+	_, err := services.NewFilePriceProvider(writePriceFile(t, "prices.csv", "RELIANCE,abc\n"))
+	assert.Error(t, err)
+
+	_, err = services.NewFilePriceProvider(writePriceFile(t, "prices.txt", "RELIANCE,2500\n"))
+	assert.Error(t, err)
+
+	_, err = services.NewFilePriceProvider(filepath.Join(t.TempDir(), "missing.csv"))
+	assert.Error(t, err)
+}
+
+func TestHTTPPriceProviderFetchesQuotes(t *testing.T) {
+	t.Parallel()
+
+	feed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("X-API-Key"))
+		if r.URL.Query().Get("symbol") != "RELIANCE" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"symbol": "RELIANCE", "price": 2456.75})
+	}))
+	defer feed.Close()
+
+	provider := services.NewHTTPPriceProvider(feed.URL, "secret", time.Second, []string{"RELIANCE"})
+
+	price, err := provider.GetPrice("RELIANCE")
+	assert.NoError(t, err)
+	assertDecimal(t, "2456.75", price)
+	assert.Equal(t, []string{"RELIANCE"}, provider.Symbols())
+
+	_, err = provider.GetPrice("UNKNOWN")
+	assert.Error(t, err)
+}
+
+func TestNewPriceProviderFromConfig(t *testing.T) {
+	t.Parallel()
+
+	provider, err := services.NewPriceProvider(initializers.PriceProviderConfig{Provider: "random"})
+	assert.NoError(t, err)
+	assert.IsType(t, &services.RandomPriceProvider{}, provider)
+
+	path := writePriceFile(t, "prices.csv", "RELIANCE,2500\n")
+	provider, err = services.NewPriceProvider(initializers.PriceProviderConfig{Provider: "file", FilePath: path})
+	assert.NoError(t, err)
+	assert.IsType(t, &services.FilePriceProvider{}, provider)
+
+	provider, err = services.NewPriceProvider(initializers.PriceProviderConfig{Provider: "http", FeedURL: "http://localhost:9999"})
+	assert.NoError(t, err)
+	assert.IsType(t, &services.HTTPPriceProvider{}, provider)
+	assert.NotEmpty(t, provider.Symbols())
+
+	_, err = services.NewPriceProvider(initializers.PriceProviderConfig{Provider: "file"})
+	assert.Error(t, err)
+
+	_, err = services.NewPriceProvider(initializers.PriceProviderConfig{Provider: "carrier-pigeon"})
+	assert.Error(t, err)
+}
+
+func TestSchedulerUsesConfiguredPriceProvider(t *testing.T) {
+	t.Parallel()
+
+	a := newTestApp(t, setupTestDB(t))
+	path := writePriceFile(t, "prices.csv", "RELIANCE,2500\nRELIANCE,2600\n")
+
+	provider, err := services.NewFilePriceProvider(path)
+	assert.NoError(t, err)
+	usePriceProvider(a, provider)
+
+	price, err := a.Prices.GetCurrentStockPrice("RELIANCE")
+	assert.NoError(t, err)
+	assertDecimal(t, "2500", price)
+
+	err = a.Prices.UpdateStockPrices()
+	assert.NoError(t, err)
+
+	price, err = a.Prices.GetCurrentStockPrice("RELIANCE")
+	assert.NoError(t, err)
+	assertDecimal(t, "2600", price)
+
+	_, err = a.Prices.GetCurrentStockPrice("TCS")
+	assert.Error(t, err)
+}
@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"assignment/app"
+	"assignment/controllers"
+	"assignment/models"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupReconciliationRouter(t *testing.T, a *app.App) *gin.Engine {
+	reconciliationController := controllers.NewReconciliationController(a)
+	rewardController := controllers.NewRewardController(a)
+	router := setupRouter()
+	router.POST("/api/reward", rewardController.RewardUser)
+	router.POST("/api/reward/:id/reverse", rewardController.ReverseReward)
+	router.POST("/api/admin/reconciliation-runs", reconciliationController.RunReconciliation)
+	router.GET("/api/admin/reconciliation-runs", reconciliationController.ListReconciliationRuns)
+	router.GET("/api/admin/reconciliation-runs/:id", reconciliationController.GetReconciliationRun)
+
+	for _, id := range []string{"reward-1", "reward-2", "reward-3", "reward-4"} {
+		rewardReq := models.RewardRequest{
+			ID:              id,
+			UserID:          "user123",
+			StockSymbol:     "WIPRO",
+			Quantity:        dec("4"),
+			RewardTimestamp: time.Now(),
+		}
+		body, _ := json.Marshal(rewardReq)
+		req, _ := http.NewRequest("POST", "/api/reward", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	req, _ := http.NewRequest("POST", "/api/reward/reward-4/reverse", bytes.NewBufferString(`{"reason":"duplicate payout"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	return router
+}
+
+func runReconciliation(t *testing.T, router *gin.Engine) models.ReconciliationRun {
+	req, _ := http.NewRequest("POST", "/api/admin/reconciliation-runs", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var run models.ReconciliationRun
+	err := json.Unmarshal(w.Body.Bytes(), &run)
+	assert.NoError(t, err)
+	return run
+}
+
+func TestRunReconciliationClean(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	router := setupReconciliationRouter(t, a)
+
+	run := runReconciliation(t, router)
+	assert.Equal(t, models.ReconciliationStatusClean, run.Status)
+	assert.Equal(t, 4, run.RewardsScanned)
+	assert.Equal(t, 0, len(run.Findings))
+}
+
+func TestRunReconciliationFindsIssues(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	router := setupReconciliationRouter(t, a)
+
+	err := db.Model(&models.LedgerEntry{}).
+		Where("reward_id = ? AND account_type = ?", "reward-1", models.AccountTypeCashAccount).
+		Update("credit_amount", dec("1")).Error
+	assert.NoError(t, err)
+
+	err = db.Model(&models.LedgerEntry{}).
+		Where("reward_id = ? AND account_type = ?", "reward-2", models.AccountTypeStockAsset).
+		Update("quantity", dec("3.999999")).Error
+	assert.NoError(t, err)
+
+	err = db.Create(&models.StockReward{
+		ID:                 "reward-orphan",
+		UserID:             "user123",
+		StockSymbol:        "WIPRO",
+		Quantity:           dec("1"),
+		RewardTimestamp:    time.Now(),
+		StockPriceAtReward: dec("450"),
+	}).Error
+	assert.NoError(t, err)
+
+	err = db.Where("reward_id = ? AND entry_type = ?", "reward-4", models.EntryTypeReversal).
+		Delete(&models.LedgerEntry{}).Error
+	assert.NoError(t, err)
+
+	run := runReconciliation(t, router)
+	assert.Equal(t, models.ReconciliationStatusIssuesFound, run.Status)
+	assert.Equal(t, 5, run.RewardsScanned)
+	assert.Equal(t, 1, run.UnbalancedCount)
+	assert.Equal(t, 1, run.MissingEntriesCount)
+	assert.Equal(t, 2, run.QuantityMismatchCount)
+
+	findings := make(map[string]string)
+	for _, finding := range run.Findings {
+		findings[finding.RewardID] = finding.Type
+	}
+	assert.Equal(t, models.FindingUnbalancedJournal, findings["reward-1"])
+	assert.Equal(t, models.FindingQuantityMismatch, findings["reward-2"])
+	assert.Equal(t, models.FindingMissingEntries, findings["reward-orphan"])
+	assert.Equal(t, models.FindingQuantityMismatch, findings["reward-4"])
+
+	req, _ := http.NewRequest("GET", "/api/admin/reconciliation-runs", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var runs []models.ReconciliationRun
+	err = json.Unmarshal(w.Body.Bytes(), &runs)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(runs))
+
+	req, _ = http.NewRequest("GET", "/api/admin/reconciliation-runs/1", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var stored models.ReconciliationRun
+	err = json.Unmarshal(w.Body.Bytes(), &stored)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(stored.Findings))
+
+	req, _ = http.NewRequest("GET", "/api/admin/reconciliation-runs/99", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
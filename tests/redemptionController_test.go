@@ -0,0 +1,313 @@
+package tests
+
+import (
+	"assignment/controllers"
+	"assignment/decimal"
+	"assignment/models"
+	"assignment/services"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupRedemptionRouter(t *testing.T, prices string) (*gin.Engine, *gorm.DB) {
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	provider, err := services.NewFilePriceProvider(writePriceFile(t, "prices.csv", prices))
+	assert.NoError(t, err)
+	usePriceProvider(a, provider)
+
+	redemptionController := controllers.NewRedemptionController(a)
+	router := setupRouter()
+	router.POST("/redemptions", redemptionController.RedeemShares)
+	router.GET("/redemptions/:userId", redemptionController.GetUserRedemptions)
+	router.GET("/portfolio/:userId", controllers.NewPortfolioController(a).GetUserPortfolio)
+	router.GET("/stats/:userId", controllers.NewStatsController(a).GetUserStats)
+
+	return router, db
+}
+
+func postRedemption(router *gin.Engine, body map[string]interface{}) (*httptest.ResponseRecorder, models.RedemptionResponse) {
+	payload, _ := json.Marshal(body)
+	req, _ := http.NewRequest("POST", "/redemptions", bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response models.RedemptionResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return w, response
+}
+
+func TestRedeemSharesAcrossLots(t *testing.T) {
+	t.Parallel()
+
+	router, db := setupRedemptionRouter(t, "TCS,4000\n")
+
+	createRewardWithLedger(t, db, models.StockReward{
+		ID: "red-1", UserID: "user123", StockSymbol: "TCS", Quantity: dec("3"),
+		RewardTimestamp: startOfDay(5).Add(10 * time.Hour), StockPriceAtReward: dec("3500"),
+	})
+	createRewardWithLedger(t, db, models.StockReward{
+		ID: "red-2", UserID: "user123", StockSymbol: "TCS", Quantity: dec("2"),
+		RewardTimestamp: startOfDay(2).Add(10 * time.Hour), StockPriceAtReward: dec("3600"),
+	})
+
+	w, response := postRedemption(router, map[string]interface{}{
+		"id": "redemption-1", "user_id": "user123", "stock_symbol": "tcs", "quantity": "3.5",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.True(t, response.Success)
+
+	schedule := services.DefaultFeeSchedule()
+	charges := services.CalculateSaleCharges(dec("14000"), &schedule)
+
+	redemption := response.Redemption
+	assert.Equal(t, "TCS", redemption.StockSymbol)
+	assertDecimal(t, "4000", redemption.Price)
+	assertDecimal(t, "14000", redemption.GrossAmount)
+	assertDecimal(t, "12300", redemption.CostBasis)
+	assertDecimal(t, "1700", redemption.RealizedGain)
+	assertDecimal(t, charges.STT.String(), redemption.STT)
+	assertDecimal(t, charges.TotalCharges.String(), redemption.TotalCharges)
+	assertDecimal(t, dec("14000").Sub(charges.TotalCharges).String(), redemption.NetAmount)
+	assert.True(t, redemption.STT.IsPositive())
+	assert.True(t, redemption.Brokerage.IsPositive())
+	assert.Equal(t, 2, redemption.LotsRedeemed)
+
+	var stockEntries []models.LedgerEntry
+	err := db.Where("entry_type = ? AND account_type = ?", models.EntryTypeRedemption, models.AccountTypeStockAsset).
+		Order("reward_id").Find(&stockEntries).Error
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(stockEntries))
+	assertDecimal(t, "-3", *stockEntries[0].Quantity)
+	assertDecimal(t, "10500", stockEntries[0].CreditAmount)
+	assertDecimal(t, "-0.5", *stockEntries[1].Quantity)
+	assertDecimal(t, "1800", stockEntries[1].CreditAmount)
+	assert.Equal(t, "RED-redemption-1", stockEntries[0].ReferenceID)
+
+	// The user bears the sell-side charges: they come out of the payout and
+	// none are booked as company expenses.
+	var totals []struct {
+		AccountType string
+		Debit       decimal.Decimal
+		Credit      decimal.Decimal
+	}
+	err = db.Model(&models.LedgerEntry{}).
+		Select("account_type, SUM(debit_amount) AS debit, SUM(credit_amount) AS credit").
+		Where("entry_type = ?", models.EntryTypeRedemption).
+		Group("account_type").
+		Scan(&totals).Error
+	assert.NoError(t, err)
+	accounts := make(map[string]decimal.Decimal)
+	for _, total := range totals {
+		accounts[total.AccountType] = total.Debit.Sub(total.Credit)
+	}
+	for _, expense := range []string{models.AccountTypeBrokerageExp, models.AccountTypeSTTExp,
+		models.AccountTypeExchangeExp, models.AccountTypeSEBIFeesExp, models.AccountTypeGSTExp} {
+		assert.NotContains(t, accounts, expense)
+	}
+	assertDecimal(t, "14000", accounts[models.AccountTypeRedemptionPayout])
+	assertDecimal(t, "0", accounts[models.AccountTypeCashAccount])
+
+	var chargeEntries []models.LedgerEntry
+	err = db.Where("entry_type = ? AND account_type = ? AND description LIKE ?",
+		models.EntryTypeRedemption, models.AccountTypeCashAccount, "Sell-side charges%").
+		Find(&chargeEntries).Error
+	assert.NoError(t, err)
+	chargesPaid := dec("0")
+	for _, entry := range chargeEntries {
+		chargesPaid = chargesPaid.Add(entry.CreditAmount)
+	}
+	assertDecimal(t, charges.TotalCharges.String(), chargesPaid)
+
+	assertPortfolioQuantities(t, router, "user123", map[string]string{"TCS": "1.5"})
+
+	req, _ := http.NewRequest("GET", "/stats/user123", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var stats models.StatsResponse
+	err = json.Unmarshal(w.Body.Bytes(), &stats)
+	assert.NoError(t, err)
+	assertDecimal(t, "1.5", stats.TotalSharesRewarded)
+	assertDecimal(t, "6000", stats.CurrentPortfolioINR)
+
+	positions, err := services.GetUserPositions(db, "user123", startOfDay(0))
+	assert.NoError(t, err)
+	assertDecimal(t, "5400", positions[0].BookValue)
+
+	trialBalance, err := services.GetTrialBalance(db, time.Now())
+	assert.NoError(t, err)
+	assert.True(t, trialBalance.Balanced)
+
+	run, err := services.RunReconciliation(db, services.SystemClock{})
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReconciliationStatusClean, run.Status)
+
+	_, _, err = services.ReverseReward(db, services.SystemClock{}, noRewardLimits(), "red-1", "Issued in error")
+	assert.ErrorIs(t, err, services.ErrRewardSharesDisposed)
+
+	req, _ = http.NewRequest("GET", "/redemptions/user123", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var redemptions []models.Redemption
+	err = json.Unmarshal(w.Body.Bytes(), &redemptions)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(redemptions))
+	assert.Equal(t, "redemption-1", redemptions[0].ID)
+}
+
+func TestRedeemSharesIdempotencyAndLimits(t *testing.T) {
+	t.Parallel()
+
+	router, db := setupRedemptionRouter(t, "WIPRO,500\n")
+
+	createRewardWithLedger(t, db, models.StockReward{
+		ID: "red-limit", UserID: "user123", StockSymbol: "WIPRO", Quantity: dec("2"),
+		RewardTimestamp: startOfDay(3), StockPriceAtReward: dec("480"),
+	})
+
+	body := map[string]interface{}{"id": "redemption-2", "user_id": "user123", "stock_symbol": "WIPRO", "quantity": "0.75"}
+	w, first := postRedemption(router, body)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w, replay := postRedemption(router, body)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, first.Redemption.ID, replay.Redemption.ID)
+	assertDecimal(t, first.Redemption.NetAmount.String(), replay.Redemption.NetAmount)
+	assert.Equal(t, len(first.LedgerEntries), len(replay.LedgerEntries))
+
+	var count int64
+	db.Model(&models.Redemption{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+
+	body["quantity"] = "1"
+	w, _ = postRedemption(router, body)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w, response := postRedemption(router, map[string]interface{}{
+		"id": "redemption-3", "user_id": "user123", "stock_symbol": "WIPRO", "quantity": "1.5",
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, response.Message, "available 1.25")
+
+	w, _ = postRedemption(router, map[string]interface{}{
+		"id": "redemption-4", "user_id": "user123", "stock_symbol": "WIPRO", "quantity": "1.25",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assertPortfolioQuantities(t, router, "user123", map[string]string{})
+
+	w, _ = postRedemption(router, map[string]interface{}{
+		"id": "redemption-5", "user_id": "user456", "stock_symbol": "WIPRO", "quantity": "1",
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
+
+func TestRedeemSharesValidation(t *testing.T) {
+	t.Parallel()
+
+	router, _ := setupRedemptionRouter(t, "TCS,4000\n")
+
+	tests := []struct {
+		name         string
+		body         map[string]interface{}
+		expectedCode int
+	}{
+		{
+			name:         "missing id",
+			body:         map[string]interface{}{"user_id": "user123", "stock_symbol": "TCS", "quantity": "1"},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "zero quantity",
+			body:         map[string]interface{}{"id": "r1", "user_id": "user123", "stock_symbol": "TCS", "quantity": "0"},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "unknown symbol",
+			body:         map[string]interface{}{"id": "r2", "user_id": "user123", "stock_symbol": "NOPE", "quantity": "1"},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, response := postRedemption(router, tt.body)
+			assert.Equal(t, tt.expectedCode, w.Code, w.Body.String())
+			assert.False(t, response.Success)
+		})
+	}
+}
+
+func TestReverseRewardRacesRedemptionAndTransfer(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDBAt(t, filepath.Join(t.TempDir(), "race.db"))
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	provider, err := services.NewFilePriceProvider(writePriceFile(t, "prices.csv", "TCS,4000\n"))
+	assert.NoError(t, err)
+	prices := services.NewPriceService(db, provider, services.SystemClock{})
+
+	for round := 0; round < 10; round++ {
+		rewardID := fmt.Sprintf("race-%d", round)
+		userID := fmt.Sprintf("racer-%d", round)
+		createRewardWithLedger(t, db, models.StockReward{
+			ID: rewardID, UserID: userID, StockSymbol: "TCS", Quantity: dec("2"),
+			RewardTimestamp: startOfDay(3), StockPriceAtReward: dec("3500"),
+		})
+
+		var wg sync.WaitGroup
+		var reverseErr, disposeErr error
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, _, reverseErr = services.ReverseReward(db, services.SystemClock{}, noRewardLimits(), rewardID, "Issued in error")
+		}()
+		go func() {
+			defer wg.Done()
+			if round%2 == 0 {
+				_, _, disposeErr = services.CreateRedemption(db, services.SystemClock{}, prices, models.RedemptionRequest{
+					ID: rewardID, UserID: userID, StockSymbol: "TCS", Quantity: dec("2"),
+				})
+			} else {
+				_, _, disposeErr = services.CreateDematTransfer(db, services.SystemClock{}, models.DematTransferRequest{
+					ID: rewardID, UserID: userID, StockSymbol: "TCS", Quantity: dec("2"),
+					DepositoryParticipantID: "IN300000", BeneficiaryAccount: "1234567890123456",
+				})
+			}
+		}()
+		wg.Wait()
+
+		assert.True(t, (reverseErr == nil) != (disposeErr == nil),
+			"round %d: reversal error %v, redemption/transfer error %v", round, reverseErr, disposeErr)
+
+		var reward models.StockReward
+		assert.NoError(t, db.First(&reward, "id = ?", rewardID).Error)
+		var disposals int64
+		db.Model(&models.Redemption{}).Where("id = ?", rewardID).Count(&disposals)
+		var transfers int64
+		db.Model(&models.DematTransfer{}).Where("id = ?", rewardID).Count(&transfers)
+		if reward.Status == models.RewardStatusReversed {
+			assert.Zero(t, disposals+transfers, "round %d", round)
+		} else {
+			assert.Equal(t, int64(1), disposals+transfers, "round %d", round)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package tests
+
+import (
+	"assignment/app"
+	"assignment/controllers"
+	"assignment/models"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupBatchRouter(a *app.App) *gin.Engine {
+	router := setupRouter()
+	router.POST("/api/reward", controllers.NewRewardController(a).RewardUser)
+	router.POST("/api/rewards/batch", controllers.NewRewardBatchController(a).RewardUsersBatch)
+	return router
+}
+
+func postBatch(router *gin.Engine, body string, contentType string) (*httptest.ResponseRecorder, models.BatchRewardResponse) {
+	req, _ := http.NewRequest("POST", "/api/rewards/batch", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", contentType)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response models.BatchRewardResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return w, response
+}
+
+func TestRewardUsersBatchMixedResults(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	router := setupBatchRouter(a)
+
+	for _, payload := range []string{
+		`{"id":"existing-same","user_id":"user1","stock_symbol":"TCS","quantity":1,"reward_timestamp":"2025-11-17T10:30:00Z"}`,
+		`{"id":"existing-diff","user_id":"user1","stock_symbol":"TCS","quantity":1,"reward_timestamp":"2025-11-17T10:30:00Z"}`,
+	} {
+		req, _ := http.NewRequest("POST", "/api/reward", bytes.NewBufferString(payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	body := `[
+		{"id":"batch-1","user_id":"user1","stock_symbol":"TCS","quantity":2,"reward_timestamp":"2025-11-17T10:30:00Z"},
+		{"id":"batch-2","user_id":"user2","stock_symbol":"RELIANCE","quantity":"0.5","reward_timestamp":"2025-11-17T10:30:00Z"},
+		{"id":"batch-3","user_id":"user3","stock_symbol":"TCS","quantity":0,"reward_timestamp":"2025-11-17T10:30:00Z"},
+		{"id":"batch-4","user_id":"user4","stock_symbol":"NOPE","quantity":1,"reward_timestamp":"2025-11-17T10:30:00Z"},
+		{"id":"batch-1","user_id":"user1","stock_symbol":"TCS","quantity":2,"reward_timestamp":"2025-11-17T10:30:00Z"},
+		{"id":"existing-same","user_id":"user1","stock_symbol":"TCS","quantity":"1.000","reward_timestamp":"2025-11-17T10:30:00Z"},
+		{"id":"existing-diff","user_id":"user9","stock_symbol":"TCS","quantity":1,"reward_timestamp":"2025-11-17T10:30:00Z"},
+		{"id":"batch-5","user_id":"user5","stock_symbol":"TCS","quantity":"abc","reward_timestamp":"2025-11-17T10:30:00Z"}
+	]`
+
+	w, response := postBatch(router, body, "application/json")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	assert.Equal(t, 8, response.Total)
+	assert.Equal(t, 2, response.Created)
+	assert.Equal(t, 3, response.Duplicate)
+	assert.Equal(t, 3, response.Invalid)
+	assert.Equal(t, 0, response.Failed)
+
+	expected := []string{
+		models.BatchStatusCreated,
+		models.BatchStatusCreated,
+		models.BatchStatusInvalid,
+		models.BatchStatusInvalid,
+		models.BatchStatusDuplicate,
+		models.BatchStatusDuplicate,
+		models.BatchStatusDuplicate,
+		models.BatchStatusInvalid,
+	}
+	for i, status := range expected {
+		assert.Equal(t, i, response.Results[i].Index)
+		assert.Equal(t, status, response.Results[i].Status, "item %d: %s", i, response.Results[i].Message)
+	}
+
+	assert.NotNil(t, response.Results[5].Reward)
+	assert.Equal(t, models.FieldDiff{Stored: "user1", Received: "user9"}, response.Results[6].Diff["user_id"])
+
+	var rewardCount, entryCount int64
+	db.Model(&models.StockReward{}).Count(&rewardCount)
+	db.Model(&models.LedgerEntry{}).Count(&entryCount)
+	assert.Equal(t, int64(4), rewardCount)
+	assert.Equal(t, int64(20), entryCount)
+
+	var reward models.StockReward
+	err := db.Where("id = ?", "batch-2").First(&reward).Error
+	assert.NoError(t, err)
+	assert.Equal(t, "user2", reward.UserID)
+	assertDecimal(t, "0.5", reward.Quantity)
+}
+
+func TestRewardUsersBatchNDJSON(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	router := setupBatchRouter(a)
+
+	var lines []string
+	for i := 0; i < 150; i++ {
+		lines = append(lines, fmt.Sprintf(`{"id":"nd-%d","user_id":"user%d","stock_symbol":"ITC","quantity":1,"reward_timestamp":"2025-11-17T10:30:00Z"}`, i, i%7))
+	}
+	lines = append(lines, `{"id":"nd-broken",`)
+
+	w, response := postBatch(router, strings.Join(lines, "\n")+"\n", "application/x-ndjson")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 151, response.Total)
+	assert.Equal(t, 150, response.Created)
+	assert.Equal(t, 1, response.Invalid)
+	assert.Equal(t, models.BatchStatusInvalid, response.Results[150].Status)
+
+	var rewardCount int64
+	db.Model(&models.StockReward{}).Count(&rewardCount)
+	assert.Equal(t, int64(150), rewardCount)
+}
+
+func TestRewardUsersBatchLimits(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+	a.Batch.MaxItems = 2
+
+	router := setupBatchRouter(a)
+
+	item := `{"id":"%s","user_id":"user1","stock_symbol":"TCS","quantity":1,"reward_timestamp":"2025-11-17T10:30:00Z"}`
+	body := "[" + fmt.Sprintf(item, "a") + "," + fmt.Sprintf(item, "b") + "," + fmt.Sprintf(item, "c") + "]"
+
+	w, _ := postBatch(router, body, "application/json")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	padded := "[" + fmt.Sprintf(item, "a") + strings.Repeat(" ", 10<<10) + "]"
+	w, _ = postBatch(router, padded, "application/json")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "Batch body exceeds")
+
+	w, _ = postBatch(router, "[]", "application/json")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w, _ = postBatch(router, `[{"id":"a"`, "application/json")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -2,7 +2,7 @@ package tests
 
 import (
 	"assignment/controllers"
-	"assignment/initializers"
+	"assignment/decimal"
 	"assignment/models"
 	"bytes"
 	"encoding/json"
@@ -15,18 +15,19 @@ import (
 )
 
 func TestRewardUserSuccess(t *testing.T) {
+	t.Parallel()
+
 	db := setupTestDB(t)
-	initializers.DB = db
-	setupTestLogger()
+	a := newTestApp(t, db)
 
 	router := setupRouter()
-	router.POST("/api/reward", controllers.RewardUser)
+	router.POST("/api/reward", controllers.NewRewardController(a).RewardUser)
 
 	rewardReq := models.RewardRequest{
 		ID:              "reward-123",
 		UserID:          "user123",
 		StockSymbol:     "RELIANCE",
-		Quantity:        10.5,
+		Quantity:        dec("10.5"),
 		RewardTimestamp: time.Now(),
 	}
 
@@ -46,14 +47,14 @@ func TestRewardUserSuccess(t *testing.T) {
 	assert.Equal(t, "Stock reward recorded successfully", response.Message)
 	assert.NotNil(t, response.Reward)
 	assert.NotNil(t, response.CompanyCharges)
-	assert.Greater(t, response.INRValue, 0.0)
+	assert.True(t, response.INRValue.IsPositive())
 
 	var savedReward models.StockReward
 	err = db.Where("id = ?", "reward-123").First(&savedReward).Error
 	assert.NoError(t, err)
 	assert.Equal(t, "user123", savedReward.UserID)
 	assert.Equal(t, "RELIANCE", savedReward.StockSymbol)
-	assert.Equal(t, 10.5, savedReward.Quantity)
+	assertDecimal(t, "10.5", savedReward.Quantity)
 
 	var ledgerEntries []models.LedgerEntry
 	err = db.Where("reward_id = ?", "reward-123").Find(&ledgerEntries).Error
@@ -62,12 +63,13 @@ func TestRewardUserSuccess(t *testing.T) {
 }
 
 func TestRewardUserInvalidRequest(t *testing.T) {
+	t.Parallel()
+
 	db := setupTestDB(t)
-	initializers.DB = db
-	setupTestLogger()
+	a := newTestApp(t, db)
 
 	router := setupRouter()
-	router.POST("/api/reward", controllers.RewardUser)
+	router.POST("/api/reward", controllers.NewRewardController(a).RewardUser)
 
 	req, _ := http.NewRequest("POST", "/api/reward", bytes.NewBuffer([]byte("invalid json")))
 	req.Header.Set("Content-Type", "application/json")
@@ -84,20 +86,21 @@ func TestRewardUserInvalidRequest(t *testing.T) {
 }
 
 func TestRewardUserDuplicateID(t *testing.T) {
+	t.Parallel()
+
 	db := setupTestDB(t)
-	initializers.DB = db
-	setupTestLogger()
+	a := newTestApp(t, db)
 
 	router := setupRouter()
-	router.POST("/api/reward", controllers.RewardUser)
+	router.POST("/api/reward", controllers.NewRewardController(a).RewardUser)
 
 	existingReward := models.StockReward{
 		ID:                 "reward-123",
 		UserID:             "user123",
 		StockSymbol:        "RELIANCE",
-		Quantity:           10.0,
+		Quantity:           dec("10.0"),
 		RewardTimestamp:    time.Now(),
-		StockPriceAtReward: 2500.0,
+		StockPriceAtReward: dec("2500.0"),
 	}
 	err := db.Create(&existingReward).Error
 	assert.NoError(t, err)
@@ -106,7 +109,7 @@ func TestRewardUserDuplicateID(t *testing.T) {
 		ID:              "reward-123",
 		UserID:          "user456",
 		StockSymbol:     "TCS",
-		Quantity:        5.0,
+		Quantity:        dec("5.0"),
 		RewardTimestamp: time.Now(),
 	}
 
@@ -127,18 +130,19 @@ func TestRewardUserDuplicateID(t *testing.T) {
 }
 
 func TestRewardUserCompanyChargesCalculation(t *testing.T) {
+	t.Parallel()
+
 	db := setupTestDB(t)
-	initializers.DB = db
-	setupTestLogger()
+	a := newTestApp(t, db)
 
 	router := setupRouter()
-	router.POST("/api/reward", controllers.RewardUser)
+	router.POST("/api/reward", controllers.NewRewardController(a).RewardUser)
 
 	rewardReq := models.RewardRequest{
 		ID:              "reward-123",
 		UserID:          "user123",
 		StockSymbol:     "RELIANCE",
-		Quantity:        10.0,
+		Quantity:        dec("10.0"),
 		RewardTimestamp: time.Now(),
 	}
 
@@ -157,18 +161,385 @@ func TestRewardUserCompanyChargesCalculation(t *testing.T) {
 	assert.NotNil(t, response.CompanyCharges)
 
 	charges := response.CompanyCharges
-	assert.Greater(t, charges.StockCost, 0.0)
-	assert.Greater(t, charges.Brokerage, 0.0)
-	assert.Greater(t, charges.STT, 0.0)
-	assert.Greater(t, charges.GST, 0.0)
-	assert.Greater(t, charges.TotalCost, charges.StockCost)
+	assert.True(t, charges.StockCost.IsPositive())
+	assert.True(t, charges.Brokerage.IsPositive())
+	assert.True(t, charges.STT.IsPositive())
+	assert.True(t, charges.GST.IsPositive())
+	assert.True(t, charges.TotalCost.GreaterThan(charges.StockCost))
+
+	expectedBrokerage := charges.StockCost.Mul(dec("0.0003")).RoundBank(2)
+	assert.True(t, expectedBrokerage.Equal(charges.Brokerage))
+
+	expectedSTT := charges.StockCost.Mul(dec("0.001")).RoundBank(2)
+	assert.True(t, expectedSTT.Equal(charges.STT))
+
+	expectedGST := charges.Brokerage.Mul(dec("0.18")).RoundBank(2)
+	assert.True(t, expectedGST.Equal(charges.GST))
+
+	total := charges.StockCost.Add(charges.Brokerage).Add(charges.STT).Add(charges.GST)
+	assert.True(t, total.Equal(charges.TotalCost))
+}
+
+func TestRewardUserUnknownSymbol(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	router := setupRouter()
+	router.POST("/api/reward", controllers.NewRewardController(a).RewardUser)
+
+	rewardReq := models.RewardRequest{
+		ID:              "reward-123",
+		UserID:          "user123",
+		StockSymbol:     "RELIANC",
+		Quantity:        dec("10.0"),
+		RewardTimestamp: time.Now(),
+	}
+
+	body, _ := json.Marshal(rewardReq)
+	req, _ := http.NewRequest("POST", "/api/reward", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	var response models.RewardResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.False(t, response.Success)
+	assert.Contains(t, response.Message, "unknown stock symbol")
+
+	var count int64
+	db.Model(&models.LedgerEntry{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestRewardUserDelistedSymbol(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	router := setupRouter()
+	router.POST("/api/reward", controllers.NewRewardController(a).RewardUser)
+
+	err := db.Model(&models.Instrument{}).Where("symbol = ?", "HDFC").
+		Update("status", models.InstrumentStatusDelisted).Error
+	assert.NoError(t, err)
+
+	rewardReq := models.RewardRequest{
+		ID:              "reward-123",
+		UserID:          "user123",
+		StockSymbol:     "HDFC",
+		Quantity:        dec("10.0"),
+		RewardTimestamp: time.Now(),
+	}
+
+	body, _ := json.Marshal(rewardReq)
+	req, _ := http.NewRequest("POST", "/api/reward", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	var response models.RewardResponse
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.False(t, response.Success)
+	assert.Contains(t, response.Message, "not active")
+}
+
+func TestRewardUserRejectsNonPositiveQuantity(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	router := setupRouter()
+	router.POST("/api/reward", controllers.NewRewardController(a).RewardUser)
+
+	payloads := []string{
+		`{"id":"reward-1","user_id":"user123","stock_symbol":"TCS","quantity":0,"reward_timestamp":"2025-11-17T10:30:00Z"}`,
+		`{"id":"reward-2","user_id":"user123","stock_symbol":"TCS","quantity":"-1.5","reward_timestamp":"2025-11-17T10:30:00Z"}`,
+		`{"id":"reward-3","user_id":"user123","stock_symbol":"TCS","reward_timestamp":"2025-11-17T10:30:00Z"}`,
+	}
+
+	for _, payload := range payloads {
+		req, _ := http.NewRequest("POST", "/api/reward", bytes.NewBufferString(payload))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, payload)
+	}
+
+	req, _ := http.NewRequest("POST", "/api/reward", bytes.NewBufferString(
+		`{"id":"reward-4","user_id":"user123","stock_symbol":"TCS","quantity":2.5,"reward_timestamp":"2025-11-17T10:30:00Z"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var ledgerEntries []models.LedgerEntry
+	err := db.Where("reward_id = ?", "reward-4").Find(&ledgerEntries).Error
+	assert.NoError(t, err)
+
+	debits := dec("0")
+	credits := dec("0")
+	for _, entry := range ledgerEntries {
+		debits = debits.Add(entry.DebitAmount)
+		credits = credits.Add(entry.CreditAmount)
+	}
+	assert.True(t, debits.Equal(credits), "debits %s != credits %s", debits, credits)
+}
+
+func TestRewardUserQuantityPrecision(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	router := setupRouter()
+	router.POST("/api/reward", controllers.NewRewardController(a).RewardUser)
+
+	req, _ := http.NewRequest("POST", "/api/reward", bytes.NewBufferString(
+		`{"id":"reward-1","user_id":"user123","stock_symbol":"TCS","quantity":"0.0000004","reward_timestamp":"2025-11-17T10:30:00Z"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var count int64
+	db.Model(&models.StockReward{}).Count(&count)
+	assert.Zero(t, count)
+
+	req, _ = http.NewRequest("POST", "/api/reward", bytes.NewBufferString(
+		`{"id":"reward-2","user_id":"user123","stock_symbol":"TCS","quantity":"1.123456","reward_timestamp":"2025-11-17T10:30:00Z"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var reward models.StockReward
+	assert.NoError(t, db.First(&reward, "id = ?", "reward-2").Error)
+	assertDecimal(t, "1.123456", reward.Quantity)
+}
+
+func TestReverseReward(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	rewardController := controllers.NewRewardController(a)
+	router := setupRouter()
+	router.POST("/api/reward", rewardController.RewardUser)
+	router.POST("/api/reward/:id/reverse", rewardController.ReverseReward)
+	router.GET("/api/portfolio/:userId", controllers.NewPortfolioController(a).GetUserPortfolio)
+	router.GET("/api/stats/:userId", controllers.NewStatsController(a).GetUserStats)
+
+	for _, id := range []string{"reward-keep", "reward-fraud"} {
+		rewardReq := models.RewardRequest{
+			ID:              id,
+			UserID:          "user123",
+			StockSymbol:     "TCS",
+			Quantity:        dec("3"),
+			RewardTimestamp: time.Now(),
+		}
+		body, _ := json.Marshal(rewardReq)
+		req, _ := http.NewRequest("POST", "/api/reward", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	req, _ := http.NewRequest("POST", "/api/reward/reward-fraud/reverse", bytes.NewBufferString(`{"reason":"fraudulent referral"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.ReversalResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.True(t, response.Success)
+	assert.Equal(t, 5, len(response.LedgerEntries))
+
+	var reward models.StockReward
+	err = db.Where("id = ?", "reward-fraud").First(&reward).Error
+	assert.NoError(t, err)
+	assert.Equal(t, models.RewardStatusReversed, reward.Status)
+	assert.Equal(t, "fraudulent referral", reward.ReversalReason)
+	assert.NotNil(t, reward.ReversedAt)
+
+	var entries []models.LedgerEntry
+	err = db.Where("reward_id = ?", "reward-fraud").Find(&entries).Error
+	assert.NoError(t, err)
+	assert.Equal(t, 10, len(entries))
+
+	balances := make(map[string]decimal.Decimal)
+	for _, entry := range entries {
+		balances[entry.AccountType] = balances[entry.AccountType].Add(entry.DebitAmount).Sub(entry.CreditAmount)
+		if entry.EntryType == models.EntryTypeReversal && entry.AccountType == models.AccountTypeStockAsset {
+			assert.True(t, entry.CreditAmount.IsPositive())
+		}
+		if entry.EntryType == models.EntryTypeReversal && entry.AccountType == models.AccountTypeCashAccount {
+			assert.True(t, entry.DebitAmount.IsPositive())
+		}
+	}
+	for accountType, balance := range balances {
+		assert.True(t, balance.IsZero(), "%s does not net to zero: %s", accountType, balance)
+	}
+
+	req, _ = http.NewRequest("GET", "/api/portfolio/user123", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var portfolio models.PortfolioResponse
+	err = json.Unmarshal(w.Body.Bytes(), &portfolio)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(portfolio.Holdings))
+	assertDecimal(t, "3", portfolio.Holdings[0].TotalQuantity)
+
+	req, _ = http.NewRequest("GET", "/api/stats/user123", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var stats models.StatsResponse
+	err = json.Unmarshal(w.Body.Bytes(), &stats)
+	assert.NoError(t, err)
+	assertDecimal(t, "3", stats.TotalSharesRewarded)
+	assertDecimal(t, "3", stats.TodayRewards["TCS"])
+}
+
+func TestReverseRewardErrors(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
 
-	expectedBrokerage := charges.StockCost * 0.0003
-	assert.InDelta(t, expectedBrokerage, charges.Brokerage, 1.0)
+	rewardController := controllers.NewRewardController(a)
+	router := setupRouter()
+	router.POST("/api/reward", rewardController.RewardUser)
+	router.POST("/api/reward/:id/reverse", rewardController.ReverseReward)
 
-	expectedSTT := charges.StockCost * 0.001
-	assert.InDelta(t, expectedSTT, charges.STT, 1.0)
+	rewardReq := models.RewardRequest{
+		ID:              "reward-123",
+		UserID:          "user123",
+		StockSymbol:     "INFOSYS",
+		Quantity:        dec("1"),
+		RewardTimestamp: time.Now(),
+	}
+	body, _ := json.Marshal(rewardReq)
+	req, _ := http.NewRequest("POST", "/api/reward", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	testCases := []struct {
+		path     string
+		payload  string
+		expected int
+	}{
+		{"/api/reward/reward-123/reverse", `{}`, http.StatusBadRequest},
+		{"/api/reward/missing/reverse", `{"reason":"ops"}`, http.StatusNotFound},
+		{"/api/reward/reward-123/reverse", `{"reason":"ops"}`, http.StatusOK},
+		{"/api/reward/reward-123/reverse", `{"reason":"ops"}`, http.StatusConflict},
+	}
 
-	expectedGST := charges.Brokerage * 0.18
-	assert.InDelta(t, expectedGST, charges.GST, 1.0)
+	for _, tc := range testCases {
+		req, _ := http.NewRequest("POST", tc.path, bytes.NewBufferString(tc.payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, tc.expected, w.Code, tc.path+" "+tc.payload)
+	}
+
+	var count int64
+	db.Model(&models.LedgerEntry{}).Where("reward_id = ? AND entry_type = ?", "reward-123", models.EntryTypeReversal).Count(&count)
+	assert.Equal(t, int64(5), count)
+}
+
+func TestRewardUserReplaysIdenticalRequest(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	router := setupRouter()
+	router.POST("/api/reward", controllers.NewRewardController(a).RewardUser)
+
+	payloads := []string{
+		`{"id":"reward-123","user_id":"user123","stock_symbol":"HDFC","quantity":2.5,"reward_timestamp":"2025-11-17T10:30:00Z"}`,
+		`{"reward_timestamp":"2025-11-17T16:00:00+05:30","quantity":"2.50","stock_symbol":"HDFC","user_id":"user123","id":"reward-123"}`,
+	}
+
+	var bodies []string
+	for _, payload := range payloads {
+		req, _ := http.NewRequest("POST", "/api/reward", bytes.NewBufferString(payload))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		bodies = append(bodies, w.Body.String())
+	}
+
+	assert.JSONEq(t, bodies[0], bodies[1])
+
+	var rewardCount, entryCount int64
+	db.Model(&models.StockReward{}).Count(&rewardCount)
+	db.Model(&models.LedgerEntry{}).Count(&entryCount)
+	assert.Equal(t, int64(1), rewardCount)
+	assert.Equal(t, int64(5), entryCount)
+}
+
+func TestRewardUserConflictingPayload(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	router := setupRouter()
+	router.POST("/api/reward", controllers.NewRewardController(a).RewardUser)
+
+	payloads := []struct {
+		body     string
+		expected int
+	}{
+		{`{"id":"reward-123","user_id":"user123","stock_symbol":"HDFC","quantity":2.5,"reward_timestamp":"2025-11-17T10:30:00Z"}`, http.StatusCreated},
+		{`{"id":"reward-123","user_id":"user123","stock_symbol":"ITC","quantity":3,"reward_timestamp":"2025-11-17T10:30:00Z"}`, http.StatusConflict},
+	}
+
+	var response models.RewardResponse
+	for _, payload := range payloads {
+		req, _ := http.NewRequest("POST", "/api/reward", bytes.NewBufferString(payload.body))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, payload.expected, w.Code)
+		response = models.RewardResponse{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+	}
+
+	assert.False(t, response.Success)
+	assert.Contains(t, response.Message, "different payload")
+	assert.Equal(t, 2, len(response.Diff))
+	assert.Equal(t, models.FieldDiff{Stored: "HDFC", Received: "ITC"}, response.Diff["stock_symbol"])
+	assert.Equal(t, models.FieldDiff{Stored: "2.5", Received: "3"}, response.Diff["quantity"])
 }
@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"assignment/controllers"
+	"assignment/models"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRewardListRouter(t *testing.T) *gin.Engine {
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	base := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	rewards := []models.StockReward{
+		{ID: "list-a", StockSymbol: "TCS", RewardTimestamp: base},
+		{ID: "list-b", StockSymbol: "RELIANCE", RewardTimestamp: base.Add(time.Hour)},
+		{ID: "list-c", StockSymbol: "TCS", RewardTimestamp: base.Add(time.Hour)},
+		{ID: "list-d", StockSymbol: "INFY", RewardTimestamp: base.Add(2 * time.Hour), Status: models.RewardStatusReversed},
+		{ID: "list-e", StockSymbol: "TCS", RewardTimestamp: base.Add(24 * time.Hour)},
+		{ID: "other-user", UserID: "user456", StockSymbol: "TCS", RewardTimestamp: base},
+	}
+	for _, reward := range rewards {
+		if reward.UserID == "" {
+			reward.UserID = "user123"
+		}
+		reward.Quantity = dec("1")
+		reward.StockPriceAtReward = dec("1000")
+		assert.NoError(t, db.Create(&reward).Error)
+	}
+
+	router := setupRouter()
+	router.GET("/rewards/:userId", controllers.NewRewardController(a).ListUserRewards)
+	return router
+}
+
+func listRewards(t *testing.T, router *gin.Engine, query url.Values) (*httptest.ResponseRecorder, models.RewardListResponse) {
+	t.Helper()
+
+	req, _ := http.NewRequest("GET", "/rewards/user123?"+query.Encode(), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response models.RewardListResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return w, response
+}
+
+func rewardIDs(rewards []models.StockReward) []string {
+	ids := make([]string, 0, len(rewards))
+	for _, reward := range rewards {
+		ids = append(ids, reward.ID)
+	}
+	return ids
+}
+
+func TestListUserRewardsPagesWithCursor(t *testing.T) {
+	t.Parallel()
+
+	router := setupRewardListRouter(t)
+
+	var pages [][]string
+	query := url.Values{"limit": {"2"}}
+	for {
+		w, response := listRewards(t, router, query)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		pages = append(pages, rewardIDs(response.Rewards))
+		if response.NextCursor == "" {
+			break
+		}
+		query.Set("cursor", response.NextCursor)
+	}
+	assert.Equal(t, [][]string{{"list-e", "list-d"}, {"list-c", "list-b"}, {"list-a"}}, pages)
+
+	pages = nil
+	query = url.Values{"limit": {"3"}, "sort": {"asc"}}
+	for {
+		_, response := listRewards(t, router, query)
+		pages = append(pages, rewardIDs(response.Rewards))
+		if response.NextCursor == "" {
+			break
+		}
+		query.Set("cursor", response.NextCursor)
+	}
+	assert.Equal(t, [][]string{{"list-a", "list-b", "list-c"}, {"list-d", "list-e"}}, pages)
+}
+
+func TestListUserRewardsFilters(t *testing.T) {
+	t.Parallel()
+
+	router := setupRewardListRouter(t)
+
+	_, response := listRewards(t, router, url.Values{"symbol": {"tcs"}})
+	assert.Equal(t, []string{"list-e", "list-c", "list-a"}, rewardIDs(response.Rewards))
+	assert.Empty(t, response.NextCursor)
+
+	_, response = listRewards(t, router, url.Values{"status": {"reversed"}})
+	assert.Equal(t, []string{"list-d"}, rewardIDs(response.Rewards))
+
+	_, response = listRewards(t, router, url.Values{
+		"from": {"2026-03-10T15:00:00+05:30"},
+		"to":   {"2026-03-10T11:00:00Z"},
+	})
+	assert.Equal(t, []string{"list-c", "list-b"}, rewardIDs(response.Rewards))
+}
+
+func TestListUserRewardsRejectsBadQueries(t *testing.T) {
+	t.Parallel()
+
+	router := setupRewardListRouter(t)
+
+	for _, query := range []url.Values{
+		{"cursor": {"not-a-cursor!"}},
+		{"cursor": {"bm8tc2VwYXJhdG9y"}},
+		{"sort": {"sideways"}},
+		{"limit": {"501"}},
+		{"from": {"yesterday"}},
+	} {
+		w, _ := listRewards(t, router, query)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query.Encode())
+	}
+}
@@ -2,7 +2,6 @@ package tests
 
 import (
 	"assignment/controllers"
-	"assignment/initializers"
 	"assignment/models"
 	"encoding/json"
 	"net/http"
@@ -14,12 +13,13 @@ import (
 )
 
 func TestGetUserStatsSuccess(t *testing.T) {
+	t.Parallel()
+
 	db := setupTestDB(t)
-	initializers.DB = db
-	setupTestLogger()
+	a := newTestApp(t, db)
 
 	router := setupRouter()
-	router.GET("/api/stats/:userId", controllers.GetUserStats)
+	router.GET("/api/stats/:userId", controllers.NewStatsController(a).GetUserStats)
 
 	now := time.Now()
 	yesterday := now.AddDate(0, 0, -1)
@@ -29,23 +29,22 @@ func TestGetUserStatsSuccess(t *testing.T) {
 			ID:                 "stat-1",
 			UserID:             "user123",
 			StockSymbol:        "RELIANCE",
-			Quantity:           10.0,
+			Quantity:           dec("10.0"),
 			RewardTimestamp:    now,
-			StockPriceAtReward: 2500.0,
+			StockPriceAtReward: dec("2500.0"),
 		},
 		{
 			ID:                 "stat-2",
 			UserID:             "user123",
 			StockSymbol:        "TCS",
-			Quantity:           5.0,
+			Quantity:           dec("5.0"),
 			RewardTimestamp:    yesterday,
-			StockPriceAtReward: 3500.0,
+			StockPriceAtReward: dec("3500.0"),
 		},
 	}
 
 	for _, reward := range rewards {
-		err := db.Create(&reward).Error
-		assert.NoError(t, err)
+		createRewardWithLedger(t, db, reward)
 	}
 
 	req, _ := http.NewRequest("GET", "/api/stats/user123", nil)
@@ -59,17 +58,18 @@ func TestGetUserStatsSuccess(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "user123", response.UserID)
 	assert.NotNil(t, response.TodayRewards)
-	assert.Greater(t, response.CurrentPortfolioINR, 0.0)
-	assert.Greater(t, response.TotalSharesRewarded, 0.0)
+	assert.True(t, response.CurrentPortfolioINR.IsPositive())
+	assert.True(t, response.TotalSharesRewarded.IsPositive())
 }
 
 func TestGetUserStatsNoData(t *testing.T) {
+	t.Parallel()
+
 	db := setupTestDB(t)
-	initializers.DB = db
-	setupTestLogger()
+	a := newTestApp(t, db)
 
 	router := setupRouter()
-	router.GET("/api/stats/:userId", controllers.GetUserStats)
+	router.GET("/api/stats/:userId", controllers.NewStatsController(a).GetUserStats)
 
 	req, _ := http.NewRequest("GET", "/api/stats/user999", nil)
 	w := httptest.NewRecorder()
@@ -83,17 +83,18 @@ func TestGetUserStatsNoData(t *testing.T) {
 	assert.Equal(t, "user999", response.UserID)
 	assert.NotNil(t, response.TodayRewards)
 	assert.Equal(t, 0, len(response.TodayRewards))
-	assert.Equal(t, 0.0, response.CurrentPortfolioINR)
-	assert.Equal(t, 0.0, response.TotalSharesRewarded)
+	assertDecimal(t, "0", response.CurrentPortfolioINR)
+	assertDecimal(t, "0", response.TotalSharesRewarded)
 }
 
 func TestGetUserStatsTodayRewardsOnly(t *testing.T) {
+	t.Parallel()
+
 	db := setupTestDB(t)
-	initializers.DB = db
-	setupTestLogger()
+	a := newTestApp(t, db)
 
 	router := setupRouter()
-	router.GET("/api/stats/:userId", controllers.GetUserStats)
+	router.GET("/api/stats/:userId", controllers.NewStatsController(a).GetUserStats)
 
 	now := time.Now()
 
@@ -102,23 +103,22 @@ func TestGetUserStatsTodayRewardsOnly(t *testing.T) {
 			ID:                 "stat-1",
 			UserID:             "user123",
 			StockSymbol:        "RELIANCE",
-			Quantity:           10.0,
+			Quantity:           dec("10.0"),
 			RewardTimestamp:    now,
-			StockPriceAtReward: 2500.0,
+			StockPriceAtReward: dec("2500.0"),
 		},
 		{
 			ID:                 "stat-2",
 			UserID:             "user123",
 			StockSymbol:        "TCS",
-			Quantity:           5.0,
+			Quantity:           dec("5.0"),
 			RewardTimestamp:    now.Add(2 * time.Hour),
-			StockPriceAtReward: 3500.0,
+			StockPriceAtReward: dec("3500.0"),
 		},
 	}
 
 	for _, reward := range rewards {
-		err := db.Create(&reward).Error
-		assert.NoError(t, err)
+		createRewardWithLedger(t, db, reward)
 	}
 
 	req, _ := http.NewRequest("GET", "/api/stats/user123", nil)
@@ -133,17 +133,18 @@ func TestGetUserStatsTodayRewardsOnly(t *testing.T) {
 
 	assert.Contains(t, response.TodayRewards, "RELIANCE")
 	assert.Contains(t, response.TodayRewards, "TCS")
-	assert.Equal(t, 10.0, response.TodayRewards["RELIANCE"])
-	assert.Equal(t, 5.0, response.TodayRewards["TCS"])
+	assertDecimal(t, "10", response.TodayRewards["RELIANCE"])
+	assertDecimal(t, "5", response.TodayRewards["TCS"])
 }
 
 func TestGetUserStatsMixedRewards(t *testing.T) {
+	t.Parallel()
+
 	db := setupTestDB(t)
-	initializers.DB = db
-	setupTestLogger()
+	a := newTestApp(t, db)
 
 	router := setupRouter()
-	router.GET("/api/stats/:userId", controllers.GetUserStats)
+	router.GET("/api/stats/:userId", controllers.NewStatsController(a).GetUserStats)
 
 	now := time.Now()
 	yesterday := now.AddDate(0, 0, -1)
@@ -153,23 +154,22 @@ func TestGetUserStatsMixedRewards(t *testing.T) {
 			ID:                 "stat-1",
 			UserID:             "user123",
 			StockSymbol:        "RELIANCE",
-			Quantity:           10.0,
+			Quantity:           dec("10.0"),
 			RewardTimestamp:    now,
-			StockPriceAtReward: 2500.0,
+			StockPriceAtReward: dec("2500.0"),
 		},
 		{
 			ID:                 "stat-2",
 			UserID:             "user123",
 			StockSymbol:        "TCS",
-			Quantity:           5.0,
+			Quantity:           dec("5.0"),
 			RewardTimestamp:    yesterday,
-			StockPriceAtReward: 3500.0,
+			StockPriceAtReward: dec("3500.0"),
 		},
 	}
 
 	for _, reward := range rewards {
-		err := db.Create(&reward).Error
-		assert.NoError(t, err)
+		createRewardWithLedger(t, db, reward)
 	}
 
 	req, _ := http.NewRequest("GET", "/api/stats/user123", nil)
@@ -185,7 +185,7 @@ func TestGetUserStatsMixedRewards(t *testing.T) {
 	assert.Contains(t, response.TodayRewards, "RELIANCE")
 	assert.NotContains(t, response.TodayRewards, "TCS")
 
-	assert.Greater(t, response.CurrentPortfolioINR, 0.0)
+	assert.True(t, response.CurrentPortfolioINR.IsPositive())
 
-	assert.Equal(t, 15.0, response.TotalSharesRewarded)
+	assertDecimal(t, "15", response.TotalSharesRewarded)
 }
@@ -1,73 +1,92 @@
 package tests
 
 import (
+	"assignment/decimal"
+	"assignment/models"
 	"assignment/services"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 )
 
 func TestGetCurrentStockPrice(t *testing.T) {
+	t.Parallel()
+
+	service := services.NewPriceService(nil, services.NewRandomPriceProvider(), services.SystemClock{})
 	symbols := []string{"RELIANCE", "TCS", "INFOSYS", "HDFC", "WIPRO"}
 
 	for _, symbol := range symbols {
-		price, err := services.GetCurrentStockPrice(symbol)
+		price, err := service.GetCurrentStockPrice(symbol)
 		assert.NoError(t, err)
-		assert.Greater(t, price, 0.0)
+		assert.True(t, price.IsPositive())
 	}
 }
 
 func TestGetCurrentStockPriceConsistency(t *testing.T) {
+	t.Parallel()
+
+	service := services.NewPriceService(nil, services.NewRandomPriceProvider(), services.SystemClock{})
 	symbol := "RELIANCE"
 
-	price1, err1 := services.GetCurrentStockPrice(symbol)
+	price1, err1 := service.GetCurrentStockPrice(symbol)
 	assert.NoError(t, err1)
 
-	price2, err2 := services.GetCurrentStockPrice(symbol)
+	price2, err2 := service.GetCurrentStockPrice(symbol)
 	assert.NoError(t, err2)
 
 	assert.Equal(t, price1, price2, "Prices should be consistent within same session")
 }
 
 func TestGetCurrentStockPriceUnknownSymbol(t *testing.T) {
-	price, err := services.GetCurrentStockPrice("UNKNOWN")
+	t.Parallel()
+
+	service := services.NewPriceService(nil, services.NewRandomPriceProvider(), services.SystemClock{})
+	price, err := service.GetCurrentStockPrice("UNKNOWN")
 	assert.NoError(t, err)
-	assert.Greater(t, price, 0.0)
-	assert.LessOrEqual(t, price, 5000.0)
+	assert.True(t, price.IsPositive())
+	assert.True(t, price.LessThanOrEqual(dec("5000")))
 }
 
 func TestGetCurrentStockPriceKnownSymbolRange(t *testing.T) {
+	t.Parallel()
+
+	service := services.NewPriceService(nil, services.NewRandomPriceProvider(), services.SystemClock{})
 	testCases := []struct {
 		symbol string
-		min    float64
-		max    float64
+		min    string
+		max    string
 	}{
-		{"RELIANCE", 2200.0, 2800.0},
-		{"TCS", 3200.0, 4000.0},
-		{"INFOSYS", 1400.0, 1800.0},
-		{"HDFC", 1500.0, 2000.0},
-		{"WIPRO", 400.0, 600.0},
-		{"ITC", 380.0, 480.0},
-		{"BHARTI", 800.0, 1200.0},
-		{"SBIN", 500.0, 700.0},
-		{"HDFCBANK", 1400.0, 1700.0},
-		{"ICICIBANK", 900.0, 1200.0},
+		{"RELIANCE", "2200", "2800"},
+		{"TCS", "3200", "4000"},
+		{"INFOSYS", "1400", "1800"},
+		{"HDFC", "1500", "2000"},
+		{"WIPRO", "400", "600"},
+		{"ITC", "380", "480"},
+		{"BHARTI", "800", "1200"},
+		{"SBIN", "500", "700"},
+		{"HDFCBANK", "1400", "1700"},
+		{"ICICIBANK", "900", "1200"},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.symbol, func(t *testing.T) {
-			price, err := services.GetCurrentStockPrice(tc.symbol)
+			price, err := service.GetCurrentStockPrice(tc.symbol)
 			assert.NoError(t, err)
-			assert.GreaterOrEqual(t, price, tc.min, "Price should be >= min")
-			assert.LessOrEqual(t, price, tc.max, "Price should be <= max")
+			assert.True(t, price.GreaterThanOrEqual(dec(tc.min)), "Price should be >= min")
+			assert.True(t, price.LessThanOrEqual(dec(tc.max)), "Price should be <= max")
 		})
 	}
 }
 
 func TestGetCurrentPricesMultipleSymbols(t *testing.T) {
+	t.Parallel()
+
+	service := services.NewPriceService(nil, services.NewRandomPriceProvider(), services.SystemClock{})
 	symbols := []string{"RELIANCE", "TCS", "INFOSYS"}
 
-	prices, err := services.GetCurrentPrices(symbols)
+	prices, err := service.GetCurrentPrices(symbols)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, prices)
@@ -76,14 +95,17 @@ func TestGetCurrentPricesMultipleSymbols(t *testing.T) {
 	for _, symbol := range symbols {
 		price, exists := prices[symbol]
 		assert.True(t, exists, "Price should exist for %s", symbol)
-		assert.Greater(t, price, 0.0)
+		assert.True(t, price.IsPositive())
 	}
 }
 
 func TestGetCurrentPricesEmptyList(t *testing.T) {
+	t.Parallel()
+
+	service := services.NewPriceService(nil, services.NewRandomPriceProvider(), services.SystemClock{})
 	symbols := []string{}
 
-	prices, err := services.GetCurrentPrices(symbols)
+	prices, err := service.GetCurrentPrices(symbols)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, prices)
@@ -91,20 +113,26 @@ func TestGetCurrentPricesEmptyList(t *testing.T) {
 }
 
 func TestGetCurrentPricesSingleSymbol(t *testing.T) {
+	t.Parallel()
+
+	service := services.NewPriceService(nil, services.NewRandomPriceProvider(), services.SystemClock{})
 	symbols := []string{"RELIANCE"}
 
-	prices, err := services.GetCurrentPrices(symbols)
+	prices, err := service.GetCurrentPrices(symbols)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, prices)
 	assert.Equal(t, 1, len(prices))
-	assert.Greater(t, prices["RELIANCE"], 0.0)
+	assert.True(t, prices["RELIANCE"].IsPositive())
 }
 
 func TestGetCurrentPricesMixedKnownUnknown(t *testing.T) {
+	t.Parallel()
+
+	service := services.NewPriceService(nil, services.NewRandomPriceProvider(), services.SystemClock{})
 	symbols := []string{"RELIANCE", "UNKNOWN1", "TCS", "UNKNOWN2"}
 
-	prices, err := services.GetCurrentPrices(symbols)
+	prices, err := service.GetCurrentPrices(symbols)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, prices)
@@ -113,32 +141,116 @@ func TestGetCurrentPricesMixedKnownUnknown(t *testing.T) {
 	for _, symbol := range symbols {
 		price, exists := prices[symbol]
 		assert.True(t, exists)
-		assert.Greater(t, price, 0.0)
+		assert.True(t, price.IsPositive())
 	}
 }
 
 func TestUpdateStockPrices(t *testing.T) {
-	oldPrices := make(map[string]float64)
+	t.Parallel()
+
+	service := services.NewPriceService(nil, services.NewRandomPriceProvider(), services.SystemClock{})
+	oldPrices := make(map[string]decimal.Decimal)
 	symbols := []string{"RELIANCE", "TCS", "INFOSYS"}
 
 	for _, symbol := range symbols {
-		price, _ := services.GetCurrentStockPrice(symbol)
+		price, _ := service.GetCurrentStockPrice(symbol)
 		oldPrices[symbol] = price
 	}
 
-	err := services.UpdateStockPrices()
+	err := service.UpdateStockPrices()
 	assert.NoError(t, err)
 
 	for _, symbol := range symbols {
-		newPrice, _ := services.GetCurrentStockPrice(symbol)
-		assert.Greater(t, newPrice, 0.0)
+		newPrice, _ := service.GetCurrentStockPrice(symbol)
+		assert.True(t, newPrice.IsPositive())
 	}
 }
 
 func TestStockPricePrecision(t *testing.T) {
-	price, err := services.GetCurrentStockPrice("RELIANCE")
+	t.Parallel()
+
+	service := services.NewPriceService(nil, services.NewRandomPriceProvider(), services.SystemClock{})
+	price, err := service.GetCurrentStockPrice("RELIANCE")
+	assert.NoError(t, err)
+
+	assert.True(t, price.Equal(price.RoundBank(2)), "Price should have at most 2 decimal places")
+}
+
+func TestUpdateStockPricesRecordsHistory(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	err := a.Prices.UpdateStockPrices()
+	assert.NoError(t, err)
+
+	var count int64
+	err = db.Model(&models.StockPrice{}).Count(&count).Error
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), count)
+
+	var recorded models.StockPrice
+	err = db.Where("stock_symbol = ?", "RELIANCE").First(&recorded).Error
+	assert.NoError(t, err)
+
+	currentPrice, err := a.Prices.GetCurrentStockPrice("RELIANCE")
+	assert.NoError(t, err)
+	assertDecimal(t, currentPrice.String(), recorded.Price)
+}
+
+func TestGetCurrentStockPriceRecordsLazyPrice(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	price, err := a.Prices.GetCurrentStockPrice("LAZYSYMBOL")
+	assert.NoError(t, err)
+
+	var recorded []models.StockPrice
+	err = db.Where("stock_symbol = ?", "LAZYSYMBOL").Find(&recorded).Error
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(recorded))
+	assertDecimal(t, price.String(), recorded[0].Price)
+}
+
+func TestGetStockPriceAsOf(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+
+	base := time.Date(2025, 11, 17, 10, 0, 0, 0, time.UTC)
+	prices := []models.StockPrice{
+		{StockSymbol: "TCS", Price: dec("3500.0"), Timestamp: base},
+		{StockSymbol: "TCS", Price: dec("3550.0"), Timestamp: base.Add(1 * time.Hour)},
+		{StockSymbol: "TCS", Price: dec("3600.0"), Timestamp: base.Add(2 * time.Hour)},
+		{StockSymbol: "INFOSYS", Price: dec("1500.0"), Timestamp: base.Add(90 * time.Minute)},
+	}
+	err := db.Create(&prices).Error
+	assert.NoError(t, err)
+
+	price, err := services.GetStockPriceAsOf(db, "TCS", base.Add(90*time.Minute))
+	assert.NoError(t, err)
+	assertDecimal(t, "3550", price.Price)
+
+	price, err = services.GetStockPriceAsOf(db, "TCS", base.Add(2*time.Hour))
+	assert.NoError(t, err)
+	assertDecimal(t, "3600", price.Price)
+
+	_, err = services.GetStockPriceAsOf(db, "TCS", base.Add(-1*time.Minute))
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestStockPriceHistoryUniquePerTimestamp(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+
+	timestamp := time.Date(2025, 11, 17, 10, 0, 0, 0, time.UTC)
+	err := db.Create(&models.StockPrice{StockSymbol: "TCS", Price: dec("3500.0"), Timestamp: timestamp}).Error
 	assert.NoError(t, err)
 
-	priceStr := string(rune(int(price * 100)))
-	assert.LessOrEqual(t, len(priceStr), 4, "Price should have at most 2 decimal places")
+	err = db.Create(&models.StockPrice{StockSymbol: "TCS", Price: dec("3600.0"), Timestamp: timestamp}).Error
+	assert.Error(t, err)
 }
@@ -1,10 +1,14 @@
 package tests
 
 import (
+	"assignment/app"
+	"assignment/decimal"
 	"assignment/initializers"
 	"assignment/models"
+	"assignment/services"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -14,10 +18,22 @@ import (
 )
 
 func setupTestDB(t *testing.T) *gorm.DB {
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	return setupTestDBAt(t, ":memory:")
+}
+
+// setupTestDBAt opens a migrated and seeded database at dsn, for tests whose
+// connections may be discarded and so cannot use a private in-memory one.
+func setupTestDBAt(t *testing.T, dsn string) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{TranslateError: true})
+	assert.NoError(t, err)
+
+	err = db.AutoMigrate(&models.StockReward{}, &models.LedgerEntry{}, &models.StockPrice{}, &models.Instrument{}, &models.FeeSchedule{}, &models.ReconciliationRun{}, &models.RewardIdempotencyKey{}, &models.CorporateAction{}, &models.DividendDeclaration{}, &models.DividendEntitlement{}, &models.Redemption{}, &models.DematTransfer{}, &models.DematTransferLot{}, &models.RewardLimitUsage{}, &models.RewardLimitReservation{}, &models.Campaign{})
 	assert.NoError(t, err)
 
-	err = db.AutoMigrate(&models.StockReward{}, &models.LedgerEntry{})
+	err = services.SeedInstruments(db)
+	assert.NoError(t, err)
+
+	err = services.SeedFeeSchedules(db)
 	assert.NoError(t, err)
 
 	return db
@@ -29,10 +45,59 @@ func setupRouter() *gin.Engine {
 	return router
 }
 
-func setupTestLogger() {
-	if initializers.Log == nil {
-		initializers.Log = logrus.New()
-		initializers.Log.SetOutput(os.Stdout)
-		initializers.Log.SetLevel(logrus.WarnLevel)
-	}
+// newTestApp wires an App around db with random prices and the default
+// config, whatever the environment holds. Tests swap fields as they need.
+func newTestApp(t *testing.T, db *gorm.DB) *app.App {
+	t.Helper()
+	return app.New(db, newTestLogger(), services.NewRandomPriceProvider(), services.SystemClock{}, app.DefaultConfig())
+}
+
+// businessLocation is the timezone newTestApp draws days in.
+func businessLocation() *time.Location {
+	return app.DefaultConfig().Location
+}
+
+func newTestLogger() *logrus.Logger {
+	log := logrus.New()
+	log.SetOutput(os.Stdout)
+	log.SetLevel(logrus.WarnLevel)
+	return log
+}
+
+func usePriceProvider(a *app.App, provider services.PriceProvider) {
+	a.Prices = services.NewPriceService(a.DB, provider, a.Clock)
+}
+
+func useRewardLimits(a *app.App, config initializers.RewardLimitsConfig) {
+	a.Limits = services.NewRewardLimiter(config, a.Clock, a.Location)
+}
+
+// noRewardLimits is for calling reward services directly in tests that have
+// no app.
+func noRewardLimits() *services.RewardLimiter {
+	return services.NewRewardLimiter(initializers.RewardLimitsConfig{}, services.SystemClock{}, businessLocation())
+}
+
+func dec(value string) decimal.Decimal {
+	return decimal.RequireFromString(value)
+}
+
+func assertDecimal(t *testing.T, expected string, actual decimal.Decimal) {
+	t.Helper()
+	assert.True(t, dec(expected).Equal(actual), "expected %s, got %s", expected, actual)
+}
+
+func createRewardWithLedger(t *testing.T, db *gorm.DB, reward models.StockReward) {
+	t.Helper()
+
+	schedule := services.DefaultFeeSchedule()
+	charges := services.CalculateCompanyCharges(reward.Quantity.Mul(reward.StockPriceAtReward), &schedule)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&reward).Error; err != nil {
+			return err
+		}
+		return services.RecordLedgerEntriesGORM(tx, &reward, charges, &schedule)
+	})
+	assert.NoError(t, err)
 }
@@ -2,8 +2,8 @@ package tests
 
 import (
 	"assignment/controllers"
-	"assignment/initializers"
 	"assignment/models"
+	"assignment/services"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -14,12 +14,13 @@ import (
 )
 
 func TestGetTodayStocksSuccess(t *testing.T) {
+	t.Parallel()
+
 	db := setupTestDB(t)
-	initializers.DB = db
-	setupTestLogger()
+	a := newTestApp(t, db)
 
 	router := setupRouter()
-	router.GET("/api/today/:userId/stocks", controllers.GetTodayStocks)
+	router.GET("/api/today/:userId/stocks", controllers.NewTodayStocksController(a).GetTodayStocks)
 
 	now := time.Now()
 	rewards := []models.StockReward{
@@ -27,17 +28,17 @@ func TestGetTodayStocksSuccess(t *testing.T) {
 			ID:                 "today-1",
 			UserID:             "user123",
 			StockSymbol:        "RELIANCE",
-			Quantity:           10.0,
+			Quantity:           dec("10.0"),
 			RewardTimestamp:    now,
-			StockPriceAtReward: 2500.0,
+			StockPriceAtReward: dec("2500.0"),
 		},
 		{
 			ID:                 "today-2",
 			UserID:             "user123",
 			StockSymbol:        "TCS",
-			Quantity:           5.0,
+			Quantity:           dec("5.0"),
 			RewardTimestamp:    now.Add(2 * time.Hour),
-			StockPriceAtReward: 3500.0,
+			StockPriceAtReward: dec("3500.0"),
 		},
 	}
 
@@ -62,12 +63,13 @@ func TestGetTodayStocksSuccess(t *testing.T) {
 }
 
 func TestGetTodayStocksNoData(t *testing.T) {
+	t.Parallel()
+
 	db := setupTestDB(t)
-	initializers.DB = db
-	setupTestLogger()
+	a := newTestApp(t, db)
 
 	router := setupRouter()
-	router.GET("/api/today/:userId/stocks", controllers.GetTodayStocks)
+	router.GET("/api/today/:userId/stocks", controllers.NewTodayStocksController(a).GetTodayStocks)
 
 	req, _ := http.NewRequest("GET", "/api/today/user999/stocks", nil)
 	w := httptest.NewRecorder()
@@ -83,12 +85,13 @@ func TestGetTodayStocksNoData(t *testing.T) {
 }
 
 func TestGetTodayStocksExcludesHistoricalData(t *testing.T) {
+	t.Parallel()
+
 	db := setupTestDB(t)
-	initializers.DB = db
-	setupTestLogger()
+	a := newTestApp(t, db)
 
 	router := setupRouter()
-	router.GET("/api/today/:userId/stocks", controllers.GetTodayStocks)
+	router.GET("/api/today/:userId/stocks", controllers.NewTodayStocksController(a).GetTodayStocks)
 
 	now := time.Now()
 	yesterday := now.AddDate(0, 0, -1)
@@ -98,17 +101,17 @@ func TestGetTodayStocksExcludesHistoricalData(t *testing.T) {
 			ID:                 "today-1",
 			UserID:             "user123",
 			StockSymbol:        "RELIANCE",
-			Quantity:           10.0,
+			Quantity:           dec("10.0"),
 			RewardTimestamp:    now,
-			StockPriceAtReward: 2500.0,
+			StockPriceAtReward: dec("2500.0"),
 		},
 		{
 			ID:                 "hist-1",
 			UserID:             "user123",
 			StockSymbol:        "TCS",
-			Quantity:           5.0,
+			Quantity:           dec("5.0"),
 			RewardTimestamp:    yesterday,
-			StockPriceAtReward: 3500.0,
+			StockPriceAtReward: dec("3500.0"),
 		},
 	}
 
@@ -132,3 +135,99 @@ func TestGetTodayStocksExcludesHistoricalData(t *testing.T) {
 	rewardsArray := response["rewards"].([]interface{})
 	assert.Equal(t, 1, len(rewardsArray))
 }
+
+func TestGetTodayStocksUsesRequestTimezone(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	router := setupRouter()
+	router.GET("/api/today/:userId/stocks", controllers.NewTodayStocksController(a).GetTodayStocks)
+
+	now := time.Now()
+	istToday := services.DayRange(now, businessLocation())
+	utcToday := services.DayRange(now, time.UTC)
+
+	rewards := map[string]time.Time{
+		"ist-midnight":        istToday.Start,
+		"before-ist-midnight": istToday.Start.Add(-time.Second),
+		"utc-midnight":        utcToday.Start,
+		"before-utc-midnight": utcToday.Start.Add(-time.Second),
+	}
+	for id, timestamp := range rewards {
+		err := db.Create(&models.StockReward{
+			ID: id, UserID: "user123", StockSymbol: "TCS", Quantity: dec("1"),
+			RewardTimestamp: timestamp.UTC(), StockPriceAtReward: dec("3500"),
+		}).Error
+		assert.NoError(t, err)
+	}
+
+	todayIDs := func(query string) (string, map[string]bool) {
+		req, _ := http.NewRequest("GET", "/api/today/user123/stocks"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var response struct {
+			Date    string `json:"date"`
+			Rewards []models.StockReward
+		}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+
+		ids := make(map[string]bool)
+		for _, reward := range response.Rewards {
+			ids[reward.ID] = true
+		}
+		return response.Date, ids
+	}
+
+	date, ids := todayIDs("")
+	assert.Equal(t, istToday.Start.Format("2006-01-02"), date)
+	assert.True(t, ids["ist-midnight"])
+	assert.False(t, ids["before-ist-midnight"])
+
+	date, ids = todayIDs("?tz=UTC")
+	assert.Equal(t, utcToday.Start.Format("2006-01-02"), date)
+	assert.True(t, ids["utc-midnight"])
+	assert.False(t, ids["before-utc-midnight"])
+
+	req, _ := http.NewRequest("GET", "/api/today/user123/stocks?tz=Nowhere/Special", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetTodayStocksUsesAppClock(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+	a.Clock = services.FixedClock(time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC))
+
+	router := setupRouter()
+	router.GET("/api/today/:userId/stocks", controllers.NewTodayStocksController(a).GetTodayStocks)
+
+	rewards := []models.StockReward{
+		{ID: "clock-today", RewardTimestamp: time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)},
+		{ID: "clock-yesterday", RewardTimestamp: time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC)},
+	}
+	for _, reward := range rewards {
+		reward.UserID = "user123"
+		reward.StockSymbol = "TCS"
+		reward.Quantity = dec("1")
+		reward.StockPriceAtReward = dec("3500")
+		assert.NoError(t, db.Create(&reward).Error)
+	}
+
+	req, _ := http.NewRequest("GET", "/api/today/user123/stocks?tz=UTC", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "2026-03-10", response["date"])
+	assert.Equal(t, float64(1), response["total_rewards"])
+}
@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"assignment/controllers"
+	"assignment/models"
+	"assignment/services"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newNSECalendar(t *testing.T) (*services.TradingCalendar, *time.Location) {
+	t.Helper()
+
+	ist, err := time.LoadLocation("Asia/Kolkata")
+	assert.NoError(t, err)
+
+	holidays, err := services.LoadHolidays(writePriceFile(t, "holidays.csv",
+		"date,name\n# 2026 exchange holidays\n2026-01-26,Republic Day\n2026-03-03,Holi\n"))
+	assert.NoError(t, err)
+
+	calendar, err := services.NewTradingCalendar(ist, 9*time.Hour+15*time.Minute, 15*time.Hour+30*time.Minute, services.NSETradingDays, holidays)
+	assert.NoError(t, err)
+	return calendar, ist
+}
+
+func TestTradingCalendarStatus(t *testing.T) {
+	t.Parallel()
+
+	calendar, ist := newNSECalendar(t)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, time.January, day, hour, minute, 0, 0, ist)
+	}
+
+	tuesdayOpen := at(27, 9, 15)
+	cases := []struct {
+		name          string
+		at            time.Time
+		status        string
+		holiday       string
+		nextOpen      time.Time
+		previousClose time.Time
+	}{
+		{"before the open", at(22, 8, 0), models.MarketStatusPreOpen, "", at(22, 9, 15), at(20, 15, 30)},
+		{"during the session", at(22, 11, 0), models.MarketStatusOpen, "", at(23, 9, 15), at(21, 15, 30)},
+		{"after the close", at(23, 16, 0), models.MarketStatusClosed, "", tuesdayOpen, at(22, 15, 30)},
+		{"weekend", at(24, 12, 0), models.MarketStatusNonTrading, "", tuesdayOpen, at(22, 15, 30)},
+		{"holiday", at(26, 12, 0), models.MarketStatusHoliday, "Republic Day", tuesdayOpen, at(22, 15, 30)},
+		{"first session after a holiday", at(27, 10, 0), models.MarketStatusOpen, "", at(28, 9, 15), at(23, 15, 30)},
+	}
+
+	for _, tc := range cases {
+		status := calendar.Status(tc.at)
+		assert.Equal(t, tc.status, status.Status, tc.name)
+		assert.Equal(t, tc.holiday, status.Holiday, tc.name)
+		assert.True(t, tc.nextOpen.Equal(status.NextOpen), "%s: next open %v", tc.name, status.NextOpen)
+		assert.True(t, tc.previousClose.Equal(status.PreviousCloseAt), "%s: previous close %v", tc.name, status.PreviousCloseAt)
+		assert.Equal(t, tc.status == models.MarketStatusOpen, status.ClosesAt != nil, tc.name)
+	}
+}
+
+func TestLoadHolidaysRejectsBadDates(t *testing.T) {
+	t.Parallel()
+
+	_, err := services.LoadHolidays(writePriceFile(t, "holidays.csv", "26/01/2026,Republic Day\n"))
+	assert.Error(t, err)
+
+	_, err = services.LoadHolidays("/does/not/exist.csv")
+	assert.Error(t, err)
+}
+
+func TestNextPriceUpdateFollowsSessions(t *testing.T) {
+	t.Parallel()
+
+	calendar, ist := newNSECalendar(t)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, time.January, day, hour, minute, 0, 0, ist)
+	}
+
+	update, ok := services.NextPriceUpdate(calendar, time.Minute, at(22, 7, 0))
+	assert.True(t, ok)
+	assert.True(t, at(22, 9, 15).Equal(update.At))
+	assert.False(t, update.Closing)
+
+	update, _ = services.NextPriceUpdate(calendar, time.Minute, at(22, 12, 0))
+	assert.True(t, at(22, 12, 1).Equal(update.At))
+	assert.False(t, update.Closing)
+
+	update, _ = services.NextPriceUpdate(calendar, time.Minute, at(22, 15, 29).Add(30*time.Second))
+	assert.True(t, at(22, 15, 30).Equal(update.At))
+	assert.True(t, update.Closing)
+
+	update, _ = services.NextPriceUpdate(calendar, time.Minute, at(23, 15, 30))
+	assert.True(t, at(27, 9, 15).Equal(update.At), "Friday close waits for Tuesday after the holiday, got %v", update.At)
+	assert.False(t, update.Closing)
+}
+
+func TestPortfolioPreviousCloseSkipsNonTradingDays(t *testing.T) {
+	t.Parallel()
+
+	db := setupTestDB(t)
+	a := newTestApp(t, db)
+
+	provider, err := services.NewFilePriceProvider(writePriceFile(t, "prices.csv", "RELIANCE,2700\n"))
+	assert.NoError(t, err)
+	usePriceProvider(a, provider)
+
+	location := businessLocation()
+	now := time.Now().In(location)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+	yesterday := today.AddDate(0, 0, -1)
+
+	var weekdays []time.Weekday
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if weekday != yesterday.Weekday() {
+			weekdays = append(weekdays, weekday)
+		}
+	}
+	calendar, err := services.NewTradingCalendar(location, 0, 24*time.Hour, weekdays, nil)
+	assert.NoError(t, err)
+	a.Calendar = calendar
+
+	createRewardWithLedger(t, db, models.StockReward{
+		ID:                 "cal-1",
+		UserID:             "user123",
+		StockSymbol:        "RELIANCE",
+		Quantity:           dec("10"),
+		RewardTimestamp:    today.AddDate(0, 0, -5),
+		StockPriceAtReward: dec("2500"),
+	})
+	prices := []models.StockPrice{
+		{StockSymbol: "RELIANCE", Price: dec("2600"), Timestamp: yesterday.Add(-time.Hour).UTC()},
+		{StockSymbol: "RELIANCE", Price: dec("2650"), Timestamp: yesterday.Add(12 * time.Hour).UTC()},
+	}
+	assert.NoError(t, db.Create(&prices).Error)
+
+	router := setupRouter()
+	router.GET("/portfolio/:userId", controllers.NewPortfolioController(a).GetUserPortfolio)
+	req, _ := http.NewRequest("GET", "/portfolio/user123", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response models.PortfolioResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, models.MarketStatusOpen, response.Market.Status)
+	assert.True(t, yesterday.Equal(response.Market.PreviousCloseAt))
+	assertDecimal(t, "2600", response.Holdings[0].PreviousClose)
+	assertDecimal(t, "1000", response.Holdings[0].DayChange)
+}